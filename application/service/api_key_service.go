@@ -0,0 +1,103 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/Korjick/go-http-quote/domain/auth"
+)
+
+const (
+	apiKeyPrefix      = "qk_"
+	apiKeySecretBytes = 32
+	apiKeyVisiblePart = len(apiKeyPrefix) + 8
+	minAPIKeyLength   = 16
+)
+
+// APIKeyService issues and checks API keys. Keys are random, so a plain
+// SHA-256 hash is enough to store them safely; the secret itself is only
+// returned once, when the key is created.
+type APIKeyService struct {
+	repo auth.APIKeyRepository
+}
+
+func NewAPIKeyService(repo auth.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{repo: repo}
+}
+
+// CreateAPIKey issues a key with role and returns it with its secret.
+func (s *APIKeyService) CreateAPIKey(principal auth.Principal, name string, role auth.Role) (*auth.APIKey, string, error) {
+	if err := principal.Authorize(auth.PermissionManageAPIKeys); err != nil {
+		return nil, "", err
+	}
+
+	secret := apiKeyPrefix + randomSecret()
+	key, err := s.register(principal, name, role, secret)
+	if err != nil {
+		return nil, "", err
+	}
+	return key, secret, nil
+}
+
+// RegisterAPIKey stores a key with a secret chosen by the caller, such as an
+// administrator key provided through the environment at startup.
+func (s *APIKeyService) RegisterAPIKey(principal auth.Principal, name string, role auth.Role, secret string) (*auth.APIKey, error) {
+	if err := principal.Authorize(auth.PermissionManageAPIKeys); err != nil {
+		return nil, err
+	}
+	return s.register(principal, name, role, secret)
+}
+
+func (s *APIKeyService) register(principal auth.Principal, name string, role auth.Role, secret string) (*auth.APIKey, error) {
+	if len(secret) < minAPIKeyLength {
+		return nil, fmt.Errorf("%w: it must be at least %d characters", auth.ErrAPIKeyTooShort, minAPIKeyLength)
+	}
+	return s.repo.Create(auth.APIKeyDraft{
+		Name:      name,
+		Role:      role,
+		Prefix:    secret[:min(apiKeyVisiblePart, len(secret))],
+		Hash:      hashSecret(secret),
+		CreatedBy: principal.Subject,
+	})
+}
+
+func (s *APIKeyService) ListAPIKeys(principal auth.Principal) ([]*auth.APIKey, error) {
+	if err := principal.Authorize(auth.PermissionManageAPIKeys); err != nil {
+		return nil, err
+	}
+	return s.repo.GetAll()
+}
+
+func (s *APIKeyService) RevokeAPIKey(principal auth.Principal, id auth.APIKeyID) error {
+	if err := principal.Authorize(auth.PermissionManageAPIKeys); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+// Authenticate returns the principal of the key with the given secret.
+func (s *APIKeyService) Authenticate(secret string) (auth.Principal, error) {
+	key, err := s.repo.GetByHash(hashSecret(secret))
+	if errors.Is(err, auth.ErrAPIKeyNotFound) {
+		return auth.Anonymous, auth.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return auth.Anonymous, err
+	}
+	return key.Principal(), nil
+}
+
+func randomSecret() string {
+	secret := make([]byte, apiKeySecretBytes)
+	_, _ = rand.Read(secret)
+	return base64.RawURLEncoding.EncodeToString(secret)
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/Korjick/go-http-quote/domain/apperror"
+	"github.com/Korjick/go-http-quote/domain/auth"
+	"github.com/Korjick/go-http-quote/domain/quote/entity"
+	"github.com/Korjick/go-http-quote/domain/quote/repository"
+)
+
+const MaxBatchSize = 100
+
+var (
+	ErrEmptyBatch    = apperror.New(apperror.KindInvalid, "empty_batch", "batch must contain at least one operation")
+	ErrBatchTooLarge = apperror.New(apperror.KindInvalid, "batch_too_large", "batch contains too many operations")
+)
+
+// ExecuteBatch applies ops atomically. The principal needs the permissions
+// of every operation in the batch, so a batch is refused as a whole rather
+// than aborted when one of them is not allowed. Whether a delete is allowed
+// depends on the owner of the quote, which the repository checks when it
+// removes the quote, so that the quote cannot change in between. Quotes that
+// do not exist fail their operation.
+func (s *QuoteService) ExecuteBatch(ctx context.Context, principal auth.Principal, ops []repository.Operation) (results []repository.OperationResult, err error) {
+	ctx, span := s.tracer.Start(ctx, "QuoteService.ExecuteBatch",
+		slog.String("enduser.id", principal.Subject), slog.Int("batch.size", len(ops)))
+	defer func() { span.End(err) }()
+
+	if len(ops) == 0 {
+		return nil, ErrEmptyBatch
+	}
+	if len(ops) > MaxBatchSize {
+		return nil, fmt.Errorf("%w (%d > %d)", ErrBatchTooLarge, len(ops), MaxBatchSize)
+	}
+	ops = slices.Clone(ops)
+	for i, op := range ops {
+		switch op.Kind {
+		case repository.OperationDelete:
+			if err := principal.Authorize(auth.PermissionDeleteOwnQuote); err != nil {
+				return nil, err
+			}
+			ops[i].Precondition = func(quote *entity.Quote) error {
+				return authorizeChange(principal, quote, auth.PermissionDeleteQuote, auth.PermissionDeleteOwnQuote)
+			}
+		default:
+			if err := principal.Authorize(auth.PermissionCreateQuote); err != nil {
+				return nil, err
+			}
+			ops[i].Draft.CreatedBy, ops[i].Draft.Owner = principal.Subject, principal.Subject
+		}
+	}
+	results, err = s.repo.ApplyBatch(ctx, ops)
+	if err != nil {
+		for _, result := range results {
+			if apperror.KindOf(result.Err) == apperror.KindForbidden {
+				return nil, result.Err
+			}
+		}
+		return results, err
+	}
+	slog.InfoContext(ctx, "batch applied", "operations", len(ops), "by", principal.Subject)
+	return results, nil
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/Korjick/go-http-quote/domain/apperror"
+	"github.com/Korjick/go-http-quote/domain/quote/entity"
+	"github.com/Korjick/go-http-quote/domain/quote/repository"
+)
+
+const exportPageSize = 500
+
+var ErrSnapshotChanged = apperror.New(apperror.KindConflict, "snapshot_changed", "quotes were deleted during the export, retry it")
+
+func (s *QuoteService) Snapshot(ctx context.Context) (snapshot repository.Snapshot, err error) {
+	ctx, span := s.tracer.Start(ctx, "QuoteService.Snapshot")
+	defer func() { span.End(err) }()
+
+	return s.repo.Snapshot(ctx)
+}
+
+// ExportQuotes calls visit for every quote that existed when snapshot was
+// taken, in ID order. The repository is read page by page, so writers are
+// never blocked for the duration of the whole export; quotes created
+// afterwards are not visited. If quotes of the snapshot are deleted in the
+// meantime, the export no longer matches the snapshot and fails with
+// ErrSnapshotChanged once it has visited the rest. The export stops between
+// pages once ctx is done.
+func (s *QuoteService) ExportQuotes(ctx context.Context, snapshot repository.Snapshot, visit func(*entity.Quote) error) (err error) {
+	ctx, span := s.tracer.Start(ctx, "QuoteService.ExportQuotes", slog.Uint64("snapshot.revision", snapshot.Revision))
+	defer func() { span.End(err) }()
+
+	var after entity.QuoteID
+	visited := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		page, err := s.repo.GetPage(ctx, after, exportPageSize)
+		if err != nil {
+			return err
+		}
+
+		for _, quote := range page {
+			if quote.ID > snapshot.MaxID {
+				return checkVisited(snapshot, visited)
+			}
+			if err := visit(quote); err != nil {
+				return err
+			}
+			visited++
+		}
+
+		if len(page) < exportPageSize {
+			return checkVisited(snapshot, visited)
+		}
+		after = page[len(page)-1].ID
+	}
+}
+
+// checkVisited tells whether the export visited every quote of snapshot.
+// Quotes only ever get higher IDs, so a quote is missing exactly when one of
+// the snapshot was deleted.
+func checkVisited(snapshot repository.Snapshot, visited int) error {
+	if visited < snapshot.Count {
+		return fmt.Errorf("%w: exported %d of %d quotes", ErrSnapshotChanged, visited, snapshot.Count)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/Korjick/go-http-quote/domain/apperror"
+	"github.com/Korjick/go-http-quote/domain/auth"
+	"github.com/Korjick/go-http-quote/domain/quote/entity"
+)
+
+type ImportMode string
+
+const (
+	ImportModeAtomic     ImportMode = "atomic"
+	ImportModeBestEffort ImportMode = "best-effort"
+)
+
+var ErrInvalidImportMode = apperror.New(apperror.KindMalformed, "invalid_import_mode", "invalid import mode")
+
+func ParseImportMode(s string) (ImportMode, error) {
+	switch ImportMode(s) {
+	case "", ImportModeAtomic:
+		return ImportModeAtomic, nil
+	case ImportModeBestEffort:
+		return ImportModeBestEffort, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidImportMode, s)
+	}
+}
+
+// QuoteSource yields quote drafts one by one. Next returns io.EOF once the
+// source is exhausted and a *RowError for a record that could not be decoded
+// but does not prevent reading the following ones. Any other error is fatal.
+type QuoteSource interface {
+	Next() (entity.QuoteDraft, error)
+}
+
+type RowError struct {
+	Row int
+	Err error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+type ImportReport struct {
+	Mode     ImportMode
+	Total    int
+	Imported int
+	Errors   []RowError
+}
+
+func (r *ImportReport) Failed() int {
+	return len(r.Errors)
+}
+
+// ImportQuotes reads every record from src and stores the valid ones. In
+// atomic mode nothing is stored unless all records are valid; in best-effort
+// mode each valid record is stored as soon as it is read, so the records
+// before a cancellation of ctx stay stored.
+func (s *QuoteService) ImportQuotes(ctx context.Context, principal auth.Principal, src QuoteSource, mode ImportMode) (report *ImportReport, err error) {
+	ctx, span := s.tracer.Start(ctx, "QuoteService.ImportQuotes",
+		slog.String("enduser.id", principal.Subject), slog.String("import.mode", string(mode)))
+	defer func() { span.End(err) }()
+
+	if err := principal.Authorize(auth.PermissionCreateQuote); err != nil {
+		return nil, err
+	}
+
+	report = &ImportReport{Mode: mode}
+	var pending []entity.QuoteDraft
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		draft, err := src.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		report.Total++
+
+		var rowErr *RowError
+		switch {
+		case errors.As(err, &rowErr):
+			report.Errors = append(report.Errors, *rowErr)
+			continue
+		case err != nil:
+			report.Errors = append(report.Errors, RowError{Row: report.Total, Err: err})
+			return s.finishImport(ctx, report, nil)
+		}
+
+		if err := draft.Validate(); err != nil {
+			report.Errors = append(report.Errors, RowError{Row: report.Total, Err: err})
+			continue
+		}
+		draft.CreatedBy, draft.Owner = principal.Subject, principal.Subject
+
+		if mode == ImportModeBestEffort {
+			if _, err := s.repo.Create(ctx, draft); err != nil {
+				return nil, err
+			}
+			report.Imported++
+			continue
+		}
+		pending = append(pending, draft)
+	}
+
+	return s.finishImport(ctx, report, pending)
+}
+
+func (s *QuoteService) finishImport(ctx context.Context, report *ImportReport, pending []entity.QuoteDraft) (*ImportReport, error) {
+	if report.Mode == ImportModeAtomic && report.Failed() == 0 && len(pending) > 0 {
+		created, err := s.repo.CreateMany(ctx, pending)
+		if err != nil {
+			return nil, err
+		}
+		report.Imported = len(created)
+	}
+	slog.InfoContext(ctx, "quotes imported", "mode", report.Mode, "total", report.Total, "imported", report.Imported, "failed", report.Failed())
+	return report, nil
+}
@@ -1,36 +1,178 @@
 package service
 
 import (
+	"cmp"
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/Korjick/go-http-quote/domain/auth"
 	"github.com/Korjick/go-http-quote/domain/quote/entity"
 	"github.com/Korjick/go-http-quote/domain/quote/repository"
 )
 
+// cancelCheckInterval is how many quotes the loops of the service go
+// through between checks of the context.
+const cancelCheckInterval = 256
+
 type QuoteService struct {
-	repo repository.QuoteRepository
+	repo   repository.QuoteRepository
+	tracer Tracer
 }
 
-func NewQuoteService(repo repository.QuoteRepository) *QuoteService {
-	return &QuoteService{
-		repo: repo,
+func NewQuoteService(repo repository.QuoteRepository, options ...QuoteServiceOption) *QuoteService {
+	s := &QuoteService{
+		repo:   repo,
+		tracer: NoopTracer,
+	}
+	for _, option := range options {
+		option(s)
 	}
+	return s
 }
 
-func (s *QuoteService) CreateQuote(author, text string) (*entity.Quote, error) {
-	return s.repo.Create(author, text)
+func (s *QuoteService) CreateQuote(ctx context.Context, principal auth.Principal, author, text string) (quote *entity.Quote, err error) {
+	ctx, span := s.tracer.Start(ctx, "QuoteService.CreateQuote", slog.String("enduser.id", principal.Subject))
+	defer func() { span.End(err) }()
+
+	if err := principal.Authorize(auth.PermissionCreateQuote); err != nil {
+		return nil, err
+	}
+	quote, err = s.repo.Create(ctx, entity.QuoteDraft{Author: author, Text: text, CreatedBy: principal.Subject, Owner: principal.Subject})
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(slog.Int64("quote.id", int64(quote.ID)))
+	slog.InfoContext(ctx, "quote created", "id", quote.ID, "by", principal.Subject)
+	return quote, nil
+}
+
+func (s *QuoteService) GetAllQuotes(ctx context.Context) (quotes []*entity.Quote, err error) {
+	ctx, span := s.tracer.Start(ctx, "QuoteService.GetAllQuotes")
+	defer func() { span.End(err) }()
+
+	return s.repo.GetAll(ctx)
 }
 
-func (s *QuoteService) GetAllQuotes() ([]*entity.Quote, error) {
-	return s.repo.GetAll()
+func (s *QuoteService) GetQuote(ctx context.Context, id entity.QuoteID) (quote *entity.Quote, err error) {
+	ctx, span := s.tracer.Start(ctx, "QuoteService.GetQuote", slog.Int64("quote.id", int64(id)))
+	defer func() { span.End(err) }()
+
+	return s.repo.GetByID(ctx, id)
 }
 
-func (s *QuoteService) GetQuotesByAuthor(author string) ([]*entity.Quote, error) {
-	return s.repo.GetByAuthor(author)
+// SearchQuotes returns the quotes of author whose text or author contains
+// query, ignoring case. Empty arguments match every quote.
+func (s *QuoteService) SearchQuotes(ctx context.Context, author, query string) (result []*entity.Quote, err error) {
+	ctx, span := s.tracer.Start(ctx, "QuoteService.SearchQuotes", slog.String("quote.author", author))
+	defer func() { span.End(err) }()
+
+	var quotes []*entity.Quote
+	if author != "" {
+		quotes, err = s.repo.GetByAuthor(ctx, author)
+	} else {
+		quotes, err = s.repo.GetAll(ctx)
+	}
+	if err != nil || query == "" {
+		return quotes, err
+	}
+
+	query = strings.ToLower(query)
+	for i, quote := range quotes {
+		if i%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		if strings.Contains(strings.ToLower(quote.Text), query) || strings.Contains(strings.ToLower(quote.Author), query) {
+			result = append(result, quote)
+		}
+	}
+	return result, nil
 }
 
-func (s *QuoteService) GetRandomQuote() (*entity.Quote, error) {
-	return s.repo.GetRandom()
+// RecentQuotes returns up to limit quotes of author, or of everyone if author
+// is empty, newest first.
+func (s *QuoteService) RecentQuotes(ctx context.Context, author string, limit int) (quotes []*entity.Quote, err error) {
+	ctx, span := s.tracer.Start(ctx, "QuoteService.RecentQuotes", slog.String("quote.author", author))
+	defer func() { span.End(err) }()
+
+	quotes, err = s.SearchQuotes(ctx, author, "")
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(quotes, func(a, b *entity.Quote) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.ID, a.ID)
+	})
+	return quotes[:min(limit, len(quotes))], nil
 }
 
-func (s *QuoteService) DeleteQuote(id entity.QuoteID) error {
-	return s.repo.Delete(id)
+func (s *QuoteService) GetQuotesByAuthor(ctx context.Context, author string) (quotes []*entity.Quote, err error) {
+	ctx, span := s.tracer.Start(ctx, "QuoteService.GetQuotesByAuthor", slog.String("quote.author", author))
+	defer func() { span.End(err) }()
+
+	return s.repo.GetByAuthor(ctx, author)
+}
+
+func (s *QuoteService) GetRandomQuote(ctx context.Context) (quote *entity.Quote, err error) {
+	ctx, span := s.tracer.Start(ctx, "QuoteService.GetRandomQuote")
+	defer func() { span.End(err) }()
+
+	return s.repo.GetRandom(ctx)
+}
+
+// OwnQuotes returns the quotes owned by principal.
+func (s *QuoteService) OwnQuotes(ctx context.Context, principal auth.Principal) (quotes []*entity.Quote, err error) {
+	ctx, span := s.tracer.Start(ctx, "QuoteService.OwnQuotes", slog.String("enduser.id", principal.Subject))
+	defer func() { span.End(err) }()
+
+	if !principal.Authenticated() {
+		return nil, auth.ErrUnauthenticated
+	}
+	return s.repo.GetByOwner(ctx, principal.Subject)
+}
+
+// DeleteQuote deletes a quote owned by principal, or any quote if principal
+// is a moderator.
+func (s *QuoteService) DeleteQuote(ctx context.Context, principal auth.Principal, id entity.QuoteID) (err error) {
+	ctx, span := s.tracer.Start(ctx, "QuoteService.DeleteQuote",
+		slog.String("enduser.id", principal.Subject), slog.Int64("quote.id", int64(id)))
+	defer func() { span.End(err) }()
+
+	if err := principal.Authorize(auth.PermissionDeleteOwnQuote); err != nil {
+		return err
+	}
+	// The ownership check runs as a precondition, under the lock of the
+	// repository, so the quote cannot change between the check and the
+	// delete.
+	results, err := s.repo.ApplyBatch(ctx, []repository.Operation{{
+		Kind: repository.OperationDelete,
+		ID:   id,
+		Precondition: func(quote *entity.Quote) error {
+			return authorizeChange(principal, quote, auth.PermissionDeleteQuote, auth.PermissionDeleteOwnQuote)
+		},
+	}})
+	if errors.Is(err, entity.ErrBatchAborted) && len(results) == 1 {
+		return results[0].Err
+	}
+	if err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "quote deleted", "id", id, "by", principal.Subject)
+	return nil
+}
+
+// authorizeChange checks that principal may change quote: with the own
+// permission if it owns the quote, with the other one otherwise.
+func authorizeChange(principal auth.Principal, quote *entity.Quote, permission, ownPermission auth.Permission) error {
+	if quote.OwnedBy(principal.Subject) {
+		return principal.Authorize(ownPermission)
+	}
+	return principal.Authorize(permission)
 }
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Tracer starts spans: timed, named operations that form a trace with the
+// spans of the context they are started in.
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, Span)
+}
+
+// Span is an operation in progress. End records its outcome, a failure if
+// err is not nil.
+type Span interface {
+	SetName(name string)
+	SetAttributes(attrs ...slog.Attr)
+	End(err error)
+}
+
+// NoopTracer starts spans that record nothing.
+var NoopTracer Tracer = noopTracer{}
+
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, _ string, _ ...slog.Attr) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetName(string)             {}
+func (noopSpan) SetAttributes(...slog.Attr) {}
+func (noopSpan) End(error)                  {}
+
+type QuoteServiceOption func(*QuoteService)
+
+// WithTracer traces every operation of the service with tracer.
+func WithTracer(tracer Tracer) QuoteServiceOption {
+	return func(s *QuoteService) {
+		s.tracer = tracer
+	}
+}
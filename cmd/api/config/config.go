@@ -0,0 +1,352 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Korjick/go-http-quote/domain/auth"
+)
+
+// FileEnv names the variable holding the path of the config file, which the
+// --config flag overrides.
+const FileEnv = "QUOTES_CONFIG"
+
+// Backends lists the supported quote repositories.
+var Backends = []string{"memory"}
+
+// Config is the configuration of the API server.
+type Config struct {
+	Server  Server
+	Quotes  Quotes
+	Auth    Auth
+	Log     Log
+	Tracing Tracing
+}
+
+type Server struct {
+	Host              string
+	Port              int
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	ShutdownTimeout   time.Duration
+	// TrustedProxies are the networks of reverse proxies whose
+	// X-Forwarded-For the rate limiter believes.
+	TrustedProxies []netip.Prefix
+	// ReadRateLimit and WriteRateLimit are the reads and writes a client
+	// may make per RateLimitWindow in every group of routes, and
+	// AuthFailureLimit the rejected credentials an address may send.
+	ReadRateLimit    int
+	WriteRateLimit   int
+	AuthFailureLimit int
+	RateLimitWindow  time.Duration
+	// PublicURL is the URL clients reach the service at, behind any
+	// proxies, for links in responses.
+	PublicURL string
+}
+
+// Addr is the address the server listens on.
+func (s Server) Addr() string {
+	return net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+}
+
+type Quotes struct {
+	Prefix         string
+	Backend        string
+	RequestTimeout time.Duration
+	BulkTimeout    time.Duration
+}
+
+// Auth configures the bootstrap admin key and bearer tokens. Bearer tokens
+// are accepted when a JWK Set is configured, from a file or a URL.
+type Auth struct {
+	AdminAPIKey  string
+	JWKSFile     string
+	JWKSURL      string
+	JWTIssuer    string
+	JWTAudience  string
+	JWTRoleClaim string
+	// JWTRoles maps values of the role claim to roles. Without it the
+	// values are role names.
+	JWTRoles map[string]auth.Role
+}
+
+type Log struct {
+	Level slog.Level
+}
+
+type Tracing struct {
+	// Exporter is empty or "none" when tracing is off, "stdout" or "file"
+	// to write spans as JSON lines and "otlp" to post them to a collector.
+	Exporter     string
+	File         string
+	OTLPEndpoint string
+}
+
+// Default returns the configuration used where nothing else is set.
+func Default() Config {
+	return Config{
+		Server: Server{
+			Port:              8080,
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       30 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			MaxHeaderBytes:    64 << 10,
+			ShutdownTimeout:   30 * time.Second,
+			ReadRateLimit:     120,
+			WriteRateLimit:    30,
+			AuthFailureLimit:  10,
+			RateLimitWindow:   time.Minute,
+			PublicURL:         "http://localhost:8080",
+		},
+		Quotes: Quotes{
+			Prefix:         "/quotes",
+			Backend:        "memory",
+			RequestTimeout: 10 * time.Second,
+			BulkTimeout:    5 * time.Minute,
+		},
+		Auth:    Auth{JWTRoleClaim: "roles"},
+		Log:     Log{Level: slog.LevelInfo},
+		Tracing: Tracing{OTLPEndpoint: "http://localhost:4318/v1/traces"},
+	}
+}
+
+// setting is one entry of the configuration. Its key names it in files,
+// as "section.name", and gives its flag, --section-name.
+type setting struct {
+	key    string
+	env    string
+	usage  string
+	secret bool
+	value  flag.Value
+}
+
+func (s setting) flag() string {
+	return strings.NewReplacer(".", "-", "_", "-").Replace(s.key)
+}
+
+func (c *Config) settings() []setting {
+	return []setting{
+		{key: "server.host", env: "QUOTES_HOST", usage: "host or address to listen on, all interfaces if empty", value: (*stringValue)(&c.Server.Host)},
+		{key: "server.port", env: "QUOTES_PORT", usage: "port to listen on", value: (*intValue)(&c.Server.Port)},
+		{key: "server.read_header_timeout", env: "QUOTES_READ_HEADER_TIMEOUT", usage: "time to read the request headers", value: (*durationValue)(&c.Server.ReadHeaderTimeout)},
+		{key: "server.read_timeout", env: "QUOTES_READ_TIMEOUT", usage: "time to read the whole request", value: (*durationValue)(&c.Server.ReadTimeout)},
+		{key: "server.write_timeout", env: "QUOTES_WRITE_TIMEOUT", usage: "time to write the response", value: (*durationValue)(&c.Server.WriteTimeout)},
+		{key: "server.idle_timeout", env: "QUOTES_IDLE_TIMEOUT", usage: "time an idle keep-alive connection stays open", value: (*durationValue)(&c.Server.IdleTimeout)},
+		{key: "server.max_header_bytes", env: "QUOTES_MAX_HEADER_BYTES", usage: "maximum size of the request headers", value: (*intValue)(&c.Server.MaxHeaderBytes)},
+		{key: "server.shutdown_timeout", env: "QUOTES_SHUTDOWN_TIMEOUT", usage: "time requests in flight may drain on shutdown", value: (*durationValue)(&c.Server.ShutdownTimeout)},
+		{key: "server.trusted_proxies", env: "QUOTES_TRUSTED_PROXIES", usage: "networks of reverse proxies, such as 10.0.0.0/8,127.0.0.1", value: (*prefixesValue)(&c.Server.TrustedProxies)},
+		{key: "server.read_rate_limit", env: "QUOTES_READ_RATE_LIMIT", usage: "reads a client may make per rate limit window", value: (*intValue)(&c.Server.ReadRateLimit)},
+		{key: "server.write_rate_limit", env: "QUOTES_WRITE_RATE_LIMIT", usage: "writes a client may make per rate limit window", value: (*intValue)(&c.Server.WriteRateLimit)},
+		{key: "server.auth_failure_limit", env: "QUOTES_AUTH_FAILURE_LIMIT", usage: "rejected credentials an address may send per rate limit window", value: (*intValue)(&c.Server.AuthFailureLimit)},
+		{key: "server.rate_limit_window", env: "QUOTES_RATE_LIMIT_WINDOW", usage: "time over which the rate limits refill", value: (*durationValue)(&c.Server.RateLimitWindow)},
+		{key: "server.public_url", env: "QUOTES_PUBLIC_URL", usage: "URL clients reach the service at, for links in feeds", value: (*stringValue)(&c.Server.PublicURL)},
+
+		{key: "quotes.prefix", env: "QUOTES_PREFIX", usage: "path prefix of the quote API", value: (*stringValue)(&c.Quotes.Prefix)},
+		{key: "quotes.backend", env: "QUOTES_BACKEND", usage: "quote repository: " + strings.Join(Backends, ", "), value: (*stringValue)(&c.Quotes.Backend)},
+		{key: "quotes.request_timeout", env: "QUOTES_REQUEST_TIMEOUT", usage: "time limit of a quote request, 0 for none", value: (*durationValue)(&c.Quotes.RequestTimeout)},
+		{key: "quotes.bulk_timeout", env: "QUOTES_BULK_TIMEOUT", usage: "time limit of an import or export, 0 for none", value: (*durationValue)(&c.Quotes.BulkTimeout)},
+
+		{key: "auth.admin_api_key", env: "QUOTES_ADMIN_API_KEY", usage: "secret of the bootstrap admin key, generated and printed to stderr if empty", secret: true, value: (*stringValue)(&c.Auth.AdminAPIKey)},
+		{key: "auth.jwks_file", env: "QUOTES_JWKS_FILE", usage: "file with the JWK Set of bearer tokens", value: (*stringValue)(&c.Auth.JWKSFile)},
+		{key: "auth.jwks_url", env: "QUOTES_JWKS_URL", usage: "URL of the JWK Set of bearer tokens", value: (*stringValue)(&c.Auth.JWKSURL)},
+		{key: "auth.jwt_issuer", env: "QUOTES_JWT_ISSUER", usage: "required issuer of bearer tokens", value: (*stringValue)(&c.Auth.JWTIssuer)},
+		{key: "auth.jwt_audience", env: "QUOTES_JWT_AUDIENCE", usage: "required audience of bearer tokens", value: (*stringValue)(&c.Auth.JWTAudience)},
+		{key: "auth.jwt_role_claim", env: "QUOTES_JWT_ROLE_CLAIM", usage: "claim of bearer tokens holding the roles", value: (*stringValue)(&c.Auth.JWTRoleClaim)},
+		{key: "auth.jwt_roles", env: "QUOTES_JWT_ROLES", usage: "mapping of role claim values to roles, such as editors=contributor,ops=admin", value: (*rolesValue)(&c.Auth.JWTRoles)},
+
+		{key: "log.level", env: "QUOTES_LOG_LEVEL", usage: "minimum log level: debug, info, warn or error", value: (*levelValue)(&c.Log.Level)},
+
+		{key: "tracing.exporter", env: "QUOTES_TRACES_EXPORTER", usage: "span exporter: stdout, file, otlp or none", value: (*stringValue)(&c.Tracing.Exporter)},
+		{key: "tracing.file", env: "QUOTES_TRACES_FILE", usage: "file the file exporter appends spans to", value: (*stringValue)(&c.Tracing.File)},
+		{key: "tracing.otlp_endpoint", env: "QUOTES_OTLP_ENDPOINT", usage: "OTLP/HTTP endpoint of the otlp exporter", value: (*stringValue)(&c.Tracing.OTLPEndpoint)},
+	}
+}
+
+// Loaded is a configuration together with where each of its settings came
+// from.
+type Loaded struct {
+	Config Config
+	// PrintConfig asks to print the configuration instead of serving.
+	PrintConfig bool
+	sources     map[string]string
+}
+
+// Load builds the configuration from the defaults, then the config file,
+// then the environment and then the flags in args, each overriding the
+// ones before. Empty variables count as unset. The errors name the setting
+// and the source of the offending value.
+func Load(args []string, lookupEnv func(string) (string, bool)) (*Loaded, error) {
+	loaded := &Loaded{Config: Default(), sources: make(map[string]string)}
+	settings := loaded.Config.settings()
+
+	type flagValue struct {
+		setting setting
+		value   string
+	}
+	var flags []flagValue
+	fs := flag.NewFlagSet("quotes", flag.ContinueOnError)
+	path := fs.String("config", "", "config file, .toml, .yaml or .yml (env "+FileEnv+")")
+	fs.BoolVar(&loaded.PrintConfig, "print-config", false, "print the effective configuration with secrets redacted and exit")
+	for _, s := range settings {
+		fs.Func(s.flag(), s.usage+" (env "+s.env+")", func(value string) error {
+			flags = append(flags, flagValue{setting: s, value: value})
+			return nil
+		})
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments %q", fs.Args())
+	}
+
+	var errs []error
+	set := func(s setting, value, source string) {
+		if err := s.value.Set(value); err != nil {
+			errs = append(errs, fmt.Errorf("%s (%s): %w", s.key, source, err))
+			return
+		}
+		loaded.sources[s.key] = source
+	}
+
+	if *path == "" {
+		*path, _ = lookupEnv(FileEnv)
+	}
+	if *path != "" {
+		entries, err := readFile(*path)
+		if err != nil {
+			return nil, err
+		}
+		byKey := make(map[string]setting, len(settings))
+		for _, s := range settings {
+			byKey[s.key] = s
+		}
+		for _, entry := range entries {
+			s, ok := byKey[entry.key]
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s:%d: unknown setting %q", *path, entry.line, entry.key))
+				continue
+			}
+			set(s, entry.value, fmt.Sprintf("%s:%d", *path, entry.line))
+		}
+	}
+	for _, s := range settings {
+		if value, ok := lookupEnv(s.env); ok && value != "" {
+			set(s, value, "env "+s.env)
+		}
+	}
+	for _, f := range flags {
+		set(f.setting, f.value, "flag --"+f.setting.flag())
+	}
+	if len(errs) == 0 {
+		errs = loaded.validate()
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return loaded, nil
+}
+
+// validate checks the settings that parse but make no sense.
+func (l *Loaded) validate() []error {
+	c := l.Config
+	var errs []error
+	check := func(ok bool, key, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s (%s): %s", key, l.source(key), fmt.Sprintf(format, args...)))
+		}
+	}
+
+	check(c.Server.Port > 0 && c.Server.Port <= 65535, "server.port", "%d is not a port number", c.Server.Port)
+	check(c.Server.ReadHeaderTimeout >= 0, "server.read_header_timeout", "must not be negative")
+	check(c.Server.ReadTimeout >= 0, "server.read_timeout", "must not be negative")
+	check(c.Server.WriteTimeout >= 0, "server.write_timeout", "must not be negative")
+	check(c.Server.IdleTimeout >= 0, "server.idle_timeout", "must not be negative")
+	check(c.Server.MaxHeaderBytes > 0, "server.max_header_bytes", "must be positive")
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout", "must be positive")
+	check(c.Server.ReadRateLimit > 0, "server.read_rate_limit", "must be positive")
+	check(c.Server.WriteRateLimit > 0, "server.write_rate_limit", "must be positive")
+	check(c.Server.AuthFailureLimit > 0, "server.auth_failure_limit", "must be positive")
+	check(c.Server.RateLimitWindow > 0, "server.rate_limit_window", "must be positive")
+	publicURL, err := url.Parse(c.Server.PublicURL)
+	check(err == nil && (publicURL.Scheme == "http" || publicURL.Scheme == "https") && publicURL.Host != "" &&
+		publicURL.RawQuery == "" && publicURL.Fragment == "" && !strings.HasSuffix(publicURL.Path, "/"),
+		"server.public_url", "%q must be an http or https URL without a query, fragment or trailing /", c.Server.PublicURL)
+
+	prefix := c.Quotes.Prefix
+	check(strings.HasPrefix(prefix, "/") && !strings.HasSuffix(prefix, "/") && !strings.ContainsAny(prefix, " {}?#"),
+		"quotes.prefix", "%q must start with / and have no trailing /, spaces, braces, ? or #", prefix)
+	check(slices.Contains(Backends, c.Quotes.Backend), "quotes.backend", "unknown backend %q, want one of %s", c.Quotes.Backend, strings.Join(Backends, ", "))
+	check(c.Quotes.RequestTimeout >= 0, "quotes.request_timeout", "must not be negative")
+	check(c.Quotes.BulkTimeout >= 0, "quotes.bulk_timeout", "must not be negative")
+
+	check(c.Auth.JWKSFile == "" || c.Auth.JWKSURL == "", "auth.jwks_url", "set only one of auth.jwks_file and auth.jwks_url")
+	check(c.Auth.JWTRoleClaim != "", "auth.jwt_role_claim", "must not be empty")
+
+	switch c.Tracing.Exporter {
+	case "", "none", "stdout", "otlp":
+	case "file":
+		check(c.Tracing.File != "", "tracing.file", "must be set for the file exporter")
+	default:
+		check(false, "tracing.exporter", "unknown exporter %q, want stdout, file, otlp or none", c.Tracing.Exporter)
+	}
+	return errs
+}
+
+func (l *Loaded) source(key string) string {
+	if source, ok := l.sources[key]; ok {
+		return source
+	}
+	return "default"
+}
+
+// Print writes the configuration as a TOML file, which Load accepts back.
+// Each setting is followed by its source. Secrets that are set are printed
+// commented out and redacted, so the file leaves them to the environment.
+func (l *Loaded) Print(w io.Writer) error {
+	config := l.Config
+	var b strings.Builder
+	section := ""
+	for _, s := range config.settings() {
+		name, key, _ := strings.Cut(s.key, ".")
+		if name != section {
+			if section != "" {
+				b.WriteByte('\n')
+			}
+			fmt.Fprintf(&b, "[%s]\n", name)
+			section = name
+		}
+
+		value := s.value.String()
+		switch {
+		case s.secret && value != "":
+			fmt.Fprintf(&b, "# %s = %q # %s\n", key, "[redacted]", l.source(s.key))
+			continue
+		case isInt(s.value):
+		default:
+			value = strconv.Quote(value)
+		}
+		fmt.Fprintf(&b, "%s = %s # %s\n", key, value, l.source(s.key))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func isInt(value flag.Value) bool {
+	_, ok := value.(*intValue)
+	return ok
+}
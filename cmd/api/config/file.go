@@ -0,0 +1,199 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// entry is a setting read from a config file, with its value as text.
+type entry struct {
+	key   string
+	value string
+	line  int
+}
+
+// readFile reads the settings of a TOML or YAML config file. Both formats
+// are supported as far as the configuration needs them: sections of
+// settings whose values are strings, numbers or booleans.
+func readFile(path string) ([]entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+	var parse func([]byte) ([]entry, error)
+	switch ext := filepath.Ext(path); ext {
+	case ".toml":
+		parse = parseTOML
+	case ".yaml", ".yml":
+		parse = parseYAML
+	default:
+		return nil, fmt.Errorf("config file %s: unsupported format %q, want .toml, .yaml or .yml", path, ext)
+	}
+	entries, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("config file %s:%w", path, err)
+	}
+	return entries, nil
+}
+
+// lines calls fn with every line of data and its number, and prefixes the
+// error of fn with the number.
+func lines(data []byte, fn func(line string, n int) error) error {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		if err := fn(scanner.Text(), n); err != nil {
+			return fmt.Errorf("%d: %w", n, err)
+		}
+	}
+	return scanner.Err()
+}
+
+func parseTOML(data []byte) ([]entry, error) {
+	var entries []entry
+	section := ""
+	err := lines(data, func(line string, n int) error {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			return nil
+		case strings.HasPrefix(line, "["):
+			name, rest, ok := strings.Cut(line[1:], "]")
+			if rest = strings.TrimSpace(rest); !ok || !isKey(name) || (rest != "" && !strings.HasPrefix(rest, "#")) {
+				return fmt.Errorf("invalid section header %q", line)
+			}
+			section = name
+			return nil
+		}
+
+		key, raw, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !isKey(key) {
+			return fmt.Errorf("want key = value, got %q", line)
+		}
+		value, err := scalar(strings.TrimSpace(raw), false)
+		if err != nil {
+			return err
+		}
+		if section == "" {
+			return fmt.Errorf("setting %q outside a section", key)
+		}
+		entries = append(entries, entry{key: section + "." + key, value: value, line: n})
+		return nil
+	})
+	return entries, err
+}
+
+// parseYAML reads a mapping of sections to mappings of settings, indented
+// with spaces.
+func parseYAML(data []byte) ([]entry, error) {
+	var entries []entry
+	section := ""
+	indent := -1
+	err := lines(data, func(line string, n int) error {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "---" {
+			return nil
+		}
+		if strings.HasPrefix(line, "\t") {
+			return fmt.Errorf("tabs are not allowed for indentation")
+		}
+
+		key, raw, ok := strings.Cut(trimmed, ":")
+		key = strings.TrimSpace(key)
+		if !ok || !isKey(key) {
+			return fmt.Errorf("want key: value, got %q", trimmed)
+		}
+		value, err := scalar(strings.TrimSpace(raw), true)
+		if err != nil {
+			return err
+		}
+
+		depth := len(line) - len(strings.TrimLeft(line, " "))
+		switch {
+		case depth == 0 && value == "":
+			section, indent = key, -1
+			return nil
+		case depth == 0:
+			return fmt.Errorf("setting %q outside a section", key)
+		case section == "":
+			return fmt.Errorf("unexpected indentation")
+		case indent == -1:
+			indent = depth
+		case depth != indent:
+			return fmt.Errorf("inconsistent indentation")
+		}
+		entries = append(entries, entry{key: section + "." + key, value: value, line: n})
+		return nil
+	})
+	return entries, err
+}
+
+func isKey(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// scalar returns the text of a quoted or bare value followed by an optional
+// comment. TOML takes only numbers and booleans bare, YAML takes any text.
+func scalar(raw string, plain bool) (string, error) {
+	var value, rest string
+	switch {
+	case strings.HasPrefix(raw, `"`):
+		end := closingQuote(raw)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated string %s", raw)
+		}
+		unquoted, err := strconv.Unquote(raw[:end+1])
+		if err != nil {
+			return "", fmt.Errorf("invalid string %s", raw[:end+1])
+		}
+		value, rest = unquoted, raw[end+1:]
+	case strings.HasPrefix(raw, "'"):
+		end := strings.IndexByte(raw[1:], '\'')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated string %s", raw)
+		}
+		value, rest = raw[1:end+1], raw[end+2:]
+	default:
+		value, _, _ = strings.Cut(raw, " #")
+		value = strings.TrimSpace(value)
+		if strings.HasPrefix(value, "#") {
+			value = ""
+		}
+		if !plain && value != "true" && value != "false" {
+			if _, err := strconv.ParseInt(strings.ReplaceAll(value, "_", ""), 10, 64); err != nil {
+				return "", fmt.Errorf("invalid value %q, quote strings", value)
+			}
+		}
+	}
+	if rest = strings.TrimSpace(rest); rest != "" && !strings.HasPrefix(rest, "#") {
+		return "", fmt.Errorf("unexpected %q after the value", rest)
+	}
+	return value, nil
+}
+
+// closingQuote returns the index of the quote ending the double quoted
+// string at the start of s, or -1.
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Korjick/go-http-quote/domain/auth"
+)
+
+// The values below let settings of every type be set from the text of a
+// file, an environment variable or a flag. They implement flag.Value.
+
+type stringValue string
+
+func (v *stringValue) Set(s string) error {
+	*v = stringValue(s)
+	return nil
+}
+
+func (v *stringValue) String() string { return string(*v) }
+
+type intValue int
+
+func (v *intValue) Set(s string) error {
+	n, err := strconv.Atoi(strings.ReplaceAll(s, "_", ""))
+	if err != nil {
+		return fmt.Errorf("invalid integer %q", s)
+	}
+	*v = intValue(n)
+	return nil
+}
+
+func (v *intValue) String() string { return strconv.Itoa(int(*v)) }
+
+type durationValue time.Duration
+
+func (v *durationValue) Set(s string) error {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q, want a number with a unit such as 30s or 5m", s)
+	}
+	*v = durationValue(d)
+	return nil
+}
+
+func (v *durationValue) String() string { return time.Duration(*v).String() }
+
+type levelValue slog.Level
+
+func (v *levelValue) Set(s string) error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return fmt.Errorf("invalid level %q, want debug, info, warn or error", s)
+	}
+	*v = levelValue(level)
+	return nil
+}
+
+func (v *levelValue) String() string { return strings.ToLower(slog.Level(*v).String()) }
+
+// prefixesValue is a comma separated list of CIDR prefixes or single
+// addresses, such as "10.0.0.0/8,127.0.0.1".
+type prefixesValue []netip.Prefix
+
+func (v *prefixesValue) Set(s string) error {
+	var prefixes []netip.Prefix
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if !strings.Contains(item, "/") {
+			addr, err := netip.ParseAddr(item)
+			if err != nil {
+				return fmt.Errorf("invalid address %q", item)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(item)
+		if err != nil {
+			return fmt.Errorf("invalid network %q", item)
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	*v = prefixes
+	return nil
+}
+
+func (v *prefixesValue) String() string {
+	items := make([]string, len(*v))
+	for i, prefix := range *v {
+		items[i] = prefix.String()
+	}
+	return strings.Join(items, ",")
+}
+
+// rolesValue maps claim values to roles as "group=role,group=role".
+type rolesValue map[string]auth.Role
+
+func (v *rolesValue) Set(s string) error {
+	roles := make(map[string]auth.Role)
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		value, name, ok := strings.Cut(pair, "=")
+		role, err := auth.ParseRole(strings.TrimSpace(name))
+		if !ok || err != nil {
+			return fmt.Errorf("invalid mapping %q, want value=role", pair)
+		}
+		roles[strings.TrimSpace(value)] = role
+	}
+	// Without mappings the claim values are role names, which nil tells.
+	if len(roles) == 0 {
+		roles = nil
+	}
+	*v = roles
+	return nil
+}
+
+func (v *rolesValue) String() string {
+	pairs := make([]string, 0, len(*v))
+	for _, value := range slices.Sorted(maps.Keys(*v)) {
+		pairs = append(pairs, value+"="+string((*v)[value]))
+	}
+	return strings.Join(pairs, ",")
+}
@@ -1,23 +1,229 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/Korjick/go-http-quote/cmd/api/config"
+	"github.com/Korjick/go-http-quote/infrastructure/jwt"
+	"github.com/Korjick/go-http-quote/infrastructure/metrics"
 	"github.com/Korjick/go-http-quote/infrastructure/repository/in_memory"
+	"github.com/Korjick/go-http-quote/infrastructure/tracing"
 
 	"github.com/Korjick/go-http-quote/application/service"
+	"github.com/Korjick/go-http-quote/domain/auth"
+	"github.com/Korjick/go-http-quote/domain/quote/repository"
+	utils "github.com/Korjick/go-http-quote/presentation/http"
+	"github.com/Korjick/go-http-quote/presentation/http/apikey"
+	"github.com/Korjick/go-http-quote/presentation/http/middleware"
+	"github.com/Korjick/go-http-quote/presentation/http/openapi"
 	"github.com/Korjick/go-http-quote/presentation/http/quote"
+	"github.com/Korjick/go-http-quote/presentation/http/quote/dto"
+	"github.com/Korjick/go-http-quote/presentation/http/server"
 )
 
 func main() {
-	repo := in_memory.NewInMemoryQuoteRepository()
-	quoteService := service.NewQuoteService(repo)
+	loaded, err := config.Load(os.Args[1:], os.LookupEnv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+		os.Exit(2)
+	}
+	if loaded.PrintConfig {
+		if err := loaded.Print(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	cfg := loaded.Config
 
-	quotePrefix := "/quotes"
-	quoteHandler := quote.NewQuoteController(quoteService, quotePrefix)
+	logger := slog.New(utils.NewLogHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: cfg.Log.Level})))
+	slog.SetDefault(logger)
 
-	http.Handle(quotePrefix+"/", quoteHandler)
-	http.Handle(quotePrefix, quoteHandler)
-	log.Fatal(http.ListenAndServe(":8080", nil))
+	registry := metrics.NewRegistry()
+	lockWait := registry.Histogram("quotes_repository_lock_wait_seconds",
+		"Time quote repository operations waited for the lock.", metrics.LockWaitBuckets, "mode")
+	var store repository.QuoteRepository
+	switch cfg.Quotes.Backend {
+	case "memory":
+		store = in_memory.NewInMemoryQuoteRepository(
+			in_memory.WithLockWaitObserver(func(mode string, wait time.Duration) {
+				lockWait.Observe(wait.Seconds(), mode)
+			}),
+		)
+	}
+	repo := metrics.InstrumentQuoteRepository(store, registry)
+	tracer, shutdownTracing, err := newTracer(cfg.Tracing)
+	if err != nil {
+		log.Fatalf("configure tracing: %v", err)
+	}
+	var serviceOptions []service.QuoteServiceOption
+	if tracer != nil {
+		repo = tracing.TraceQuoteRepository(repo, tracer)
+		serviceOptions = append(serviceOptions, service.WithTracer(tracer))
+	}
+	quoteService := service.NewQuoteService(repo, serviceOptions...)
+
+	apiKeyService := service.NewAPIKeyService(in_memory.NewInMemoryAPIKeyRepository())
+	if secret := cfg.Auth.AdminAPIKey; secret != "" {
+		if _, err := apiKeyService.RegisterAPIKey(auth.System, "bootstrap", auth.RoleAdmin, secret); err != nil {
+			log.Fatalf("register auth.admin_api_key: %v", err)
+		}
+	} else {
+		_, secret, err := apiKeyService.CreateAPIKey(auth.System, "bootstrap", auth.RoleAdmin)
+		if err != nil {
+			log.Fatalf("create bootstrap API key: %v", err)
+		}
+		// The secret is shown once on the terminal and kept out of the
+		// logs, which may be collected and stored elsewhere.
+		fmt.Fprintf(os.Stderr, "bootstrap admin API key: %s (set auth.admin_api_key to keep it across restarts)\n", secret)
+	}
+	authenticators := []middleware.Authenticator{middleware.NewAPIKeyAuthenticator(apiKeyService)}
+	if bearer, err := bearerAuthenticator(cfg.Auth); err != nil {
+		log.Fatalf("configure bearer tokens: %v", err)
+	} else if bearer != nil {
+		authenticators = append(authenticators, bearer)
+	}
+	authentication := middleware.NewAuthentication(authenticators...)
+
+	readLimit := middleware.RateLimit{Requests: cfg.Server.ReadRateLimit, Per: cfg.Server.RateLimitWindow}
+	writeLimit := middleware.RateLimit{Requests: cfg.Server.WriteRateLimit, Per: cfg.Server.RateLimitWindow}
+	failureLimit := middleware.RateLimit{Requests: cfg.Server.AuthFailureLimit, Per: cfg.Server.RateLimitWindow}
+	rateLimiter := middleware.NewRateLimiter(readLimit, writeLimit, cfg.Server.TrustedProxies, middleware.WithFailureLimit(failureLimit))
+	// protect puts a handler behind authentication and the rate limit of
+	// its route group, which tells clients apart by their principal.
+	// Rejected credentials are counted by address before authentication.
+	protect := func(group string, handler http.Handler) http.Handler {
+		return rateLimiter.WrapCredentials(authentication.Wrap(rateLimiter.WrapGroup(group, handler)))
+	}
+
+	timeouts := quote.Timeouts{Request: cfg.Quotes.RequestTimeout, Bulk: cfg.Quotes.BulkTimeout}
+	quoteController := quote.NewVersionedController(quoteService, cfg.Quotes.Prefix, quote.WithTimeouts(timeouts), quote.WithPublicURL(cfg.Server.PublicURL))
+	quoteHandler := protect("quotes", middleware.NewIdempotency(middleware.DefaultIdempotencyTTL).Wrap(quoteController))
+
+	for _, prefix := range quoteController.Prefixes() {
+		http.Handle(prefix+"/", quoteHandler)
+		http.Handle(prefix, quoteHandler)
+	}
+
+	ownQuotesController := quote.NewOwnQuotesController(quoteService, "/me", quote.WithTimeouts(timeouts))
+	http.Handle("/me/", protect("me", ownQuotesController))
+
+	apiKeyPrefix := "/admin/api-keys"
+	apiKeyController := apikey.NewController(apiKeyService, apiKeyPrefix)
+	apiKeyHandler := protect("api-keys", apiKeyController)
+	http.Handle(apiKeyPrefix+"/", apiKeyHandler)
+	http.Handle(apiKeyPrefix, apiKeyHandler)
+
+	doc := openapi.NewDocument(openapi.Info{
+		Title:   "Quotes API",
+		Version: dto.Versions[len(dto.Versions)-1].String(),
+		Description: "Responses are negotiated with the Accept header (JSON, XML, YAML, CSV, plain text); " +
+			"JSON bodies are documented. On the unversioned prefix the API version is selected with " +
+			"Accept: " + quote.VendorMediaType(dto.DefaultVersion) + " and similar. Errors are RFC 9457 problem details. " +
+			"Reading is anonymous; changes need an API key with a sufficient role.",
+	})
+	authentication.Describe(doc)
+	quoteController.Describe(doc)
+	ownQuotesController.Describe(doc)
+	apiKeyController.Describe(doc)
+	http.Handle("/openapi.json", openapi.Handler(doc))
+	http.Handle("/docs", openapi.DocsHandler("Quotes API", "/openapi.json"))
+	http.Handle("/metrics", metrics.Handler(registry))
+	accessLog := middleware.NewAccessLog(logger, cfg.Server.TrustedProxies)
+	requestMetrics := middleware.NewMetrics(metrics.NewHTTPRequests(registry))
+	var handler http.Handler = http.DefaultServeMux
+	if tracer != nil {
+		handler = middleware.NewTracing(tracer).Wrap(handler)
+	}
+
+	srv := server.New(server.Config{
+		Addr:              cfg.Server.Addr(),
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+		ShutdownTimeout:   cfg.Server.ShutdownTimeout,
+	}, accessLog.Wrap(requestMetrics.Wrap(handler)))
+	if flusher, ok := store.(repository.Flusher); ok {
+		srv.OnShutdown("flush quotes", flusher.Flush)
+	}
+	if tracer != nil {
+		srv.OnShutdown("export spans", shutdownTracing)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// A second signal during the shutdown kills the process.
+	context.AfterFunc(ctx, stop)
+	if err := srv.Run(ctx); err != nil {
+		log.Fatalf("server: %v", err)
+	}
+	slog.Info("server stopped")
+}
+
+// bearerAuthenticator configures JWT authentication. It returns nil when no
+// JWK Set is configured.
+func bearerAuthenticator(cfg config.Auth) (*middleware.BearerAuthenticator, error) {
+	var source jwt.Source
+	switch {
+	case cfg.JWKSFile != "":
+		source = jwt.FileSource(cfg.JWKSFile)
+	case cfg.JWKSURL != "":
+		source = jwt.URLSource(&http.Client{Timeout: 10 * time.Second}, cfg.JWKSURL)
+	default:
+		return nil, nil
+	}
+
+	keys := jwt.NewKeySet(source, jwt.DefaultKeySetTTL, jwt.DefaultKeySetMinRefresh)
+	if err := keys.Refresh(context.Background()); err != nil {
+		return nil, err
+	}
+
+	mapping := middleware.RoleMapping{Claim: cfg.JWTRoleClaim, Roles: cfg.JWTRoles}
+	verifier := jwt.NewVerifier(keys, cfg.JWTIssuer, cfg.JWTAudience)
+	return middleware.NewBearerAuthenticator(verifier, mapping), nil
+}
+
+// newTracer configures tracing. It returns nil when tracing is off, and
+// otherwise a shutdown that exports the last spans and closes the trace file.
+func newTracer(cfg config.Tracing) (*tracing.Tracer, func(context.Context) error, error) {
+	var exporter tracing.Exporter
+	var file *os.File
+	switch cfg.Exporter {
+	case "", "none":
+		return nil, nil, nil
+	case "stdout":
+		exporter = tracing.NewWriterExporter(os.Stdout)
+	case "file":
+		var err error
+		file, err = os.OpenFile(cfg.File, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, err
+		}
+		exporter = tracing.NewWriterExporter(file)
+	case "otlp":
+		exporter = tracing.NewOTLPExporter(&http.Client{Timeout: 10 * time.Second}, cfg.OTLPEndpoint, "quotes")
+	}
+	tracer := tracing.NewTracer(exporter)
+	shutdown := func(ctx context.Context) error {
+		err := tracer.Shutdown(ctx)
+		if file != nil {
+			err = errors.Join(err, file.Close())
+		}
+		return err
+	}
+	return tracer, shutdown, nil
 }
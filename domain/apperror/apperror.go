@@ -0,0 +1,100 @@
+package apperror
+
+import "errors"
+
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindMalformed
+	KindInvalid
+	KindNotFound
+	KindConflict
+	KindUnsupported
+	KindNotAcceptable
+	KindTooLarge
+	KindUnauthenticated
+	KindForbidden
+	KindTooManyRequests
+	// KindTimeout is for work abandoned because its deadline passed.
+	KindTimeout
+	// KindCanceled is for work abandoned because the caller went away.
+	KindCanceled
+	// KindUnavailable is for work that depends on something temporarily
+	// out of reach, such as an external service.
+	KindUnavailable
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindMalformed:
+		return "malformed"
+	case KindInvalid:
+		return "invalid"
+	case KindNotFound:
+		return "not_found"
+	case KindConflict:
+		return "conflict"
+	case KindUnsupported:
+		return "unsupported"
+	case KindNotAcceptable:
+		return "not_acceptable"
+	case KindTooLarge:
+		return "too_large"
+	case KindUnauthenticated:
+		return "unauthenticated"
+	case KindForbidden:
+		return "forbidden"
+	case KindTooManyRequests:
+		return "too_many_requests"
+	case KindTimeout:
+		return "timeout"
+	case KindCanceled:
+		return "canceled"
+	case KindUnavailable:
+		return "unavailable"
+	default:
+		return "internal"
+	}
+}
+
+// Error is an error with a kind, which transports map to their own status
+// codes, and a stable code clients can rely on instead of the message.
+// Errors are compared by identity, so declare them once as package variables
+// and wrap them with fmt.Errorf("%w") to add details.
+type Error struct {
+	kind    Kind
+	code    string
+	message string
+}
+
+func New(kind Kind, code, message string) *Error {
+	return &Error{kind: kind, code: code, message: message}
+}
+
+func (e *Error) Error() string {
+	return e.message
+}
+
+func (e *Error) Kind() Kind {
+	return e.kind
+}
+
+func (e *Error) Code() string {
+	return e.code
+}
+
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
+
+func KindOf(err error) Kind {
+	if appErr, ok := As(err); ok {
+		return appErr.kind
+	}
+	return KindInternal
+}
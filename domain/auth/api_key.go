@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+type APIKeyID int64
+
+func (id APIKeyID) String() string {
+	return strconv.FormatInt(int64(id), 10)
+}
+
+// APIKey is a stored API key. Only the SHA-256 hash of the secret is kept;
+// Prefix holds its first characters so that keys can be told apart.
+type APIKey struct {
+	ID        APIKeyID
+	Name      string
+	Role      Role
+	Prefix    string
+	Hash      string
+	CreatedAt time.Time
+	CreatedBy string
+}
+
+type APIKeyDraft struct {
+	Name      string
+	Role      Role
+	Prefix    string
+	Hash      string
+	CreatedBy string
+}
+
+func NewAPIKey(id APIKeyID, draft APIKeyDraft) (*APIKey, error) {
+	if err := draft.Validate(); err != nil {
+		return nil, err
+	}
+	return &APIKey{
+		ID:        id,
+		Name:      draft.Name,
+		Role:      draft.Role,
+		Prefix:    draft.Prefix,
+		Hash:      draft.Hash,
+		CreatedAt: time.Now(),
+		CreatedBy: draft.CreatedBy,
+	}, nil
+}
+
+func (d APIKeyDraft) Validate() error {
+	if strings.TrimSpace(d.Name) == "" {
+		return ErrEmptyAPIKeyName
+	}
+	if _, err := ParseRole(string(d.Role)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Principal is the identity requests authenticated with the key act as.
+func (k *APIKey) Principal() Principal {
+	return Principal{Subject: "api-key:" + k.ID.String(), Role: k.Role}
+}
@@ -0,0 +1,16 @@
+package auth
+
+import "github.com/Korjick/go-http-quote/domain/apperror"
+
+var (
+	ErrUnauthenticated  = apperror.New(apperror.KindUnauthenticated, "unauthenticated", "authentication required")
+	ErrInvalidAPIKey    = apperror.New(apperror.KindUnauthenticated, "invalid_api_key", "invalid API key")
+	ErrInvalidToken     = apperror.New(apperror.KindUnauthenticated, "invalid_token", "invalid bearer token")
+	ErrForbidden        = apperror.New(apperror.KindForbidden, "forbidden", "not allowed")
+	ErrUnknownRole      = apperror.New(apperror.KindInvalid, "unknown_role", "unknown role")
+	ErrEmptyAPIKeyName  = apperror.New(apperror.KindInvalid, "empty_api_key_name", "API key name cannot be empty")
+	ErrAPIKeyNotFound   = apperror.New(apperror.KindNotFound, "api_key_not_found", "API key not found")
+	ErrAPIKeyNameExists = apperror.New(apperror.KindConflict, "api_key_name_exists", "API key name is already taken")
+	ErrAPIKeyTooShort   = apperror.New(apperror.KindInvalid, "api_key_too_short", "API key is too short")
+	ErrAPIKeyInUse      = apperror.New(apperror.KindConflict, "api_key_in_use", "API key is already registered")
+)
@@ -0,0 +1,40 @@
+package auth
+
+import "fmt"
+
+// Principal is the identity a request acts as. Subject identifies the caller
+// across requests, such as an API key ID; it is empty for anonymous callers.
+type Principal struct {
+	Subject string
+	Role    Role
+}
+
+var (
+	// Anonymous is the principal of requests without credentials. It can
+	// read quotes and nothing else.
+	Anonymous = Principal{}
+	// System acts on behalf of the service itself, for example to seed data
+	// at startup.
+	System = Principal{Subject: "system", Role: RoleAdmin}
+)
+
+func (p Principal) Authenticated() bool {
+	return p.Subject != ""
+}
+
+func (p Principal) Can(permission Permission) bool {
+	return p.Role.Can(permission)
+}
+
+// Authorize returns nil if p has permission. Otherwise it asks anonymous
+// callers to authenticate and tells authenticated ones they are forbidden.
+func (p Principal) Authorize(permission Permission) error {
+	switch {
+	case p.Can(permission):
+		return nil
+	case !p.Authenticated():
+		return fmt.Errorf("%w: %s", ErrUnauthenticated, permission)
+	default:
+		return fmt.Errorf("%w: %s requires %s", ErrForbidden, permission, permissions[permission])
+	}
+}
@@ -0,0 +1,8 @@
+package auth
+
+type APIKeyRepository interface {
+	Create(draft APIKeyDraft) (*APIKey, error)
+	GetAll() ([]*APIKey, error)
+	GetByHash(hash string) (*APIKey, error)
+	Delete(id APIKeyID) error
+}
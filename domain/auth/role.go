@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Role is a set of permissions. Each role includes the permissions of the
+// roles before it in Roles.
+type Role string
+
+const (
+	RoleReader      Role = "reader"
+	RoleContributor Role = "contributor"
+	RoleModerator   Role = "moderator"
+	RoleAdmin       Role = "admin"
+)
+
+var Roles = []Role{RoleReader, RoleContributor, RoleModerator, RoleAdmin}
+
+func ParseRole(s string) (Role, error) {
+	if !slices.Contains(Roles, Role(s)) {
+		return "", fmt.Errorf("%w: %q", ErrUnknownRole, s)
+	}
+	return Role(s), nil
+}
+
+// Includes reports whether r grants everything other does.
+func (r Role) Includes(other Role) bool {
+	rank := slices.Index(Roles, r)
+	return rank >= 0 && rank >= slices.Index(Roles, other)
+}
+
+type Permission string
+
+const (
+	PermissionCreateQuote    Permission = "quotes:create"
+	PermissionDeleteOwnQuote Permission = "quotes:delete:own"
+	PermissionDeleteQuote    Permission = "quotes:delete"
+	PermissionManageAPIKeys  Permission = "api_keys:manage"
+)
+
+// permissions maps each permission to the least role granting it. Reading
+// quotes needs no permission.
+var permissions = map[Permission]Role{
+	PermissionCreateQuote:    RoleContributor,
+	PermissionDeleteOwnQuote: RoleContributor,
+	PermissionDeleteQuote:    RoleModerator,
+	PermissionManageAPIKeys:  RoleAdmin,
+}
+
+func (r Role) Can(permission Permission) bool {
+	required, ok := permissions[permission]
+	return ok && r.Includes(required)
+}
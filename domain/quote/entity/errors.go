@@ -1,9 +1,10 @@
 package entity
 
-import "errors"
+import "github.com/Korjick/go-http-quote/domain/apperror"
 
 var (
-	ErrEmptyAuthor   = errors.New("author cannot be empty")
-	ErrEmptyText     = errors.New("quote text cannot be empty")
-	ErrQuoteNotFound = errors.New("quote not found")
+	ErrEmptyAuthor   = apperror.New(apperror.KindInvalid, "empty_author", "author cannot be empty")
+	ErrEmptyText     = apperror.New(apperror.KindInvalid, "empty_text", "quote text cannot be empty")
+	ErrQuoteNotFound = apperror.New(apperror.KindNotFound, "quote_not_found", "quote not found")
+	ErrBatchAborted  = apperror.New(apperror.KindInvalid, "batch_aborted", "batch aborted, no operations were applied")
 )
@@ -7,19 +7,38 @@ import (
 
 type QuoteID int64
 
+// Quote is a stored quote. CreatedBy is the subject of the principal that
+// added it, empty for quotes added before it was recorded. Owner is the
+// subject that may change the quote without moderator rights; it starts as
+// the creator.
 type Quote struct {
 	ID        QuoteID
 	Author    string
 	Text      string
 	CreatedAt time.Time
+	CreatedBy string
+	Owner     string
+}
+
+type QuoteDraft struct {
+	Author    string
+	Text      string
+	CreatedBy string
+	Owner     string
 }
 
 func NewQuote(id QuoteID, author, text string) (*Quote, error) {
+	return NewQuoteFromDraft(id, QuoteDraft{Author: author, Text: text})
+}
+
+func NewQuoteFromDraft(id QuoteID, draft QuoteDraft) (*Quote, error) {
 	quote := &Quote{
 		ID:        id,
-		Author:    author,
-		Text:      text,
+		Author:    draft.Author,
+		Text:      draft.Text,
 		CreatedAt: time.Now(),
+		CreatedBy: draft.CreatedBy,
+		Owner:     draft.Owner,
 	}
 
 	if err := quote.validate(); err != nil {
@@ -29,6 +48,17 @@ func NewQuote(id QuoteID, author, text string) (*Quote, error) {
 	return quote, nil
 }
 
+func (d QuoteDraft) Validate() error {
+	quote := &Quote{Author: d.Author, Text: d.Text}
+	return quote.validate()
+}
+
+// OwnedBy reports whether subject owns q. Quotes without an owner belong
+// to nobody.
+func (q *Quote) OwnedBy(subject string) bool {
+	return q.Owner != "" && q.Owner == subject
+}
+
 func (q *Quote) validate() error {
 	if strings.TrimSpace(q.Author) == "" {
 		return ErrEmptyAuthor
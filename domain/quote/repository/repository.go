@@ -1,11 +1,69 @@
 package repository
 
-import "github.com/Korjick/go-http-quote/domain/quote/entity"
+import (
+	"context"
+	"time"
 
+	"github.com/Korjick/go-http-quote/domain/quote/entity"
+)
+
+// QuoteRepository stores quotes. Every operation returns the error of ctx
+// instead of starting once ctx is done.
 type QuoteRepository interface {
-	Create(author, text string) (*entity.Quote, error)
-	GetAll() ([]*entity.Quote, error)
-	GetByAuthor(author string) ([]*entity.Quote, error)
-	GetRandom() (*entity.Quote, error)
-	Delete(id entity.QuoteID) error
+	Create(ctx context.Context, draft entity.QuoteDraft) (*entity.Quote, error)
+	CreateMany(ctx context.Context, drafts []entity.QuoteDraft) ([]*entity.Quote, error)
+	GetAll(ctx context.Context) ([]*entity.Quote, error)
+	GetByID(ctx context.Context, id entity.QuoteID) (*entity.Quote, error)
+	GetByAuthor(ctx context.Context, author string) ([]*entity.Quote, error)
+	GetByOwner(ctx context.Context, owner string) ([]*entity.Quote, error)
+	GetRandom(ctx context.Context) (*entity.Quote, error)
+	// GetPage returns up to limit quotes with an ID greater than after,
+	// ordered by ID.
+	GetPage(ctx context.Context, after entity.QuoteID, limit int) ([]*entity.Quote, error)
+	Snapshot(ctx context.Context) (Snapshot, error)
+	Delete(ctx context.Context, id entity.QuoteID) error
+	// ApplyBatch applies all operations atomically. If any of them fails,
+	// none is applied, ErrBatchAborted is returned and the results tell
+	// which operations failed.
+	ApplyBatch(ctx context.Context, ops []Operation) ([]OperationResult, error)
+}
+
+// Flusher is implemented by repositories that hold writes back, which must
+// be saved before the process exits.
+type Flusher interface {
+	Flush(ctx context.Context) error
+}
+
+type OperationKind string
+
+const (
+	OperationCreate OperationKind = "create"
+	OperationDelete OperationKind = "delete"
+)
+
+type Operation struct {
+	Kind  OperationKind
+	Draft entity.QuoteDraft
+	ID    entity.QuoteID
+	// Precondition, if set, is checked against the quote a delete removes
+	// at the moment it is removed. An error fails the operation.
+	Precondition func(*entity.Quote) error
+}
+
+type OperationResult struct {
+	Quote *entity.Quote
+	Err   error
+}
+
+// Snapshot marks a point in the repository history. Revision grows with every
+// change and MaxID is the highest ID assigned so far, so quotes created after
+// the snapshot can be told apart by their ID.
+type Snapshot struct {
+	Revision uint64
+	MaxID    entity.QuoteID
+	Count    int
+	TakenAt  time.Time
+	// ModifiedAt is the time of the last change to the collection, zero if
+	// it has never changed.
+	ModifiedAt time.Time
 }
@@ -0,0 +1,147 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+const minRSAKeyBits = 2048
+
+// JWK is a public JSON Web Key (RFC 7517). Only the members needed for
+// RSA, P-256 and Ed25519 signature keys are decoded.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// Algorithm is the JWS algorithm the key verifies. A key without "alg" is
+// used with the one algorithm its type supports.
+func (k JWK) Algorithm() (string, error) {
+	var alg string
+	switch {
+	case k.Kty == "RSA":
+		alg = AlgRS256
+	case k.Kty == "EC" && k.Crv == "P-256":
+		alg = AlgES256
+	case k.Kty == "OKP" && k.Crv == "Ed25519":
+		alg = AlgEdDSA
+	default:
+		return "", fmt.Errorf("unsupported key type %s %s", k.Kty, k.Crv)
+	}
+	if k.Alg != "" && k.Alg != alg {
+		return "", fmt.Errorf("unsupported algorithm %s for key type %s", k.Alg, k.Kty)
+	}
+	return alg, nil
+}
+
+// PublicKey decodes the key into an *rsa.PublicKey, *ecdsa.PublicKey or
+// ed25519.PublicKey.
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	alg, err := k.Algorithm()
+	if err != nil {
+		return nil, err
+	}
+	switch alg {
+	case AlgRS256:
+		n, err := decodeInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("modulus: %w", err)
+		}
+		e, err := decodeInt(k.E)
+		if err != nil || !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid public exponent")
+		}
+		if n.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("RSA key of %d bits is shorter than %d", n.BitLen(), minRSAKeyBits)
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case AlgES256:
+		x, errX := decodeFixed(k.X, 32)
+		y, errY := decodeFixed(k.Y, 32)
+		if err := errors.Join(errX, errY); err != nil {
+			return nil, err
+		}
+		// crypto/ecdh checks that the point is on the curve.
+		if _, err := ecdh.P256().NewPublicKey(append(append([]byte{4}, x...), y...)); err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	default:
+		x, err := decodeFixed(k.X, ed25519.PublicKeySize)
+		if err != nil {
+			return nil, err
+		}
+		return ed25519.PublicKey(x), nil
+	}
+}
+
+// Key is a decoded signature key of a key set.
+type Key struct {
+	ID        string
+	Algorithm string
+	Public    crypto.PublicKey
+}
+
+// ParseKeySet decodes a JWK Set document. Keys that are not meant for
+// signatures or are of an unsupported type are skipped, so a set can
+// announce new kinds of keys without breaking older verifiers; a set
+// without a single usable key is an error.
+func ParseKeySet(data []byte) ([]Key, error) {
+	var set struct {
+		Keys []JWK `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("decode JWK set: %w", err)
+	}
+
+	var keys []Key
+	var errs []error
+	for i, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		public, err := jwk.PublicKey()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("key %d (%q): %w", i, jwk.Kid, err))
+			continue
+		}
+		alg, _ := jwk.Algorithm()
+		keys = append(keys, Key{ID: jwk.Kid, Algorithm: alg, Public: public})
+	}
+	if len(keys) == 0 {
+		return nil, errors.Join(append([]error{errors.New("JWK set has no usable signature keys")}, errs...)...)
+	}
+	return keys, nil
+}
+
+func decodeInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("invalid base64url integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func decodeFixed(s string, size int) ([]byte, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) != size {
+		return nil, fmt.Errorf("expected %d base64url bytes", size)
+	}
+	return b, nil
+}
@@ -0,0 +1,212 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Korjick/go-http-quote/domain/apperror"
+	"github.com/Korjick/go-http-quote/domain/auth"
+)
+
+const (
+	DefaultKeySetTTL        = 15 * time.Minute
+	DefaultKeySetMinRefresh = 30 * time.Second
+	maxKeySetSize           = 1 << 20
+)
+
+// ErrKeySetUnavailable means no keys could be loaded, so tokens cannot be
+// checked until the source is back.
+var ErrKeySetUnavailable = apperror.New(apperror.KindUnavailable, "key_set_unavailable", "token signing keys are unavailable, retry later")
+
+// Source loads a JWK Set document. maxAge is how long the document may be
+// cached according to its origin, zero if it does not say.
+type Source func(ctx context.Context) (data []byte, maxAge time.Duration, err error)
+
+// FileSource reads the key set from a local file on every refresh.
+func FileSource(path string) Source {
+	return func(context.Context) ([]byte, time.Duration, error) {
+		data, err := os.ReadFile(path)
+		return data, 0, err
+	}
+}
+
+// URLSource downloads the key set, honoring the max-age of its
+// Cache-Control header.
+func URLSource(client *http.Client, url string) Source {
+	return func(ctx context.Context) ([]byte, time.Duration, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, 0, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, 0, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, 0, fmt.Errorf("GET %s: %s", url, resp.Status)
+		}
+		data, err := io.ReadAll(io.LimitReader(resp.Body, maxKeySetSize))
+		return data, maxAge(resp.Header.Get("Cache-Control")), err
+	}
+}
+
+func maxAge(cacheControl string) time.Duration {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-store", "no-cache":
+			return 0
+		case "max-age":
+			if seconds, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil && seconds > 0 {
+				return time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	return 0
+}
+
+// KeySet caches the keys of a Source. The keys are reloaded once they are
+// older than the TTL, or the max-age of the source, and early when a token
+// names a key the set does not have yet, which is how issuers rotate keys.
+// Early reloads happen at most once per minRefresh, so tokens with made-up
+// key IDs cannot flood the source. When a reload fails the previous keys
+// stay in use.
+//
+// A single reload runs at a time, outside the lock: requests that need it
+// wait for it, while the others keep using the keys loaded before.
+type KeySet struct {
+	source     Source
+	ttl        time.Duration
+	minRefresh time.Duration
+	keys       []Key
+	lastErr    error
+	fetchedAt  time.Time
+	expiresAt  time.Time
+	// reloading is closed when the reload in flight ends, nil without one.
+	reloading chan struct{}
+	mutex     sync.Mutex
+}
+
+func NewKeySet(source Source, ttl, minRefresh time.Duration) *KeySet {
+	return &KeySet{source: source, ttl: ttl, minRefresh: minRefresh}
+}
+
+// Refresh reloads the keys now, for example to fail fast at startup.
+func (s *KeySet) Refresh(ctx context.Context) error {
+	s.reload(ctx, true)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.lastErr
+}
+
+// Key returns the key with id that verifies alg. Tokens without a key ID
+// are accepted only while the set has a single key for alg. A reload it
+// needs is canceled with ctx.
+func (s *KeySet) Key(ctx context.Context, id, alg string) (Key, error) {
+	s.mutex.Lock()
+	expired, loaded := !time.Now().Before(s.expiresAt), s.keys != nil
+	s.mutex.Unlock()
+	if expired {
+		// Expired keys still serve while another request reloads them.
+		s.reload(ctx, !loaded)
+	}
+
+	s.mutex.Lock()
+	if s.keys == nil {
+		err := s.lastErr
+		s.mutex.Unlock()
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		return Key{}, fmt.Errorf("%w: %v", ErrKeySetUnavailable, err)
+	}
+	key, ok := s.find(id, alg)
+	early := !ok && time.Since(s.fetchedAt) >= s.minRefresh
+	s.mutex.Unlock()
+
+	if early {
+		s.reload(ctx, true)
+		s.mutex.Lock()
+		key, ok = s.find(id, alg)
+		s.mutex.Unlock()
+	}
+	if !ok {
+		return Key{}, fmt.Errorf("%w: no %s key %q", auth.ErrInvalidToken, alg, id)
+	}
+	return key, nil
+}
+
+// find looks up a key. Callers must hold the lock.
+func (s *KeySet) find(id, alg string) (Key, bool) {
+	var found []Key
+	for _, key := range s.keys {
+		if key.Algorithm == alg && (id == "" || key.ID == id) {
+			found = append(found, key)
+		}
+	}
+	if len(found) != 1 {
+		return Key{}, false
+	}
+	return found[0], true
+}
+
+// reload starts a reload unless one is in flight. With wait it returns once
+// the reload in flight ends or ctx is done, otherwise it returns at once
+// when another request is reloading.
+func (s *KeySet) reload(ctx context.Context, wait bool) {
+	s.mutex.Lock()
+	if done := s.reloading; done != nil {
+		s.mutex.Unlock()
+		if wait {
+			select {
+			case <-done:
+			case <-ctx.Done():
+			}
+		}
+		return
+	}
+	done := make(chan struct{})
+	s.reloading = done
+	s.mutex.Unlock()
+
+	now := time.Now()
+	data, maxAge, err := s.source(ctx)
+	var keys []Key
+	if err == nil {
+		keys, err = ParseKeySet(data)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.reloading = nil
+	close(done)
+	// A canceled request says nothing about the source, so it neither
+	// replaces the keys nor delays the next reload.
+	if ctx.Err() != nil {
+		return
+	}
+	s.fetchedAt = now
+	s.lastErr = err
+	if err != nil {
+		s.expiresAt = now.Add(s.minRefresh)
+		return
+	}
+
+	ttl := s.ttl
+	if maxAge > 0 {
+		ttl = max(maxAge, s.minRefresh)
+	}
+	s.keys = keys
+	s.expiresAt = now.Add(ttl)
+}
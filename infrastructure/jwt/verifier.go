@@ -0,0 +1,188 @@
+// Package jwt verifies JSON Web Tokens (RFC 7519) signed with RS256, ES256
+// or EdDSA against the keys of a JWK Set.
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Korjick/go-http-quote/domain/auth"
+)
+
+const (
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+	AlgEdDSA = "EdDSA"
+
+	// DefaultLeeway absorbs clock skew between the issuer and the service.
+	DefaultLeeway = time.Minute
+)
+
+// Claims are the decoded claims of a token. Numbers are json.Number.
+type Claims = map[string]any
+
+type header struct {
+	Alg  string   `json:"alg"`
+	Kid  string   `json:"kid"`
+	Crit []string `json:"crit"`
+}
+
+// Verifier checks the signature, lifetime, issuer and audience of tokens.
+// An empty issuer or audience is not checked.
+type Verifier struct {
+	keys     *KeySet
+	issuer   string
+	audience string
+	leeway   time.Duration
+}
+
+func NewVerifier(keys *KeySet, issuer, audience string) *Verifier {
+	return &Verifier{keys: keys, issuer: issuer, audience: audience, leeway: DefaultLeeway}
+}
+
+// Verify returns the claims of a valid token. Invalid tokens yield errors
+// wrapping auth.ErrInvalidToken; ErrKeySetUnavailable means the token could
+// not be checked at all, as when ctx ends while the keys reload.
+func (v *Verifier) Verify(ctx context.Context, token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, invalid("not a signed JWT")
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, invalid("header: %v", err)
+	}
+	if len(h.Crit) > 0 {
+		return nil, invalid("unsupported critical headers %v", h.Crit)
+	}
+	if !slices.Contains([]string{AlgRS256, AlgES256, AlgEdDSA}, h.Alg) {
+		return nil, invalid("unsupported algorithm %q", h.Alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, invalid("signature is not base64url")
+	}
+	key, err := v.keys.Key(ctx, h.Kid, h.Alg)
+	if err != nil {
+		return nil, err
+	}
+	if !verifySignature(key, []byte(parts[0]+"."+parts[1]), signature) {
+		return nil, invalid("bad signature")
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, invalid("claims: %v", err)
+	}
+	if err := v.validate(claims, time.Now()); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (v *Verifier) validate(claims Claims, now time.Time) error {
+	expires, ok, err := numericDate(claims, "exp")
+	switch {
+	case err != nil:
+		return err
+	case !ok:
+		return invalid("no expiration time")
+	case !now.Before(expires.Add(v.leeway)):
+		return invalid("expired at %s", expires.UTC().Format(time.RFC3339))
+	}
+	notBefore, ok, err := numericDate(claims, "nbf")
+	if err != nil {
+		return err
+	}
+	if ok && now.Add(v.leeway).Before(notBefore) {
+		return invalid("not valid before %s", notBefore.UTC().Format(time.RFC3339))
+	}
+
+	if subject, _ := claims["sub"].(string); subject == "" {
+		return invalid("no subject")
+	}
+	if v.issuer != "" && claims["iss"] != v.issuer {
+		return invalid("unexpected issuer %v", claims["iss"])
+	}
+	if v.audience != "" && !hasAudience(claims["aud"], v.audience) {
+		return invalid("not issued for %s", v.audience)
+	}
+	return nil
+}
+
+func verifySignature(key Key, input, signature []byte) bool {
+	digest := sha256.Sum256(input)
+	switch public := key.Public.(type) {
+	case *rsa.PublicKey:
+		return key.Algorithm == AlgRS256 && rsa.VerifyPKCS1v15(public, crypto.SHA256, digest[:], signature) == nil
+	case *ecdsa.PublicKey:
+		// JWS encodes ECDSA signatures as the fixed-size R and S concatenated.
+		if key.Algorithm != AlgES256 || len(signature) != 64 {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(public, digest[:], r, s)
+	case ed25519.PublicKey:
+		return key.Algorithm == AlgEdDSA && ed25519.Verify(public, input, signature)
+	default:
+		return false
+	}
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return fmt.Errorf("not base64url")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+func numericDate(claims Claims, name string) (time.Time, bool, error) {
+	value, ok := claims[name]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+	number, ok := value.(json.Number)
+	if !ok {
+		return time.Time{}, false, invalid("%s is not a number", name)
+	}
+	seconds, err := number.Float64()
+	if err != nil || math.IsInf(seconds, 0) || math.Abs(seconds) > 1e11 {
+		return time.Time{}, false, invalid("%s is not a valid date", name)
+	}
+	whole, fraction := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(fraction*1e9)), true, nil
+}
+
+func hasAudience(claim any, audience string) bool {
+	switch aud := claim.(type) {
+	case string:
+		return aud == audience
+	case []any:
+		return slices.Contains(aud, any(audience))
+	default:
+		return false
+	}
+}
+
+func invalid(format string, args ...any) error {
+	return fmt.Errorf("%w: "+format, append([]any{auth.ErrInvalidToken}, args...)...)
+}
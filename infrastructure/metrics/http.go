@@ -0,0 +1,28 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+)
+
+// HTTPRequests counts the requests served and their latency by method,
+// route and status.
+type HTTPRequests struct {
+	total    *Counter
+	duration *Histogram
+}
+
+func NewHTTPRequests(registry *Registry) *HTTPRequests {
+	return &HTTPRequests{
+		total: registry.Counter("http_requests_total",
+			"Number of HTTP requests served.", "method", "route", "status"),
+		duration: registry.Histogram("http_request_duration_seconds",
+			"Latency of HTTP requests.", DefaultBuckets, "method", "route", "status"),
+	}
+}
+
+func (m *HTTPRequests) ObserveRequest(method, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	m.total.Inc(method, route, code)
+	m.duration.Observe(duration.Seconds(), method, route, code)
+}
@@ -0,0 +1,293 @@
+// Package metrics keeps counters, gauges and histograms in memory and
+// exposes them in the Prometheus text exposition format, so that the
+// service can be scraped without a client library.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+var (
+	// DefaultBuckets suit request latencies in seconds.
+	DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+	// LockWaitBuckets suit waits for an in-process lock, in seconds.
+	LockWaitBuckets = []float64{1e-6, 1e-5, 1e-4, 1e-3, 1e-2, .1, 1}
+)
+
+// Registry holds the metric families in registration order. Registering a
+// name twice panics, as it is a programming error.
+type Registry struct {
+	families []family
+	names    map[string]bool
+	mutex    sync.Mutex
+}
+
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]bool)}
+}
+
+type family interface {
+	write(w *bufio.Writer)
+}
+
+func (r *Registry) register(name string, f family) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.names[name] {
+		panic("metrics: duplicate metric " + name)
+	}
+	r.names[name] = true
+	r.families = append(r.families, f)
+}
+
+// Counter registers a counter with the given label names.
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	c := &Counter{header: header{name, help, "counter", labels}, values: make(map[string]*series)}
+	r.register(name, c)
+	return c
+}
+
+// Histogram registers a histogram with the given upper bounds, which must
+// be sorted, and label names.
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	h := &Histogram{header: header{name, help, "histogram", labels}, buckets: buckets, values: make(map[string]*series)}
+	r.register(name, h)
+	return h
+}
+
+// GaugeFunc registers a gauge without labels whose value is read from fn
+// at every scrape.
+func (r *Registry) GaugeFunc(name, help string, fn func() float64) {
+	r.register(name, &gaugeFunc{header: header{name, help, "gauge", nil}, fn: fn})
+}
+
+// WriteTo writes every metric in the text exposition format.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mutex.Lock()
+	families := slices.Clone(r.families)
+	r.mutex.Unlock()
+
+	counter := &countingWriter{w: w}
+	buf := bufio.NewWriter(counter)
+	for _, f := range families {
+		f.write(buf)
+	}
+	err := buf.Flush()
+	return counter.n, err
+}
+
+// Handler serves the metrics of r, as scraped by Prometheus.
+func Handler(r *Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", ContentType)
+		r.WriteTo(w)
+	})
+}
+
+type header struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+}
+
+func (h header) write(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", h.name, escapeHelp(h.help), h.name, h.kind)
+}
+
+// series is one combination of label values.
+type series struct {
+	labels []string
+	value  float64
+	counts []uint64
+	count  uint64
+}
+
+// key joins label values with a byte that cannot occur in valid UTF-8.
+func (h header) key(values []string) string {
+	if len(values) != len(h.labels) {
+		panic(fmt.Sprintf("metrics: %s takes %d label values, got %d", h.name, len(h.labels), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+// labelPairs formats the label values, followed by the extra name and
+// value if given.
+func (h header) labelPairs(values []string, extra ...string) string {
+	if len(values) == 0 && len(extra) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, value := range values {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(h.labels[i] + `="` + escapeLabel(value) + `"`)
+	}
+	if len(extra) == 2 {
+		if len(values) > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(extra[0] + `="` + escapeLabel(extra[1]) + `"`)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// sorted returns the series ordered by label values, for a stable output.
+func sorted(values map[string]*series) []*series {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	result := make([]*series, len(keys))
+	for i, key := range keys {
+		result[i] = values[key]
+	}
+	return result
+}
+
+// Counter is a monotonically increasing value per label combination.
+type Counter struct {
+	header
+	values map[string]*series
+	mutex  sync.Mutex
+}
+
+// Inc adds one to the series of the label values.
+func (c *Counter) Inc(labels ...string) {
+	c.Add(1, labels...)
+}
+
+// Add adds delta, which must not be negative, to the series of the label
+// values.
+func (c *Counter) Add(delta float64, labels ...string) {
+	key := c.key(labels)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	s, ok := c.values[key]
+	if !ok {
+		s = &series{labels: slices.Clone(labels)}
+		c.values[key] = s
+	}
+	s.value += delta
+}
+
+func (c *Counter) write(w *bufio.Writer) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.header.write(w)
+	for _, s := range sorted(c.values) {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, c.labelPairs(s.labels), formatFloat(s.value))
+	}
+}
+
+// Histogram counts observations in cumulative buckets per label
+// combination.
+type Histogram struct {
+	header
+	buckets []float64
+	values  map[string]*series
+	mutex   sync.Mutex
+}
+
+// Observe records value in the series of the label values.
+func (h *Histogram) Observe(value float64, labels ...string) {
+	key := h.key(labels)
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	s, ok := h.values[key]
+	if !ok {
+		s = &series{labels: slices.Clone(labels), counts: make([]uint64, len(h.buckets))}
+		h.values[key] = s
+	}
+	if i, _ := slices.BinarySearch(h.buckets, value); i < len(h.buckets) {
+		s.counts[i]++
+	}
+	s.value += value
+	s.count++
+}
+
+func (h *Histogram) write(w *bufio.Writer) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.header.write(w)
+	for _, s := range sorted(h.values) {
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelPairs(s.labels, "le", formatFloat(bound)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelPairs(s.labels, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, h.labelPairs(s.labels), formatFloat(s.value))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, h.labelPairs(s.labels), s.count)
+	}
+}
+
+type gaugeFunc struct {
+	header
+	fn func() float64
+}
+
+func (g *gaugeFunc) write(w *bufio.Writer) {
+	g.header.write(w)
+	fmt.Fprintf(w, "%s %s\n", g.name, formatFloat(g.fn()))
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(b []byte) (int, error) {
+	n, err := w.w.Write(b)
+	w.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/Korjick/go-http-quote/domain/quote/entity"
+	"github.com/Korjick/go-http-quote/domain/quote/repository"
+)
+
+// instrumentedQuoteRepository times every call of the repository it wraps.
+type instrumentedQuoteRepository struct {
+	repo     repository.QuoteRepository
+	duration *Histogram
+}
+
+// InstrumentQuoteRepository wraps repo so that the duration and outcome of
+// every operation is recorded in registry, along with the number of quotes
+// stored.
+func InstrumentQuoteRepository(repo repository.QuoteRepository, registry *Registry) repository.QuoteRepository {
+	registry.GaugeFunc("quotes_stored", "Number of quotes in the repository.", func() float64 {
+		snapshot, err := repo.Snapshot(context.Background())
+		if err != nil {
+			return 0
+		}
+		return float64(snapshot.Count)
+	})
+	return &instrumentedQuoteRepository{
+		repo: repo,
+		duration: registry.Histogram("quotes_repository_operation_duration_seconds",
+			"Duration of quote repository operations.", DefaultBuckets, "operation", "outcome"),
+	}
+}
+
+func (r *instrumentedQuoteRepository) observe(operation string, start time.Time, err error) {
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+	}
+	r.duration.Observe(time.Since(start).Seconds(), operation, outcome)
+}
+
+func (r *instrumentedQuoteRepository) Create(ctx context.Context, draft entity.QuoteDraft) (*entity.Quote, error) {
+	start := time.Now()
+	result, err := r.repo.Create(ctx, draft)
+	r.observe("create", start, err)
+	return result, err
+}
+
+func (r *instrumentedQuoteRepository) CreateMany(ctx context.Context, drafts []entity.QuoteDraft) ([]*entity.Quote, error) {
+	start := time.Now()
+	result, err := r.repo.CreateMany(ctx, drafts)
+	r.observe("create_many", start, err)
+	return result, err
+}
+
+func (r *instrumentedQuoteRepository) GetAll(ctx context.Context) ([]*entity.Quote, error) {
+	start := time.Now()
+	result, err := r.repo.GetAll(ctx)
+	r.observe("get_all", start, err)
+	return result, err
+}
+
+func (r *instrumentedQuoteRepository) GetByID(ctx context.Context, id entity.QuoteID) (*entity.Quote, error) {
+	start := time.Now()
+	result, err := r.repo.GetByID(ctx, id)
+	r.observe("get_by_id", start, err)
+	return result, err
+}
+
+func (r *instrumentedQuoteRepository) GetByAuthor(ctx context.Context, author string) ([]*entity.Quote, error) {
+	start := time.Now()
+	result, err := r.repo.GetByAuthor(ctx, author)
+	r.observe("get_by_author", start, err)
+	return result, err
+}
+
+func (r *instrumentedQuoteRepository) GetByOwner(ctx context.Context, owner string) ([]*entity.Quote, error) {
+	start := time.Now()
+	result, err := r.repo.GetByOwner(ctx, owner)
+	r.observe("get_by_owner", start, err)
+	return result, err
+}
+
+func (r *instrumentedQuoteRepository) GetRandom(ctx context.Context) (*entity.Quote, error) {
+	start := time.Now()
+	result, err := r.repo.GetRandom(ctx)
+	r.observe("get_random", start, err)
+	return result, err
+}
+
+func (r *instrumentedQuoteRepository) GetPage(ctx context.Context, after entity.QuoteID, limit int) ([]*entity.Quote, error) {
+	start := time.Now()
+	result, err := r.repo.GetPage(ctx, after, limit)
+	r.observe("get_page", start, err)
+	return result, err
+}
+
+func (r *instrumentedQuoteRepository) Snapshot(ctx context.Context) (repository.Snapshot, error) {
+	start := time.Now()
+	result, err := r.repo.Snapshot(ctx)
+	r.observe("snapshot", start, err)
+	return result, err
+}
+
+func (r *instrumentedQuoteRepository) Delete(ctx context.Context, id entity.QuoteID) error {
+	start := time.Now()
+	err := r.repo.Delete(ctx, id)
+	r.observe("delete", start, err)
+	return err
+}
+
+func (r *instrumentedQuoteRepository) ApplyBatch(ctx context.Context, ops []repository.Operation) ([]repository.OperationResult, error) {
+	start := time.Now()
+	result, err := r.repo.ApplyBatch(ctx, ops)
+	r.observe("apply_batch", start, err)
+	return result, err
+}
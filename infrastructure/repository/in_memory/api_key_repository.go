@@ -0,0 +1,88 @@
+package in_memory
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/Korjick/go-http-quote/domain/auth"
+)
+
+type inMemoryAPIKeyRepository struct {
+	keys   map[auth.APIKeyID]*auth.APIKey
+	byHash map[string]*auth.APIKey
+	lastID auth.APIKeyID
+	mutex  sync.RWMutex
+}
+
+func NewInMemoryAPIKeyRepository() auth.APIKeyRepository {
+	return &inMemoryAPIKeyRepository{
+		keys:   make(map[auth.APIKeyID]*auth.APIKey),
+		byHash: make(map[string]*auth.APIKey),
+	}
+}
+
+func (r *inMemoryAPIKeyRepository) Create(draft auth.APIKeyDraft) (*auth.APIKey, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, key := range r.keys {
+		if key.Name == draft.Name {
+			return nil, fmt.Errorf("%w: %q", auth.ErrAPIKeyNameExists, draft.Name)
+		}
+	}
+	// Keys are looked up by hash, so two keys with one secret would make
+	// the principal of that secret ambiguous.
+	if _, ok := r.byHash[draft.Hash]; ok {
+		return nil, auth.ErrAPIKeyInUse
+	}
+
+	key, err := auth.NewAPIKey(r.lastID+1, draft)
+	if err != nil {
+		return nil, err
+	}
+
+	r.lastID = key.ID
+	r.keys[key.ID] = key
+	r.byHash[key.Hash] = key
+	return key, nil
+}
+
+func (r *inMemoryAPIKeyRepository) GetAll() ([]*auth.APIKey, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	keys := make([]*auth.APIKey, 0, len(r.keys))
+	for _, key := range r.keys {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b *auth.APIKey) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return keys, nil
+}
+
+func (r *inMemoryAPIKeyRepository) GetByHash(hash string) (*auth.APIKey, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	key, ok := r.byHash[hash]
+	if !ok {
+		return nil, auth.ErrAPIKeyNotFound
+	}
+	return key, nil
+}
+
+func (r *inMemoryAPIKeyRepository) Delete(id auth.APIKeyID) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	key, ok := r.keys[id]
+	if !ok {
+		return auth.ErrAPIKeyNotFound
+	}
+	delete(r.keys, id)
+	delete(r.byHash, key.Hash)
+	return nil
+}
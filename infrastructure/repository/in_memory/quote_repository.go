@@ -1,41 +1,123 @@
 package in_memory
 
 import (
+	"cmp"
+	"context"
+	"fmt"
 	"github.com/Korjick/go-http-quote/domain/quote/repository"
+	"log/slog"
 	"math/rand"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/Korjick/go-http-quote/domain/quote/entity"
 )
 
 type inMemoryQuoteRepository struct {
-	quotes []*entity.Quote
-	mutex  sync.RWMutex
+	quotes     []*entity.Quote
+	lastID     entity.QuoteID
+	revision   uint64
+	modifiedAt time.Time
+	mutex      sync.RWMutex
+	lockWait   LockWaitObserver
 }
 
-func NewInMemoryQuoteRepository() repository.QuoteRepository {
-	return &inMemoryQuoteRepository{
+// LockWaitObserver is told how long an operation waited for the lock of
+// the repository, in "read" or "write" mode.
+type LockWaitObserver func(mode string, wait time.Duration)
+
+type Option func(*inMemoryQuoteRepository)
+
+// WithLockWaitObserver reports every lock acquisition to observe, to tell
+// contention apart from slow operations.
+func WithLockWaitObserver(observe LockWaitObserver) Option {
+	return func(r *inMemoryQuoteRepository) {
+		r.lockWait = observe
+	}
+}
+
+func NewInMemoryQuoteRepository(options ...Option) repository.QuoteRepository {
+	r := &inMemoryQuoteRepository{
 		quotes: make([]*entity.Quote, 0),
 	}
+	for _, option := range options {
+		option(r)
+	}
+	return r
 }
 
-func (r *inMemoryQuoteRepository) Create(author, text string) (*entity.Quote, error) {
+func (r *inMemoryQuoteRepository) lock() {
+	start := time.Now()
 	r.mutex.Lock()
+	if r.lockWait != nil {
+		r.lockWait("write", time.Since(start))
+	}
+}
+
+func (r *inMemoryQuoteRepository) rlock() {
+	start := time.Now()
+	r.mutex.RLock()
+	if r.lockWait != nil {
+		r.lockWait("read", time.Since(start))
+	}
+}
+
+func (r *inMemoryQuoteRepository) Create(ctx context.Context, draft entity.QuoteDraft) (*entity.Quote, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.lock()
 	defer r.mutex.Unlock()
 
-	id := entity.QuoteID(len(r.quotes) + 1)
-	quote, err := entity.NewQuote(id, author, text)
+	quote, err := entity.NewQuoteFromDraft(r.lastID+1, draft)
 	if err != nil {
 		return nil, err
 	}
 
+	r.lastID = quote.ID
+	r.touch()
 	r.quotes = append(r.quotes, quote)
+	slog.DebugContext(ctx, "quote stored", "id", quote.ID, "revision", r.revision)
 	return quote, nil
 }
 
-func (r *inMemoryQuoteRepository) GetAll() ([]*entity.Quote, error) {
-	r.mutex.RLock()
+func (r *inMemoryQuoteRepository) CreateMany(ctx context.Context, drafts []entity.QuoteDraft) ([]*entity.Quote, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.lock()
+	defer r.mutex.Unlock()
+
+	created := make([]*entity.Quote, 0, len(drafts))
+	for i, draft := range drafts {
+		id := r.lastID + entity.QuoteID(i+1)
+		quote, err := entity.NewQuoteFromDraft(id, draft)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, quote)
+	}
+
+	if len(created) > 0 {
+		r.lastID = created[len(created)-1].ID
+		r.touch()
+	}
+	r.quotes = append(r.quotes, created...)
+	slog.DebugContext(ctx, "quotes stored", "count", len(created), "revision", r.revision)
+	return created, nil
+}
+
+func (r *inMemoryQuoteRepository) GetAll(ctx context.Context) ([]*entity.Quote, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.rlock()
 	defer r.mutex.RUnlock()
 
 	result := make([]*entity.Quote, len(r.quotes))
@@ -43,8 +125,29 @@ func (r *inMemoryQuoteRepository) GetAll() ([]*entity.Quote, error) {
 	return result, nil
 }
 
-func (r *inMemoryQuoteRepository) GetByAuthor(author string) ([]*entity.Quote, error) {
-	r.mutex.RLock()
+func (r *inMemoryQuoteRepository) GetByID(ctx context.Context, id entity.QuoteID) (*entity.Quote, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.rlock()
+	defer r.mutex.RUnlock()
+
+	index, found := slices.BinarySearchFunc(r.quotes, id, func(q *entity.Quote, id entity.QuoteID) int {
+		return cmp.Compare(q.ID, id)
+	})
+	if !found {
+		return nil, entity.ErrQuoteNotFound
+	}
+	return r.quotes[index], nil
+}
+
+func (r *inMemoryQuoteRepository) GetByAuthor(ctx context.Context, author string) ([]*entity.Quote, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.rlock()
 	defer r.mutex.RUnlock()
 
 	var result []*entity.Quote
@@ -56,8 +159,29 @@ func (r *inMemoryQuoteRepository) GetByAuthor(author string) ([]*entity.Quote, e
 	return result, nil
 }
 
-func (r *inMemoryQuoteRepository) GetRandom() (*entity.Quote, error) {
-	r.mutex.RLock()
+func (r *inMemoryQuoteRepository) GetByOwner(ctx context.Context, owner string) ([]*entity.Quote, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.rlock()
+	defer r.mutex.RUnlock()
+
+	var result []*entity.Quote
+	for _, quote := range r.quotes {
+		if quote.OwnedBy(owner) {
+			result = append(result, quote)
+		}
+	}
+	return result, nil
+}
+
+func (r *inMemoryQuoteRepository) GetRandom(ctx context.Context) (*entity.Quote, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.rlock()
 	defer r.mutex.RUnlock()
 
 	if len(r.quotes) == 0 {
@@ -68,15 +192,119 @@ func (r *inMemoryQuoteRepository) GetRandom() (*entity.Quote, error) {
 	return r.quotes[index], nil
 }
 
-func (r *inMemoryQuoteRepository) Delete(id entity.QuoteID) error {
-	r.mutex.Lock()
+func (r *inMemoryQuoteRepository) GetPage(ctx context.Context, after entity.QuoteID, limit int) ([]*entity.Quote, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.rlock()
+	defer r.mutex.RUnlock()
+
+	start := sort.Search(len(r.quotes), func(i int) bool {
+		return r.quotes[i].ID > after
+	})
+	end := min(start+limit, len(r.quotes))
+
+	result := make([]*entity.Quote, end-start)
+	copy(result, r.quotes[start:end])
+	return result, nil
+}
+
+func (r *inMemoryQuoteRepository) Snapshot(ctx context.Context) (repository.Snapshot, error) {
+	if err := ctx.Err(); err != nil {
+		return repository.Snapshot{}, err
+	}
+
+	r.rlock()
+	defer r.mutex.RUnlock()
+
+	return repository.Snapshot{
+		Revision:   r.revision,
+		MaxID:      r.lastID,
+		Count:      len(r.quotes),
+		TakenAt:    time.Now(),
+		ModifiedAt: r.modifiedAt,
+	}, nil
+}
+
+// touch records a change to the collection. Callers must hold the write lock.
+func (r *inMemoryQuoteRepository) touch() {
+	r.revision++
+	r.modifiedAt = time.Now()
+}
+
+func (r *inMemoryQuoteRepository) Delete(ctx context.Context, id entity.QuoteID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.lock()
 	defer r.mutex.Unlock()
 
 	for i, quote := range r.quotes {
 		if quote.ID == id {
 			r.quotes = append(r.quotes[:i], r.quotes[i+1:]...)
+			r.touch()
+			slog.DebugContext(ctx, "quote removed", "id", id, "revision", r.revision)
 			return nil
 		}
 	}
 	return entity.ErrQuoteNotFound
 }
+
+func (r *inMemoryQuoteRepository) ApplyBatch(ctx context.Context, ops []repository.Operation) ([]repository.OperationResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.lock()
+	defer r.mutex.Unlock()
+
+	quotes := make([]*entity.Quote, len(r.quotes))
+	copy(quotes, r.quotes)
+	lastID := r.lastID
+
+	results := make([]repository.OperationResult, len(ops))
+	failed := false
+	for i, op := range ops {
+		switch op.Kind {
+		case repository.OperationCreate:
+			quote, err := entity.NewQuoteFromDraft(lastID+1, op.Draft)
+			if err != nil {
+				results[i].Err = err
+				break
+			}
+			lastID = quote.ID
+			quotes = append(quotes, quote)
+			results[i].Quote = quote
+		case repository.OperationDelete:
+			index := slices.IndexFunc(quotes, func(q *entity.Quote) bool { return q.ID == op.ID })
+			if index < 0 {
+				results[i].Err = entity.ErrQuoteNotFound
+				break
+			}
+			if op.Precondition != nil {
+				if err := op.Precondition(quotes[index]); err != nil {
+					results[i].Err = err
+					break
+				}
+			}
+			results[i].Quote = quotes[index]
+			quotes = slices.Delete(quotes, index, index+1)
+		default:
+			results[i].Err = fmt.Errorf("unknown operation %q", op.Kind)
+		}
+		failed = failed || results[i].Err != nil
+	}
+
+	if failed {
+		slog.DebugContext(ctx, "batch aborted", "operations", len(ops))
+		return results, entity.ErrBatchAborted
+	}
+
+	r.quotes = quotes
+	r.lastID = lastID
+	r.touch()
+	slog.DebugContext(ctx, "batch stored", "operations", len(ops), "revision", r.revision)
+	return results, nil
+}
@@ -0,0 +1,214 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const instrumentationScope = "github.com/Korjick/go-http-quote"
+
+// WriterExporter writes every span as a line of JSON, for reading traces
+// locally from stdout or a file.
+type WriterExporter struct {
+	w     io.Writer
+	mutex sync.Mutex
+}
+
+func NewWriterExporter(w io.Writer) *WriterExporter {
+	return &WriterExporter{w: w}
+}
+
+type jsonSpan struct {
+	Name          string         `json:"name"`
+	TraceID       string         `json:"trace_id"`
+	SpanID        string         `json:"span_id"`
+	ParentSpanID  string         `json:"parent_span_id,omitempty"`
+	Kind          string         `json:"kind"`
+	Start         time.Time      `json:"start"`
+	End           time.Time      `json:"end"`
+	Duration      string         `json:"duration"`
+	Attributes    map[string]any `json:"attributes,omitempty"`
+	Status        string         `json:"status,omitempty"`
+	StatusMessage string         `json:"status_message,omitempty"`
+}
+
+func (e *WriterExporter) Export(_ context.Context, spans []SpanData) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, span := range spans {
+		line := jsonSpan{
+			Name:          span.Name,
+			TraceID:       span.SpanContext.TraceID.String(),
+			SpanID:        span.SpanContext.SpanID.String(),
+			Kind:          "internal",
+			Start:         span.Start,
+			End:           span.End,
+			Duration:      span.End.Sub(span.Start).String(),
+			StatusMessage: span.StatusMessage,
+		}
+		if span.Parent.IsValid() {
+			line.ParentSpanID = span.Parent.String()
+		}
+		if span.Kind == SpanKindServer {
+			line.Kind = "server"
+		}
+		if span.StatusCode == StatusError {
+			line.Status = "error"
+		}
+		if len(span.Attributes) > 0 {
+			line.Attributes = make(map[string]any, len(span.Attributes))
+			for _, attr := range span.Attributes {
+				line.Attributes[attr.Key] = attr.Value.Resolve().Any()
+			}
+		}
+		if err := encoder.Encode(line); err != nil {
+			return err
+		}
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	_, err := e.w.Write(buf.Bytes())
+	return err
+}
+
+func (e *WriterExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+// OTLPExporter posts spans to an OpenTelemetry collector with the JSON
+// encoding of OTLP/HTTP, usually at http://collector:4318/v1/traces.
+type OTLPExporter struct {
+	client      *http.Client
+	endpoint    string
+	serviceName string
+}
+
+func NewOTLPExporter(client *http.Client, endpoint, serviceName string) *OTLPExporter {
+	return &OTLPExporter{client: client, endpoint: endpoint, serviceName: serviceName}
+}
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              SpanKind       `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    StatusCode `json:"code,omitempty"`
+	Message string     `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string         `json:"key"`
+	Value map[string]any `json:"value"`
+}
+
+// otlpValue encodes an attribute value as an OTLP AnyValue, in which 64-bit
+// integers are strings.
+func otlpValue(value slog.Value) map[string]any {
+	switch value = value.Resolve(); value.Kind() {
+	case slog.KindBool:
+		return map[string]any{"boolValue": value.Bool()}
+	case slog.KindInt64:
+		return map[string]any{"intValue": strconv.FormatInt(value.Int64(), 10)}
+	case slog.KindUint64:
+		return map[string]any{"intValue": strconv.FormatUint(value.Uint64(), 10)}
+	case slog.KindFloat64:
+		return map[string]any{"doubleValue": value.Float64()}
+	default:
+		return map[string]any{"stringValue": value.String()}
+	}
+}
+
+func otlpAttributes(attrs []slog.Attr) []otlpKeyValue {
+	result := make([]otlpKeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		result = append(result, otlpKeyValue{Key: attr.Key, Value: otlpValue(attr.Value)})
+	}
+	return result
+}
+
+func (e *OTLPExporter) Export(ctx context.Context, spans []SpanData) error {
+	scope := otlpScopeSpans{Scope: otlpScope{Name: instrumentationScope}, Spans: make([]otlpSpan, 0, len(spans))}
+	for _, span := range spans {
+		s := otlpSpan{
+			TraceID:           span.SpanContext.TraceID.String(),
+			SpanID:            span.SpanContext.SpanID.String(),
+			Name:              span.Name,
+			Kind:              span.Kind,
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+			Attributes:        otlpAttributes(span.Attributes),
+			Status:            otlpStatus{Code: span.StatusCode, Message: span.StatusMessage},
+		}
+		if span.Parent.IsValid() {
+			s.ParentSpanID = span.Parent.String()
+		}
+		scope.Spans = append(scope.Spans, s)
+	}
+	body, err := json.Marshal(otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: otlpAttributes([]slog.Attr{slog.String("service.name", e.serviceName)})},
+		ScopeSpans: []otlpScopeSpans{scope},
+	}}})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("POST %s: %s", e.endpoint, resp.Status)
+	}
+	return nil
+}
+
+func (e *OTLPExporter) Shutdown(context.Context) error {
+	e.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,98 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/Korjick/go-http-quote/application/service"
+	"github.com/Korjick/go-http-quote/domain/quote/entity"
+	"github.com/Korjick/go-http-quote/domain/quote/repository"
+)
+
+// tracedQuoteRepository wraps every call of the repository in a span.
+type tracedQuoteRepository struct {
+	repo   repository.QuoteRepository
+	tracer service.Tracer
+}
+
+// TraceQuoteRepository wraps repo so that every operation is a span named
+// after it, a child of the span of the calling service method.
+func TraceQuoteRepository(repo repository.QuoteRepository, tracer service.Tracer) repository.QuoteRepository {
+	return &tracedQuoteRepository{repo: repo, tracer: tracer}
+}
+
+func (r *tracedQuoteRepository) Create(ctx context.Context, draft entity.QuoteDraft) (*entity.Quote, error) {
+	ctx, span := r.tracer.Start(ctx, "QuoteRepository.Create")
+	result, err := r.repo.Create(ctx, draft)
+	span.End(err)
+	return result, err
+}
+
+func (r *tracedQuoteRepository) CreateMany(ctx context.Context, drafts []entity.QuoteDraft) ([]*entity.Quote, error) {
+	ctx, span := r.tracer.Start(ctx, "QuoteRepository.CreateMany")
+	result, err := r.repo.CreateMany(ctx, drafts)
+	span.End(err)
+	return result, err
+}
+
+func (r *tracedQuoteRepository) GetAll(ctx context.Context) ([]*entity.Quote, error) {
+	ctx, span := r.tracer.Start(ctx, "QuoteRepository.GetAll")
+	result, err := r.repo.GetAll(ctx)
+	span.End(err)
+	return result, err
+}
+
+func (r *tracedQuoteRepository) GetByID(ctx context.Context, id entity.QuoteID) (*entity.Quote, error) {
+	ctx, span := r.tracer.Start(ctx, "QuoteRepository.GetByID")
+	result, err := r.repo.GetByID(ctx, id)
+	span.End(err)
+	return result, err
+}
+
+func (r *tracedQuoteRepository) GetByAuthor(ctx context.Context, author string) ([]*entity.Quote, error) {
+	ctx, span := r.tracer.Start(ctx, "QuoteRepository.GetByAuthor")
+	result, err := r.repo.GetByAuthor(ctx, author)
+	span.End(err)
+	return result, err
+}
+
+func (r *tracedQuoteRepository) GetByOwner(ctx context.Context, owner string) ([]*entity.Quote, error) {
+	ctx, span := r.tracer.Start(ctx, "QuoteRepository.GetByOwner")
+	result, err := r.repo.GetByOwner(ctx, owner)
+	span.End(err)
+	return result, err
+}
+
+func (r *tracedQuoteRepository) GetRandom(ctx context.Context) (*entity.Quote, error) {
+	ctx, span := r.tracer.Start(ctx, "QuoteRepository.GetRandom")
+	result, err := r.repo.GetRandom(ctx)
+	span.End(err)
+	return result, err
+}
+
+func (r *tracedQuoteRepository) GetPage(ctx context.Context, after entity.QuoteID, limit int) ([]*entity.Quote, error) {
+	ctx, span := r.tracer.Start(ctx, "QuoteRepository.GetPage")
+	result, err := r.repo.GetPage(ctx, after, limit)
+	span.End(err)
+	return result, err
+}
+
+func (r *tracedQuoteRepository) Snapshot(ctx context.Context) (repository.Snapshot, error) {
+	ctx, span := r.tracer.Start(ctx, "QuoteRepository.Snapshot")
+	result, err := r.repo.Snapshot(ctx)
+	span.End(err)
+	return result, err
+}
+
+func (r *tracedQuoteRepository) Delete(ctx context.Context, id entity.QuoteID) error {
+	ctx, span := r.tracer.Start(ctx, "QuoteRepository.Delete")
+	err := r.repo.Delete(ctx, id)
+	span.End(err)
+	return err
+}
+
+func (r *tracedQuoteRepository) ApplyBatch(ctx context.Context, ops []repository.Operation) ([]repository.OperationResult, error) {
+	ctx, span := r.tracer.Start(ctx, "QuoteRepository.ApplyBatch")
+	result, err := r.repo.ApplyBatch(ctx, ops)
+	span.End(err)
+	return result, err
+}
@@ -0,0 +1,210 @@
+package tracing
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Korjick/go-http-quote/application/service"
+)
+
+const (
+	DefaultBatchSize     = 512
+	DefaultBatchInterval = 5 * time.Second
+	maxQueuedSpans       = 2048
+)
+
+// SpanKind values are those of OTLP.
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindServer   SpanKind = 2
+)
+
+// StatusCode values are those of OTLP.
+type StatusCode int
+
+const (
+	StatusUnset StatusCode = 0
+	StatusOK    StatusCode = 1
+	StatusError StatusCode = 2
+)
+
+// SpanData is a finished span, as handed to exporters.
+type SpanData struct {
+	Name          string
+	SpanContext   SpanContext
+	Parent        SpanID
+	Kind          SpanKind
+	Start         time.Time
+	End           time.Time
+	Attributes    []slog.Attr
+	StatusCode    StatusCode
+	StatusMessage string
+}
+
+// Exporter sends finished spans to their destination.
+type Exporter interface {
+	Export(ctx context.Context, spans []SpanData) error
+	Shutdown(ctx context.Context) error
+}
+
+// Tracer starts spans and exports the sampled ones in batches from a
+// background goroutine, so that ending a span never waits for the exporter.
+// Spans that end while the queue is full are dropped. Root spans are always
+// sampled; the others follow their parent, including a remote one.
+type Tracer struct {
+	exporter Exporter
+	queue    chan SpanData
+	done     chan struct{}
+	closed   bool
+	mutex    sync.RWMutex
+}
+
+func NewTracer(exporter Exporter) *Tracer {
+	t := &Tracer{
+		exporter: exporter,
+		queue:    make(chan SpanData, maxQueuedSpans),
+		done:     make(chan struct{}),
+	}
+	go t.run(DefaultBatchSize, DefaultBatchInterval)
+	return t
+}
+
+// Start starts an internal span, a child of the current span of ctx.
+func (t *Tracer) Start(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, service.Span) {
+	return t.start(ctx, SpanContextFrom(ctx), SpanKindInternal, name, attrs)
+}
+
+// StartServer starts the span of a request received by the server,
+// continuing the trace of its traceparent header. A missing or invalid
+// header starts a new trace.
+func (t *Tracer) StartServer(ctx context.Context, traceparent, name string, attrs ...slog.Attr) (context.Context, service.Span) {
+	parent, err := ParseTraceparent(traceparent)
+	if err != nil {
+		parent = SpanContext{}
+	}
+	return t.start(ctx, parent, SpanKindServer, name, attrs)
+}
+
+func (t *Tracer) start(ctx context.Context, parent SpanContext, kind SpanKind, name string, attrs []slog.Attr) (context.Context, service.Span) {
+	sc := SpanContext{TraceID: parent.TraceID, SpanID: newSpanID(), Sampled: parent.Sampled}
+	if !parent.IsValid() {
+		sc.TraceID, sc.Sampled = newTraceID(), true
+	}
+	s := &span{
+		tracer: t,
+		data: SpanData{
+			Name:        name,
+			SpanContext: sc,
+			Parent:      parent.SpanID,
+			Kind:        kind,
+			Start:       time.Now(),
+			Attributes:  slices.Clip(attrs),
+		},
+	}
+	return ContextWithSpanContext(ctx, sc), s
+}
+
+func (t *Tracer) enqueue(data SpanData) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	if t.closed {
+		return
+	}
+	select {
+	case t.queue <- data:
+	default:
+	}
+}
+
+func (t *Tracer) run(batchSize int, interval time.Duration) {
+	defer close(t.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	batch := make([]SpanData, 0, batchSize)
+	export := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := t.exporter.Export(context.Background(), batch); err != nil {
+			slog.Error("exporting spans", "spans", len(batch), "error", err)
+		}
+		batch = make([]SpanData, 0, batchSize)
+	}
+
+	for {
+		select {
+		case data, ok := <-t.queue:
+			if !ok {
+				export()
+				return
+			}
+			batch = append(batch, data)
+			if len(batch) >= batchSize {
+				export()
+			}
+		case <-ticker.C:
+			export()
+		}
+	}
+}
+
+// Shutdown exports the spans already ended and shuts the exporter down.
+// Spans ending afterwards are dropped.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	t.mutex.Lock()
+	if !t.closed {
+		t.closed = true
+		close(t.queue)
+	}
+	t.mutex.Unlock()
+
+	select {
+	case <-t.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return t.exporter.Shutdown(ctx)
+}
+
+type span struct {
+	tracer *Tracer
+	data   SpanData
+	ended  bool
+	mutex  sync.Mutex
+}
+
+func (s *span) SetName(name string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.data.Name = name
+}
+
+func (s *span) SetAttributes(attrs ...slog.Attr) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.data.Attributes = append(s.data.Attributes, attrs...)
+}
+
+func (s *span) End(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.ended {
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	if err != nil {
+		s.data.StatusCode, s.data.StatusMessage = StatusError, err.Error()
+	}
+	if s.data.SpanContext.Sampled {
+		s.tracer.enqueue(s.data)
+	}
+}
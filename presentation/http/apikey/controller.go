@@ -0,0 +1,93 @@
+package apikey
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	utils "github.com/Korjick/go-http-quote/presentation/http"
+
+	"github.com/Korjick/go-http-quote/application/service"
+	"github.com/Korjick/go-http-quote/domain/apperror"
+	"github.com/Korjick/go-http-quote/domain/auth"
+	"github.com/Korjick/go-http-quote/presentation/http/i18n"
+)
+
+var ErrInvalidAPIKeyID = apperror.New(apperror.KindMalformed, "invalid_api_key_id", "invalid API key ID")
+
+// Controller serves the administration of API keys. Permissions are checked
+// by the service.
+type Controller struct {
+	service *service.APIKeyService
+	prefix  string
+	router  *utils.Router
+}
+
+func NewController(service *service.APIKeyService, prefix string) *Controller {
+	controller := &Controller{service: service, prefix: prefix, router: utils.NewRouter()}
+	controller.registerRoutes()
+	return controller
+}
+
+func (h *Controller) registerRoutes() {
+	h.router.HandleFunc(http.MethodGet, h.prefix, utils.Negotiated(h.listAPIKeys))
+	h.router.HandleFunc(http.MethodPost, h.prefix, utils.Negotiated(h.createAPIKey))
+	h.router.HandleFunc(http.MethodDelete, h.prefix+"/{id}", utils.Negotiated(h.revokeAPIKey))
+
+	h.router.NotFound = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		utils.WriteProblem(w, r, utils.LocalizedProblem(r, http.StatusNotFound, i18n.CodeRouteNotFound))
+	})
+	h.router.MethodNotAllowed = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		problem := utils.LocalizedProblem(r, http.StatusMethodNotAllowed, i18n.CodeMethodNotAllowed)
+		problem.Detail += fmt.Sprintf(": %s (%s)", r.Method, w.Header().Get("Allow"))
+		utils.WriteProblem(w, r, problem)
+	})
+}
+
+func (h *Controller) Routes() []string {
+	return h.router.Routes()
+}
+
+func (h *Controller) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.router.ServeHTTP(w, r)
+}
+
+func (h *Controller) listAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.service.ListAPIKeys(utils.PrincipalFrom(r))
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	utils.WriteResponse(w, r, http.StatusOK, EntitiesToDTO(keys))
+}
+
+func (h *Controller) createAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req CreateAPIKeyRequest
+	if err := utils.DecodeJSON(w, r, &req); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	key, secret, err := h.service.CreateAPIKey(utils.PrincipalFrom(r), req.Name, auth.Role(req.Role))
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	utils.WriteResponse(w, r, http.StatusCreated, CreatedAPIKeyResponse{APIKeyResponse: EntityToDTO(key), Key: secret})
+}
+
+func (h *Controller) revokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		utils.WriteError(w, r, ErrInvalidAPIKeyID)
+		return
+	}
+
+	if err := h.service.RevokeAPIKey(utils.PrincipalFrom(r), auth.APIKeyID(id)); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,49 @@
+package apikey
+
+import (
+	"encoding/xml"
+	"time"
+
+	"github.com/Korjick/go-http-quote/domain/auth"
+)
+
+type CreateAPIKeyRequest struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+type APIKeyResponse struct {
+	XMLName   xml.Name  `json:"-" xml:"api_key"`
+	ID        int64     `json:"id" xml:"id"`
+	Name      string    `json:"name" xml:"name"`
+	Role      string    `json:"role" xml:"role"`
+	Prefix    string    `json:"prefix" xml:"prefix"`
+	CreatedAt time.Time `json:"created_at" xml:"created_at"`
+	CreatedBy string    `json:"created_by" xml:"created_by"`
+}
+
+// CreatedAPIKeyResponse is the only response that carries the secret.
+type CreatedAPIKeyResponse struct {
+	XMLName xml.Name `json:"-" xml:"api_key"`
+	APIKeyResponse
+	Key string `json:"key" xml:"key"`
+}
+
+func EntityToDTO(key *auth.APIKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:        int64(key.ID),
+		Name:      key.Name,
+		Role:      string(key.Role),
+		Prefix:    key.Prefix,
+		CreatedAt: key.CreatedAt,
+		CreatedBy: key.CreatedBy,
+	}
+}
+
+func EntitiesToDTO(keys []*auth.APIKey) []APIKeyResponse {
+	dtos := make([]APIKeyResponse, len(keys))
+	for i, key := range keys {
+		dtos[i] = EntityToDTO(key)
+	}
+	return dtos
+}
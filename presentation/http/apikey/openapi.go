@@ -0,0 +1,68 @@
+package apikey
+
+import (
+	"net/http"
+	"strconv"
+
+	utils "github.com/Korjick/go-http-quote/presentation/http"
+
+	"github.com/Korjick/go-http-quote/domain/auth"
+	"github.com/Korjick/go-http-quote/presentation/http/openapi"
+)
+
+// Describe adds the routes of the controller to doc.
+func (h *Controller) Describe(doc *openapi.Document) {
+	roles := make([]string, len(auth.Roles))
+	for i, role := range auth.Roles {
+		roles[i] = string(role)
+	}
+	problem := func(status int) openapi.Response {
+		return openapi.Response{
+			Description: http.StatusText(status),
+			Content:     map[string]openapi.MediaType{utils.ProblemContentType: {Schema: doc.SchemaOf(utils.Problem{})}},
+		}
+	}
+	responses := func(status int, description string, body any, others ...int) map[string]openapi.Response {
+		response := openapi.Response{Description: description}
+		if body != nil {
+			response.Content = map[string]openapi.MediaType{"application/json": {Schema: doc.SchemaOf(body)}}
+		}
+		all := map[string]openapi.Response{strconv.Itoa(status): response}
+		for _, other := range append(others, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotAcceptable, http.StatusTooManyRequests) {
+			all[strconv.Itoa(other)] = problem(other)
+		}
+		return all
+	}
+
+	request := doc.SchemaOf(CreateAPIKeyRequest{})
+	doc.Components.Schemas["CreateAPIKeyRequest"].Properties["role"] = openapi.Enum(roles...)
+
+	add := func(method, path string, op *openapi.Operation) {
+		op.Tags = []string{"api keys"}
+		op.Security = doc.Requirements(false)
+		doc.Add(method, path, op)
+	}
+	add(http.MethodGet, h.prefix, &openapi.Operation{
+		OperationID: "listAPIKeys",
+		Summary:     "List API keys",
+		Description: "Requires the admin role.",
+		Responses:   responses(http.StatusOK, "API keys, without their secrets", []APIKeyResponse{}),
+	})
+	add(http.MethodPost, h.prefix, &openapi.Operation{
+		OperationID: "createAPIKey",
+		Summary:     "Issue an API key",
+		Description: "Requires the admin role. The secret is returned only in this response.",
+		RequestBody: &openapi.RequestBody{Required: true, Content: map[string]openapi.MediaType{"application/json": {Schema: request}}},
+		Responses: responses(http.StatusCreated, "Issued key with its secret", CreatedAPIKeyResponse{},
+			http.StatusBadRequest, http.StatusConflict, http.StatusUnsupportedMediaType, http.StatusUnprocessableEntity),
+	})
+	id := openapi.PathParameter("id", "API key ID")
+	id.Schema = &openapi.Schema{Type: "integer", Format: "int64"}
+	add(http.MethodDelete, h.prefix+"/{id}", &openapi.Operation{
+		OperationID: "revokeAPIKey",
+		Summary:     "Revoke an API key",
+		Description: "Requires the admin role.",
+		Parameters:  []openapi.Parameter{id},
+		Responses:   responses(http.StatusNoContent, "Revoked", nil, http.StatusBadRequest, http.StatusNotFound),
+	})
+}
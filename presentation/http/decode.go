@@ -0,0 +1,275 @@
+package http
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Korjick/go-http-quote/domain/apperror"
+)
+
+const MaxJSONBodySize = 1 << 20
+
+var (
+	ErrUnsupportedContentType = apperror.New(apperror.KindUnsupported, "unsupported_content_type", "request body must be application/json")
+	ErrBodyTooLarge           = apperror.New(apperror.KindTooLarge, "body_too_large", "request body is too large")
+	ErrMalformedJSON          = apperror.New(apperror.KindMalformed, "malformed_json", "request body is not valid JSON")
+	ErrTrailingData           = apperror.New(apperror.KindMalformed, "trailing_data", "unexpected data after the JSON value")
+	ErrInvalidBody            = apperror.New(apperror.KindMalformed, "invalid_body", "request body does not match the expected schema")
+	ErrUnknownField           = apperror.New(apperror.KindMalformed, "unknown_field", "unknown field")
+	ErrInvalidType            = apperror.New(apperror.KindMalformed, "invalid_type", "invalid type")
+)
+
+// FieldError is a problem with one member of a request body. Pointer is a
+// JSON Pointer (RFC 6901) to the member; it is empty for the whole body.
+type FieldError struct {
+	Pointer string
+	Err     error
+}
+
+// BodyError lists every member of a request body that does not match the
+// DTO it is decoded into. It wraps ErrInvalidBody; the problem built from it
+// reports the members individually.
+type BodyError struct {
+	Fields []FieldError
+}
+
+func (e *BodyError) Error() string {
+	pointers := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		pointers[i] = field.Pointer
+	}
+	return ErrInvalidBody.Error() + ": " + strings.Join(pointers, ", ")
+}
+
+func (e *BodyError) Unwrap() error {
+	return ErrInvalidBody
+}
+
+// DecodeJSON strictly decodes a JSON request body into v, a pointer to a
+// DTO. The body must be declared as JSON, fit into MaxJSONBodySize and hold
+// exactly one JSON value whose members all exist in the DTO with matching
+// types. Mismatches are collected into a BodyError rather than stopping at
+// the first one.
+func DecodeJSON(w http.ResponseWriter, r *http.Request, v any) error {
+	contentType := r.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || (mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json")) {
+		return fmt.Errorf("%w: got %q", ErrUnsupportedContentType, contentType)
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxJSONBodySize))
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return fmt.Errorf("%w: limit is %d bytes", ErrBodyTooLarge, maxErr.Limit)
+		}
+		return fmt.Errorf("%w: %v", ErrMalformedJSON, err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var tree any
+	if err := decoder.Decode(&tree); err != nil {
+		return malformedJSON(err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return fmt.Errorf("%w: at offset %d", ErrTrailingData, decoder.InputOffset())
+	}
+
+	var fields []FieldError
+	validate(&fields, "", tree, reflect.TypeOf(v))
+	if len(fields) > 0 {
+		return &BodyError{Fields: fields}
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return malformedJSON(err)
+	}
+	return nil
+}
+
+func malformedJSON(err error) error {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return fmt.Errorf("%w: %v at offset %d", ErrMalformedJSON, syntaxErr, syntaxErr.Offset)
+	}
+	if errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: empty body", ErrMalformedJSON)
+	}
+	return fmt.Errorf("%w: %v", ErrMalformedJSON, err)
+}
+
+var (
+	timeType            = reflect.TypeFor[time.Time]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+)
+
+// validate compares a generically decoded JSON value with the Go type it is
+// going to be decoded into, the way encoding/json would map it, except that
+// object members have to match field names exactly.
+func validate(fields *[]FieldError, pointer string, value any, t reflect.Type) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if value == nil {
+		return
+	}
+
+	mismatch := func(expected string) {
+		err := fmt.Errorf("%w: expected %s, got %s", ErrInvalidType, expected, jsonType(value))
+		*fields = append(*fields, FieldError{Pointer: pointer, Err: err})
+	}
+
+	switch {
+	case reflect.PointerTo(t).Implements(jsonUnmarshalerType):
+		return
+	case t == timeType || reflect.PointerTo(t).Implements(textUnmarshalerType):
+		if _, ok := value.(string); !ok {
+			mismatch("string")
+		}
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+	case reflect.Bool:
+		if _, ok := value.(bool); !ok {
+			mismatch("boolean")
+		}
+	case reflect.String:
+		if _, ok := value.(string); !ok {
+			mismatch("string")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number, ok := value.(json.Number)
+		n, err := strconv.ParseInt(number.String(), 10, 64)
+		if !ok || err != nil || reflect.Zero(t).OverflowInt(n) {
+			mismatch(fmt.Sprintf("integer of %d bits", t.Bits()))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		number, ok := value.(json.Number)
+		n, err := strconv.ParseUint(number.String(), 10, 64)
+		if !ok || err != nil || reflect.Zero(t).OverflowUint(n) {
+			mismatch(fmt.Sprintf("non-negative integer of %d bits", t.Bits()))
+		}
+	case reflect.Float32, reflect.Float64:
+		if _, ok := value.(json.Number); !ok {
+			mismatch("number")
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := value.([]any)
+		if !ok {
+			mismatch("array")
+			return
+		}
+		for i, item := range items {
+			validate(fields, pointer+"/"+strconv.Itoa(i), item, t.Elem())
+		}
+	case reflect.Map:
+		members, ok := value.(map[string]any)
+		if !ok {
+			mismatch("object")
+			return
+		}
+		for _, key := range sortedKeys(members) {
+			validate(fields, pointer+"/"+escapePointer(key), members[key], t.Elem())
+		}
+	case reflect.Struct:
+		members, ok := value.(map[string]any)
+		if !ok {
+			mismatch("object")
+			return
+		}
+		validateStruct(fields, pointer, members, t)
+	default:
+		mismatch(t.Kind().String())
+	}
+}
+
+func validateStruct(fields *[]FieldError, pointer string, members map[string]any, t reflect.Type) {
+	known := jsonFields(t)
+	for _, key := range sortedKeys(members) {
+		memberPointer := pointer + "/" + escapePointer(key)
+		fieldType, ok := known[key]
+		if !ok {
+			*fields = append(*fields, FieldError{Pointer: memberPointer, Err: ErrUnknownField})
+			continue
+		}
+		validate(fields, memberPointer, members[key], fieldType)
+	}
+}
+
+// jsonFields maps the JSON names of the exported fields of a struct,
+// including promoted ones, to their types.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	known := make(map[string]reflect.Type)
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			for embedded, embeddedType := range jsonFields(field.Type) {
+				if _, ok := known[embedded]; !ok {
+					known[embedded] = embeddedType
+				}
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		known[name] = field.Type
+	}
+	return known
+}
+
+func sortedKeys(members map[string]any) []string {
+	keys := make([]string, 0, len(members))
+	for key := range members {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func jsonType(value any) string {
+	switch v := value.(type) {
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if strings.ContainsAny(v.String(), ".eE") {
+			return "number"
+		}
+		return "integer"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return "null"
+	}
+}
+
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
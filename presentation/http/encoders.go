@@ -0,0 +1,360 @@
+package http
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type jsonEncoder struct{}
+
+func (jsonEncoder) ContentType() string {
+	return "application/json"
+}
+
+func (jsonEncoder) ProblemContentType() string {
+	return ProblemContentType
+}
+
+func (jsonEncoder) Encode(w io.Writer, v any) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+// xmlEncoder relies on the xml struct tags of the DTOs. Slices have no
+// element name of their own, so their items are wrapped in a <list> element.
+type xmlEncoder struct {
+	contentType string
+}
+
+func (e xmlEncoder) ContentType() string {
+	return e.contentType
+}
+
+func (xmlEncoder) ProblemContentType() string {
+	return "application/problem+xml"
+}
+
+func (xmlEncoder) Encode(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if value := reflect.ValueOf(v); value.Kind() == reflect.Slice {
+		list := xml.StartElement{Name: xml.Name{Local: "list"}}
+		if err := encoder.EncodeToken(list); err != nil {
+			return err
+		}
+		for i := range value.Len() {
+			if err := encoder.Encode(value.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		if err := encoder.EncodeToken(list.End()); err != nil {
+			return err
+		}
+	} else if err := encoder.Encode(v); err != nil {
+		return err
+	}
+
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// csvEncoder writes one row per item of a slice, or a single row for any
+// other value. Columns follow the JSON field names; nested values are
+// written as JSON.
+type csvEncoder struct{}
+
+func (csvEncoder) ContentType() string {
+	return "text/csv; charset=utf-8"
+}
+
+func (csvEncoder) Encode(w io.Writer, v any) error {
+	tree, err := toTree(v)
+	if err != nil {
+		return err
+	}
+
+	items, ok := tree.([]any)
+	if !ok {
+		items = []any{tree}
+	}
+
+	writer := csv.NewWriter(w)
+	var header []string
+	for _, item := range items {
+		obj, ok := item.(object)
+		if !ok {
+			obj = object{{key: "value", value: item}}
+		}
+
+		if header == nil {
+			header = obj.keys()
+			if err := writer.Write(header); err != nil {
+				return err
+			}
+		}
+
+		row := make([]string, len(header))
+		for i, key := range header {
+			if row[i], err = csvCell(obj.get(key)); err != nil {
+				return err
+			}
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func csvCell(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		data, err := json.Marshal(v)
+		return string(data), err
+	}
+}
+
+// yamlEncoder renders the JSON form of a value as block-style YAML, so the
+// field names and order match the JSON responses. Strings are always double
+// quoted, which keeps values such as "no" or "1.0" from changing type.
+type yamlEncoder struct {
+	contentType string
+}
+
+func (e yamlEncoder) ContentType() string {
+	return e.contentType
+}
+
+func (yamlEncoder) Encode(w io.Writer, v any) error {
+	tree, err := toTree(v)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	switch tree := tree.(type) {
+	case object:
+		if len(tree) == 0 {
+			buf.WriteString("{}\n")
+		}
+		writeYAMLObject(&buf, tree, 0)
+	case []any:
+		if len(tree) == 0 {
+			buf.WriteString("[]\n")
+		}
+		writeYAMLArray(&buf, tree, 0)
+	default:
+		buf.WriteString(yamlScalar(tree) + "\n")
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+func writeYAMLObject(buf *bytes.Buffer, obj object, indent int) {
+	for _, field := range obj {
+		buf.WriteString(strings.Repeat(" ", indent) + field.key + ":")
+		writeYAMLValue(buf, field.value, indent+2)
+	}
+}
+
+func writeYAMLArray(buf *bytes.Buffer, arr []any, indent int) {
+	for _, item := range arr {
+		buf.WriteString(strings.Repeat(" ", indent) + "-")
+		writeYAMLValue(buf, item, indent+2)
+	}
+}
+
+func writeYAMLValue(buf *bytes.Buffer, v any, indent int) {
+	switch v := v.(type) {
+	case object:
+		if len(v) == 0 {
+			buf.WriteString(" {}\n")
+			return
+		}
+		buf.WriteString("\n")
+		writeYAMLObject(buf, v, indent)
+	case []any:
+		if len(v) == 0 {
+			buf.WriteString(" []\n")
+			return
+		}
+		buf.WriteString("\n")
+		writeYAMLArray(buf, v, indent)
+	default:
+		buf.WriteString(" " + yamlScalar(v) + "\n")
+	}
+}
+
+func yamlScalar(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return strconv.Quote(v)
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return strconv.Quote(fmt.Sprint(v))
+	}
+}
+
+// textEncoder writes values implementing fmt.Stringer, one line per item for
+// slices of them, and falls back to YAML for everything else.
+type textEncoder struct{}
+
+func (textEncoder) ContentType() string {
+	return "text/plain; charset=utf-8"
+}
+
+func (textEncoder) Encode(w io.Writer, v any) error {
+	var lines []string
+	if stringer, ok := v.(fmt.Stringer); ok {
+		lines = append(lines, stringer.String())
+	} else if value := reflect.ValueOf(v); value.Kind() == reflect.Slice {
+		for i := range value.Len() {
+			stringer, ok := value.Index(i).Interface().(fmt.Stringer)
+			if !ok {
+				return yamlEncoder{}.Encode(w, v)
+			}
+			lines = append(lines, stringer.String())
+		}
+	} else {
+		return yamlEncoder{}.Encode(w, v)
+	}
+
+	for _, line := range lines {
+		if _, err := io.WriteString(w, line+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type field struct {
+	key   string
+	value any
+}
+
+// object is a decoded JSON object that keeps the order of its fields.
+type object []field
+
+func (o object) keys() []string {
+	keys := make([]string, len(o))
+	for i, field := range o {
+		keys[i] = field.key
+	}
+	return keys
+}
+
+func (o object) get(key string) any {
+	for _, field := range o {
+		if field.key == key {
+			return field.value
+		}
+	}
+	return nil
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, field := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(field.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(field.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// toTree converts v to its JSON form: objects, []any, string, json.Number,
+// bool and nil.
+func toTree(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return readTree(decoder)
+}
+
+func readTree(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return token, nil
+	}
+
+	switch delim {
+	case '{':
+		obj := object{}
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := readTree(decoder)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, field{key: key.(string), value: value})
+		}
+		_, err = decoder.Token()
+		return obj, err
+	case '[':
+		arr := []any{}
+		for decoder.More() {
+			value, err := readTree(decoder)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, value)
+		}
+		_, err = decoder.Token()
+		return arr, err
+	default:
+		return nil, errors.New("unexpected JSON delimiter " + delim.String())
+	}
+}
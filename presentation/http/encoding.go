@@ -0,0 +1,206 @@
+package http
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Korjick/go-http-quote/domain/apperror"
+)
+
+var ErrNotAcceptable = apperror.New(apperror.KindNotAcceptable, "not_acceptable", "none of the accepted media types can be produced")
+
+type Encoder interface {
+	ContentType() string
+	Encode(w io.Writer, v any) error
+}
+
+// problemEncoder is implemented by encoders whose format has a dedicated
+// media type for problem details, such as application/problem+json.
+type problemEncoder interface {
+	ProblemContentType() string
+}
+
+// EncoderRegistry selects a response encoder from the Accept header. The
+// first registered encoder is the default, used when the client accepts
+// anything. Structured syntax suffixes are honoured, so "+json" and "+xml"
+// media types such as application/vnd.quotes.v2+json select the encoders
+// registered for application/json and application/xml.
+type EncoderRegistry struct {
+	mediaTypes []string
+	encoders   map[string]Encoder
+}
+
+func NewEncoderRegistry() *EncoderRegistry {
+	return &EncoderRegistry{encoders: make(map[string]Encoder)}
+}
+
+func (reg *EncoderRegistry) Register(mediaType string, encoder Encoder) {
+	if _, ok := reg.encoders[mediaType]; !ok {
+		reg.mediaTypes = append(reg.mediaTypes, mediaType)
+	}
+	reg.encoders[mediaType] = encoder
+}
+
+func (reg *EncoderRegistry) MediaTypes() []string {
+	return slices.Clone(reg.mediaTypes)
+}
+
+func (reg *EncoderRegistry) Negotiate(accept string) (Encoder, error) {
+	if strings.TrimSpace(accept) == "" {
+		return reg.encoders[reg.mediaTypes[0]], nil
+	}
+
+	for _, mediaRange := range parseAccept(accept) {
+		if encoder, ok := reg.match(mediaRange); ok {
+			return encoder, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNotAcceptable, accept)
+}
+
+func (reg *EncoderRegistry) match(mediaRange string) (Encoder, bool) {
+	if encoder, ok := reg.encoders[mediaRange]; ok {
+		return encoder, true
+	}
+
+	mainType, subType, _ := strings.Cut(mediaRange, "/")
+	if i := strings.LastIndexByte(subType, '+'); i >= 0 {
+		if encoder, ok := reg.encoders["application/"+subType[i+1:]]; ok {
+			return encoder, true
+		}
+	}
+	if subType != "*" {
+		return nil, false
+	}
+
+	for _, mediaType := range reg.mediaTypes {
+		if mainType == "*" || strings.HasPrefix(mediaType, mainType+"/") {
+			return reg.encoders[mediaType], true
+		}
+	}
+	return nil, false
+}
+
+type acceptedRange struct {
+	mediaType string
+	quality   float64
+	// specificity orders ranges of equal quality: type/subtype before
+	// type/* before */*.
+	specificity int
+}
+
+// parseAccept returns the media ranges of an Accept header ordered by
+// preference, leaving out the ones with zero quality.
+func parseAccept(accept string) []string {
+	var ranges []acceptedRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+
+		specificity := 2
+		switch {
+		case mediaType == "*/*":
+			specificity = 0
+		case strings.HasSuffix(mediaType, "/*"):
+			specificity = 1
+		}
+		ranges = append(ranges, acceptedRange{mediaType: mediaType, quality: quality, specificity: specificity})
+	}
+
+	slices.SortStableFunc(ranges, func(a, b acceptedRange) int {
+		if c := cmp.Compare(b.quality, a.quality); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.specificity, a.specificity)
+	})
+
+	mediaTypes := make([]string, len(ranges))
+	for i, r := range ranges {
+		mediaTypes[i] = r.mediaType
+	}
+	return mediaTypes
+}
+
+var DefaultEncoders = newDefaultEncoders()
+
+func newDefaultEncoders() *EncoderRegistry {
+	reg := NewEncoderRegistry()
+	reg.Register("application/json", jsonEncoder{})
+	reg.Register("application/xml", xmlEncoder{contentType: "application/xml"})
+	reg.Register("application/yaml", yamlEncoder{contentType: "application/yaml"})
+	reg.Register("application/x-yaml", yamlEncoder{contentType: "application/x-yaml"})
+	reg.Register("text/plain", textEncoder{})
+	reg.Register("text/csv", csvEncoder{})
+	reg.Register("text/xml", xmlEncoder{contentType: "text/xml; charset=utf-8"})
+	reg.Register("text/yaml", yamlEncoder{contentType: "text/yaml; charset=utf-8"})
+	return reg
+}
+
+// WriteResponse encodes data in the format negotiated from the Accept header
+// of r. If no registered format is acceptable, a 406 problem is written
+// instead.
+func WriteResponse(w http.ResponseWriter, r *http.Request, statusCode int, data any) {
+	encoder, err := DefaultEncoders.Negotiate(r.Header.Get("Accept"))
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", encoder.ContentType())
+	AddVary(w.Header(), "Accept")
+	w.WriteHeader(statusCode)
+	if err := encoder.Encode(w, data); err != nil {
+		slog.ErrorContext(r.Context(), "encoding response", "content_type", encoder.ContentType(), "error", err)
+	}
+}
+
+// AcceptsHTML reports whether the most preferred media range of the Accept
+// header of r is an HTML one, as sent by browsers.
+func AcceptsHTML(r *http.Request) bool {
+	ranges := parseAccept(r.Header.Get("Accept"))
+	return len(ranges) > 0 && (ranges[0] == "text/html" || ranges[0] == "application/xhtml+xml")
+}
+
+// Negotiated rejects requests whose Accept header allows none of the default
+// encoders before they reach next, so that a 406 never follows a change that
+// has already been made.
+func Negotiated(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, err := DefaultEncoders.Negotiate(r.Header.Get("Accept")); err != nil {
+			WriteError(w, r, err)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// AddVary adds value to the Vary header unless it is already listed.
+func AddVary(header http.Header, value string) {
+	for _, line := range header.Values("Vary") {
+		for _, name := range strings.Split(line, ",") {
+			if strings.EqualFold(strings.TrimSpace(name), value) {
+				return
+			}
+		}
+	}
+	header.Add("Vary", value)
+}
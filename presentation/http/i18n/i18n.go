@@ -0,0 +1,126 @@
+package i18n
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Korjick/go-http-quote/domain/apperror"
+)
+
+type Language string
+
+const (
+	English Language = "en"
+	Russian Language = "ru"
+
+	DefaultLanguage = English
+)
+
+const (
+	CodeInternalError    = "internal_error"
+	CodeRouteNotFound    = "route_not_found"
+	CodeMethodNotAllowed = "method_not_allowed"
+
+	// Texts of the quote feeds.
+	CodeFeedTitle       = "feed_title"
+	CodeFeedAuthorTitle = "feed_author_title"
+	CodeFeedDescription = "feed_description"
+)
+
+var supported = []Language{English, Russian}
+
+// Negotiate picks the supported language with the highest weight in an
+// Accept-Language header. Region subtags are ignored, so "ru-RU" selects
+// Russian.
+func Negotiate(acceptLanguage string) Language {
+	type weighted struct {
+		tag    string
+		weight float64
+	}
+
+	var ranges []weighted
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" {
+			continue
+		}
+
+		weight := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			weight = parsed
+		}
+		if weight > 0 {
+			ranges = append(ranges, weighted{tag: strings.ToLower(tag), weight: weight})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].weight > ranges[j].weight
+	})
+
+	for _, r := range ranges {
+		if r.tag == "*" {
+			return DefaultLanguage
+		}
+		primary, _, _ := strings.Cut(r.tag, "-")
+		for _, lang := range supported {
+			if primary == string(lang) {
+				return lang
+			}
+		}
+	}
+	return DefaultLanguage
+}
+
+func FromRequest(r *http.Request) Language {
+	return Negotiate(r.Header.Get("Accept-Language"))
+}
+
+func (l Language) Message(code string) (string, bool) {
+	translations, ok := messages[code]
+	if !ok {
+		return "", false
+	}
+	if message, ok := translations[l]; ok {
+		return message, true
+	}
+	message, ok := translations[DefaultLanguage]
+	return message, ok
+}
+
+// Localize returns the stable code of err and its message in language l.
+// Details added by wrapping ("base message: details") are kept as they are.
+// Context wrapped around the error instead, as by fmt.Errorf("ctx: %w", err),
+// is dropped with any details, since it comes from the code rather than the
+// client; errors meant for clients have to be wrapped with the apperror
+// first. Errors outside the catalog are reported as internal errors.
+func (l Language) Localize(err error) (string, string) {
+	appErr, ok := apperror.As(err)
+	if !ok || appErr.Kind() == apperror.KindInternal {
+		message, _ := l.Message(CodeInternalError)
+		return CodeInternalError, message
+	}
+
+	message, ok := l.Message(appErr.Code())
+	if !ok {
+		return appErr.Code(), err.Error()
+	}
+	if details, ok := strings.CutPrefix(err.Error(), appErr.Error()); ok {
+		message += details
+	}
+	return appErr.Code(), message
+}
+
+func (l Language) StatusText(status int) string {
+	if texts, ok := statusTexts[status]; ok {
+		if text, ok := texts[l]; ok {
+			return text
+		}
+	}
+	return http.StatusText(status)
+}
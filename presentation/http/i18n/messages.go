@@ -0,0 +1,233 @@
+package i18n
+
+import "net/http"
+
+var messages = map[string]map[Language]string{
+	CodeInternalError: {
+		English: "internal server error",
+		Russian: "внутренняя ошибка сервера",
+	},
+	CodeRouteNotFound: {
+		English: "no route matches the request path",
+		Russian: "запрошенный путь не найден",
+	},
+	CodeMethodNotAllowed: {
+		English: "method is not allowed for this path",
+		Russian: "метод не поддерживается для этого пути",
+	},
+	CodeFeedTitle: {
+		English: "Quotes",
+		Russian: "Цитаты",
+	},
+	CodeFeedAuthorTitle: {
+		English: "Quotes by %s",
+		Russian: "Цитаты автора %s",
+	},
+	CodeFeedDescription: {
+		English: "Newly added quotes",
+		Russian: "Новые цитаты",
+	},
+	"empty_author": {
+		English: "author cannot be empty",
+		Russian: "автор не может быть пустым",
+	},
+	"empty_text": {
+		English: "quote text cannot be empty",
+		Russian: "текст цитаты не может быть пустым",
+	},
+	"quote_not_found": {
+		English: "quote not found",
+		Russian: "цитата не найдена",
+	},
+	"batch_aborted": {
+		English: "batch aborted, no operations were applied",
+		Russian: "пакет отменен, ни одна операция не применена",
+	},
+	"invalid_import_mode": {
+		English: "invalid import mode",
+		Russian: "недопустимый режим импорта",
+	},
+	"empty_batch": {
+		English: "batch must contain at least one operation",
+		Russian: "пакет должен содержать хотя бы одну операцию",
+	},
+	"batch_too_large": {
+		English: "batch contains too many operations",
+		Russian: "пакет содержит слишком много операций",
+	},
+	"snapshot_changed": {
+		English: "quotes were deleted during the export, retry it",
+		Russian: "во время экспорта были удалены цитаты, повторите его",
+	},
+	"unknown_operation": {
+		English: "unknown batch operation",
+		Russian: "неизвестная пакетная операция",
+	},
+	"unsupported_import_format": {
+		English: "unsupported import format",
+		Russian: "неподдерживаемый формат импорта",
+	},
+	"unsupported_export_format": {
+		English: "unsupported export format",
+		Russian: "неподдерживаемый формат экспорта",
+	},
+	"missing_column": {
+		English: "missing required column",
+		Russian: "отсутствует обязательная колонка",
+	},
+	"not_an_array": {
+		English: "expected JSON array",
+		Russian: "ожидается JSON-массив",
+	},
+	"malformed_record": {
+		English: "malformed record",
+		Russian: "некорректная запись",
+	},
+	"fortune_too_large": {
+		English: "fortune file exceeds 4 GiB",
+		Russian: "файл fortune превышает 4 ГиБ",
+	},
+	"malformed_json": {
+		English: "request body is not valid JSON",
+		Russian: "тело запроса не является корректным JSON",
+	},
+	"invalid_quote_id": {
+		English: "invalid quote ID",
+		Russian: "некорректный идентификатор цитаты",
+	},
+	"idempotency_key_too_long": {
+		English: "Idempotency-Key is too long",
+		Russian: "слишком длинный Idempotency-Key",
+	},
+	"idempotency_key_reused": {
+		English: "Idempotency-Key was already used with a different request",
+		Russian: "Idempotency-Key уже использован с другим запросом",
+	},
+	"idempotency_key_anonymous": {
+		English: "Idempotency-Key requires authentication",
+		Russian: "Idempotency-Key требует аутентификации",
+	},
+	"key_set_unavailable": {
+		English: "token signing keys are unavailable, retry later",
+		Russian: "ключи проверки токенов недоступны, повторите запрос позже",
+	},
+	"unreadable_body": {
+		English: "request body could not be read",
+		Russian: "не удалось прочитать тело запроса",
+	},
+	"not_acceptable": {
+		English: "none of the accepted media types can be produced",
+		Russian: "ни один из допустимых форматов ответа не поддерживается",
+	},
+	"unsupported_api_version": {
+		English: "unsupported API version",
+		Russian: "неподдерживаемая версия API",
+	},
+	"unknown_card_theme": {
+		English: "unknown card theme",
+		Russian: "неизвестная тема карточки",
+	},
+	"unknown_card_size": {
+		English: "unknown card size",
+		Russian: "неизвестный размер карточки",
+	},
+	"unsupported_content_type": {
+		English: "request body must be application/json",
+		Russian: "тело запроса должно быть в формате application/json",
+	},
+	"body_too_large": {
+		English: "request body is too large",
+		Russian: "слишком большое тело запроса",
+	},
+	"trailing_data": {
+		English: "unexpected data after the JSON value",
+		Russian: "лишние данные после JSON-значения",
+	},
+	"invalid_body": {
+		English: "request body does not match the expected schema",
+		Russian: "тело запроса не соответствует схеме",
+	},
+	"unknown_field": {
+		English: "unknown field",
+		Russian: "неизвестное поле",
+	},
+	"invalid_type": {
+		English: "invalid type",
+		Russian: "неверный тип",
+	},
+	"unauthenticated": {
+		English: "authentication required",
+		Russian: "требуется аутентификация",
+	},
+	"invalid_api_key": {
+		English: "invalid API key",
+		Russian: "недействительный API-ключ",
+	},
+	"invalid_token": {
+		English: "invalid bearer token",
+		Russian: "недействительный токен доступа",
+	},
+	"forbidden": {
+		English: "not allowed",
+		Russian: "недостаточно прав",
+	},
+	"unknown_role": {
+		English: "unknown role",
+		Russian: "неизвестная роль",
+	},
+	"empty_api_key_name": {
+		English: "API key name cannot be empty",
+		Russian: "имя API-ключа не может быть пустым",
+	},
+	"api_key_not_found": {
+		English: "API key not found",
+		Russian: "API-ключ не найден",
+	},
+	"api_key_name_exists": {
+		English: "API key name is already taken",
+		Russian: "API-ключ с таким именем уже существует",
+	},
+	"api_key_in_use": {
+		English: "API key is already registered",
+		Russian: "такой API-ключ уже зарегистрирован",
+	},
+	"api_key_too_short": {
+		English: "API key is too short",
+		Russian: "API-ключ слишком короткий",
+	},
+	"rate_limited": {
+		English: "too many requests, retry later",
+		Russian: "слишком много запросов, повторите позже",
+	},
+	"timeout": {
+		English: "the request took too long, retry later",
+		Russian: "запрос выполнялся слишком долго, повторите позже",
+	},
+	"request_canceled": {
+		English: "the request was canceled by the client",
+		Russian: "запрос отменен клиентом",
+	},
+	"invalid_api_key_id": {
+		English: "invalid API key ID",
+		Russian: "некорректный идентификатор API-ключа",
+	},
+}
+
+var statusTexts = map[int]map[Language]string{
+	http.StatusBadRequest:            {Russian: "Некорректный запрос"},
+	http.StatusUnauthorized:          {Russian: "Требуется аутентификация"},
+	http.StatusForbidden:             {Russian: "Доступ запрещен"},
+	http.StatusNotFound:              {Russian: "Не найдено"},
+	http.StatusMethodNotAllowed:      {Russian: "Метод не поддерживается"},
+	http.StatusNotAcceptable:         {Russian: "Неприемлемый формат ответа"},
+	http.StatusConflict:              {Russian: "Конфликт"},
+	http.StatusRequestEntityTooLarge: {Russian: "Слишком большой запрос"},
+	http.StatusUnsupportedMediaType:  {Russian: "Неподдерживаемый тип данных"},
+	http.StatusUnprocessableEntity:   {Russian: "Ошибка валидации"},
+	http.StatusTooManyRequests:       {Russian: "Слишком много запросов"},
+	http.StatusInternalServerError:   {Russian: "Внутренняя ошибка сервера"},
+	http.StatusServiceUnavailable:    {Russian: "Сервис недоступен"},
+	// Not registered, but used by nginx and this service for requests the
+	// client abandoned.
+	499: {English: "Client Closed Request", Russian: "Клиент закрыл запрос"},
+}
@@ -0,0 +1,90 @@
+package http
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the request ID, which
+// NewLogHandler adds to every record logged with that context.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFrom returns the ID set by the access log middleware, or "".
+func RequestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+type routeKey struct{}
+
+// routeRecord is filled in by the Router deep down the handler chain,
+// where the request is no longer the one the outer middleware holds.
+type routeRecord struct {
+	pattern string
+	request *http.Request
+}
+
+func (rec *routeRecord) get() string {
+	if rec.pattern == "" {
+		return rec.request.Pattern
+	}
+	return rec.pattern
+}
+
+// TrackRoute returns a shallow copy of r and a function reporting the
+// pattern of the route the Router eventually dispatched it to, or the
+// pattern of the ServeMux when no Router was involved. Middleware nested
+// in one that already tracks the route shares its record; the ServeMux
+// pattern is read from the request of the innermost one, which has to pass
+// it on to the ServeMux unchanged.
+func TrackRoute(r *http.Request) (*http.Request, func() string) {
+	if rec, ok := r.Context().Value(routeKey{}).(*routeRecord); ok {
+		rec.request = r
+		return r, rec.get
+	}
+	rec := &routeRecord{}
+	r = r.WithContext(context.WithValue(r.Context(), routeKey{}, rec))
+	rec.request = r
+	return r, rec.get
+}
+
+func recordRoute(r *http.Request, pattern string) {
+	if rec, ok := r.Context().Value(routeKey{}).(*routeRecord); ok {
+		rec.pattern = pattern
+	}
+}
+
+// logHandler adds the request ID of the context to the records.
+type logHandler struct {
+	slog.Handler
+}
+
+// NewLogHandler wraps h so that records logged with the context of a
+// request carry its ID as the "request_id" attribute, which lets the
+// service and repository log without knowing about HTTP.
+func NewLogHandler(h slog.Handler) slog.Handler {
+	return logHandler{h}
+}
+
+func (h logHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestIDFrom(ctx); id != "" {
+		record = record.Clone()
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return logHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h logHandler) WithGroup(name string) slog.Handler {
+	return logHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"time"
+
+	utils "github.com/Korjick/go-http-quote/presentation/http"
+)
+
+const maxRequestIDLength = 128
+
+// AccessLog logs one record per request: method, route pattern, status,
+// latency, response size and client address. It also gives every request an
+// ID, taken from X-Request-ID when the client or a proxy sent a sane one and
+// generated otherwise, echoed in the response header and put in the context
+// for the logs of the layers below and for problem details. It should wrap
+// everything else, so that rejected requests are logged too.
+type AccessLog struct {
+	logger         *slog.Logger
+	trustedProxies []netip.Prefix
+}
+
+func NewAccessLog(logger *slog.Logger, trustedProxies []netip.Prefix) *AccessLog {
+	return &AccessLog{logger: logger, trustedProxies: trustedProxies}
+}
+
+func (m *AccessLog) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		id := r.Header.Get(utils.RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(utils.RequestIDHeader, id)
+		r = r.WithContext(utils.WithRequestID(r.Context(), id))
+		r, route := utils.TrackRoute(r)
+
+		counter := &countingWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(counter, r)
+
+		m.logger.LogAttrs(r.Context(), slog.LevelInfo, "request",
+			slog.String("method", r.Method),
+			slog.String("route", route()),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", counter.status),
+			slog.Duration("latency", time.Since(start)),
+			slog.Int64("bytes", counter.bytes),
+			slog.String("client", utils.ClientIP(r, m.trustedProxies).String()),
+		)
+	})
+}
+
+// validRequestID accepts IDs of printable ASCII without spaces or quotes, so
+// that a client cannot forge log lines or headers through it.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if c := id[i]; c <= ' ' || c > '~' || c == '"' || c == '\\' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+type countingWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	bytes       int64
+}
+
+func (w *countingWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *countingWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+func (w *countingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	utils "github.com/Korjick/go-http-quote/presentation/http"
+
+	"github.com/Korjick/go-http-quote/application/service"
+	"github.com/Korjick/go-http-quote/domain/auth"
+	"github.com/Korjick/go-http-quote/presentation/http/openapi"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+// Authenticator turns the credentials of a request into a principal. It
+// reports ok = false when the request carries no credentials of its kind,
+// and an error when it does but they are not valid.
+type Authenticator interface {
+	Authenticate(r *http.Request) (principal auth.Principal, ok bool, err error)
+	// Challenge is the WWW-Authenticate challenge of the scheme.
+	Challenge() string
+	// SecurityScheme describes the scheme for the OpenAPI document.
+	SecurityScheme() (name string, scheme openapi.SecurityScheme)
+}
+
+// Authentication attaches the principal of the request credentials to the
+// request, for handlers to read with utils.PrincipalFrom. Requests without
+// credentials proceed as auth.Anonymous; what they may do is up to the
+// application layer. Every 401 response lists the supported schemes in
+// WWW-Authenticate.
+type Authentication struct {
+	authenticators []Authenticator
+	challenges     string
+}
+
+func NewAuthentication(authenticators ...Authenticator) *Authentication {
+	challenges := make([]string, len(authenticators))
+	for i, authenticator := range authenticators {
+		challenges[i] = authenticator.Challenge()
+	}
+	return &Authentication{authenticators: authenticators, challenges: strings.Join(challenges, ", ")}
+}
+
+func (m *Authentication) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w = &challengeWriter{ResponseWriter: w, challenges: m.challenges}
+
+		for _, authenticator := range m.authenticators {
+			principal, ok, err := authenticator.Authenticate(r)
+			if err != nil {
+				utils.WriteError(w, r, err)
+				return
+			}
+			if ok {
+				r = utils.WithPrincipal(r, principal)
+				break
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Describe adds the security schemes of the authenticators to doc.
+func (m *Authentication) Describe(doc *openapi.Document) {
+	for _, authenticator := range m.authenticators {
+		name, scheme := authenticator.SecurityScheme()
+		doc.Components.SecuritySchemes[name] = scheme
+	}
+}
+
+type challengeWriter struct {
+	http.ResponseWriter
+	challenges string
+}
+
+func (w *challengeWriter) WriteHeader(status int) {
+	if status == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+		w.Header().Set("WWW-Authenticate", w.challenges)
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *challengeWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// APIKeyAuthenticator authenticates requests by the API key in the
+// X-API-Key header.
+type APIKeyAuthenticator struct {
+	service *service.APIKeyService
+}
+
+func NewAPIKeyAuthenticator(service *service.APIKeyService) *APIKeyAuthenticator {
+	return &APIKeyAuthenticator{service: service}
+}
+
+func (a *APIKeyAuthenticator) Authenticate(r *http.Request) (auth.Principal, bool, error) {
+	secret := r.Header.Get(APIKeyHeader)
+	if secret == "" {
+		return auth.Anonymous, false, nil
+	}
+	principal, err := a.service.Authenticate(secret)
+	return principal, err == nil, err
+}
+
+func (a *APIKeyAuthenticator) Challenge() string {
+	return `APIKey header="` + APIKeyHeader + `"`
+}
+
+func (a *APIKeyAuthenticator) SecurityScheme() (string, openapi.SecurityScheme) {
+	return "apiKey", openapi.SecurityScheme{
+		Type:        "apiKey",
+		Description: "API key issued through /admin/api-keys",
+		Name:        APIKeyHeader,
+		In:          "header",
+	}
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Korjick/go-http-quote/domain/auth"
+	"github.com/Korjick/go-http-quote/presentation/http/openapi"
+)
+
+// TokenVerifier checks a bearer token and returns its claims. ctx is the
+// context of the request carrying the token.
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (map[string]any, error)
+}
+
+// RoleMapping derives the role of a token from one of its claims.
+type RoleMapping struct {
+	// Claim names the claim with the groups or roles of the subject; dots
+	// separate the names of nested objects, as in "realm_access.roles". The
+	// claim may be a string array or a space separated string like "scope".
+	Claim string
+	// Roles maps claim values to roles. When it is nil, the values are taken
+	// as role names.
+	Roles map[string]auth.Role
+}
+
+// Role returns the highest role any value of the claim maps to, and
+// auth.RoleReader if none does.
+func (m RoleMapping) Role(claims map[string]any) auth.Role {
+	var value any = claims
+	for _, name := range strings.Split(m.Claim, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return auth.RoleReader
+		}
+		value = object[name]
+	}
+
+	var values []string
+	switch v := value.(type) {
+	case string:
+		values = strings.Fields(v)
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+
+	role := auth.RoleReader
+	for _, value := range values {
+		mapped, ok := m.Roles[value]
+		if m.Roles == nil {
+			mapped, ok = auth.Role(value), slices.Contains(auth.Roles, auth.Role(value))
+		}
+		if ok && mapped.Includes(role) {
+			role = mapped
+		}
+	}
+	return role
+}
+
+// BearerSubjectPrefix is prepended to the subject of a token, so it cannot
+// collide with the subjects of API keys ("api-key:<id>") or the system.
+const BearerSubjectPrefix = "jwt:"
+
+// BearerAuthenticator authenticates requests by a JWT in the Authorization
+// header. The principal is the subject of the token, with BearerSubjectPrefix,
+// and the role its claims map to.
+type BearerAuthenticator struct {
+	verifier TokenVerifier
+	roles    RoleMapping
+}
+
+func NewBearerAuthenticator(verifier TokenVerifier, roles RoleMapping) *BearerAuthenticator {
+	return &BearerAuthenticator{verifier: verifier, roles: roles}
+}
+
+func (a *BearerAuthenticator) Authenticate(r *http.Request) (auth.Principal, bool, error) {
+	scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return auth.Anonymous, false, nil
+	}
+	claims, err := a.verifier.Verify(r.Context(), strings.TrimSpace(token))
+	if err != nil {
+		return auth.Anonymous, false, err
+	}
+	subject, _ := claims["sub"].(string)
+	return auth.Principal{Subject: BearerSubjectPrefix + subject, Role: a.roles.Role(claims)}, true, nil
+}
+
+func (a *BearerAuthenticator) Challenge() string {
+	return `Bearer realm="quotes"`
+}
+
+func (a *BearerAuthenticator) SecurityScheme() (string, openapi.SecurityScheme) {
+	return "bearer", openapi.SecurityScheme{
+		Type:         "http",
+		Description:  "JWT issued by the single sign-on provider",
+		Scheme:       "bearer",
+		BearerFormat: "JWT",
+	}
+}
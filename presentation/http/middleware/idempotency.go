@@ -0,0 +1,261 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	utils "github.com/Korjick/go-http-quote/presentation/http"
+
+	"github.com/Korjick/go-http-quote/domain/apperror"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	DefaultIdempotencyTTL    = 24 * time.Hour
+	maxIdempotencyKeyLength  = 255
+	// MaxIdempotentBodySize bounds the bodies of requests with an
+	// Idempotency-Key, which are read whole to be compared with retries.
+	MaxIdempotentBodySize = 8 << 20
+)
+
+var (
+	ErrIdempotencyKeyTooLong = apperror.New(apperror.KindMalformed, "idempotency_key_too_long", "Idempotency-Key is too long")
+	ErrIdempotencyKeyReused  = apperror.New(apperror.KindInvalid, "idempotency_key_reused", "Idempotency-Key was already used with a different request")
+	ErrUnreadableBody        = apperror.New(apperror.KindMalformed, "unreadable_body", "request body could not be read")
+	// ErrIdempotencyKeyAnonymous refuses keys from callers without a
+	// principal, who cannot be told apart and would share one key space.
+	ErrIdempotencyKeyAnonymous = apperror.New(apperror.KindUnauthenticated, "idempotency_key_anonymous", "Idempotency-Key requires authentication")
+)
+
+type idempotentResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+type idempotencyEntry struct {
+	fingerprint [sha256.Size]byte
+	done        chan struct{}
+	response    *idempotentResponse
+	expiresAt   time.Time
+}
+
+// Idempotency stores the first response to a mutating request carrying an
+// Idempotency-Key header and replays it for retries with the same key within
+// the TTL. Retries that arrive while the first request is still running wait
+// for it to finish. Server errors and requests canceled by the client or a
+// deadline are not stored, so such requests can be retried for real.
+//
+// Keys are scoped to the authenticated principal, so it has to run after
+// Authentication, and keys from anonymous callers are refused. Only the
+// headers set by the wrapped handler are stored and replayed; headers set
+// by outer middleware, such as X-Request-ID and RateLimit-*, describe the
+// current request and are left as they are.
+type Idempotency struct {
+	ttl       time.Duration
+	entries   map[string]*idempotencyEntry
+	lastSweep time.Time
+	mutex     sync.Mutex
+}
+
+func NewIdempotency(ttl time.Duration) *Idempotency {
+	return &Idempotency{
+		ttl:     ttl,
+		entries: make(map[string]*idempotencyEntry),
+	}
+}
+
+func (m *Idempotency) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" || !isMutating(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			utils.WriteError(w, r, ErrIdempotencyKeyTooLong)
+			return
+		}
+		principal := utils.PrincipalFrom(r)
+		if !principal.Authenticated() {
+			utils.WriteError(w, r, ErrIdempotencyKeyAnonymous)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxIdempotentBodySize))
+		if err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				utils.WriteError(w, r, fmt.Errorf("%w: limit is %d bytes with %s", utils.ErrBodyTooLarge, maxErr.Limit, IdempotencyKeyHeader))
+				return
+			}
+			utils.WriteError(w, r, ErrUnreadableBody)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		fingerprint := requestFingerprint(r, body)
+		// Keys are chosen by clients, so they are only unique per caller.
+		key = principal.Subject + "\x00" + key
+
+		for {
+			entry, owner := m.acquire(key, fingerprint)
+			if owner {
+				m.execute(w, r, next, key, entry)
+				return
+			}
+
+			if entry.fingerprint != fingerprint {
+				utils.WriteError(w, r, ErrIdempotencyKeyReused)
+				return
+			}
+
+			select {
+			case <-entry.done:
+			case <-r.Context().Done():
+				return
+			}
+
+			if entry.response != nil {
+				replay(w, entry.response)
+				return
+			}
+		}
+	})
+}
+
+func (m *Idempotency) acquire(key string, fingerprint [sha256.Size]byte) (*idempotencyEntry, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := time.Now()
+	m.sweep(now)
+
+	if entry, ok := m.entries[key]; ok && (entry.response == nil || now.Before(entry.expiresAt)) {
+		return entry, false
+	}
+
+	entry := &idempotencyEntry{fingerprint: fingerprint, done: make(chan struct{})}
+	m.entries[key] = entry
+	return entry, true
+}
+
+func (m *Idempotency) execute(w http.ResponseWriter, r *http.Request, next http.Handler, key string, entry *idempotencyEntry) {
+	recorder := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+	before := w.Header().Clone()
+	defer func() {
+		p := recover()
+		// A request cut short by its client or deadline did not
+		// necessarily do its work, so it is left to be retried.
+		completed := p == nil && r.Context().Err() == nil && recorder.status != utils.StatusClientClosedRequest
+		m.finish(key, entry, handlerHeader(before, w.Header()), recorder, completed)
+		if p != nil {
+			panic(p)
+		}
+	}()
+
+	next.ServeHTTP(recorder, r)
+}
+
+func (m *Idempotency) finish(key string, entry *idempotencyEntry, header http.Header, recorder *recordingWriter, completed bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if completed && recorder.status < http.StatusInternalServerError {
+		entry.response = &idempotentResponse{
+			status: recorder.status,
+			header: header,
+			body:   recorder.body.Bytes(),
+		}
+		entry.expiresAt = time.Now().Add(m.ttl)
+	} else {
+		delete(m.entries, key)
+	}
+	close(entry.done)
+}
+
+func (m *Idempotency) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < m.ttl {
+		return
+	}
+	m.lastSweep = now
+
+	for key, entry := range m.entries {
+		if entry.response != nil && !now.Before(entry.expiresAt) {
+			delete(m.entries, key)
+		}
+	}
+}
+
+func replay(w http.ResponseWriter, response *idempotentResponse) {
+	for name, values := range response.header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(response.status)
+	_, _ = w.Write(response.body)
+}
+
+// handlerHeader returns the headers in after that were added or changed
+// since before was taken.
+func handlerHeader(before, after http.Header) http.Header {
+	header := make(http.Header)
+	for name, values := range after {
+		if !slices.Equal(before[name], values) {
+			header[name] = slices.Clone(values)
+		}
+	}
+	return header
+}
+
+func requestFingerprint(r *http.Request, body []byte) [sha256.Size]byte {
+	hash := sha256.New()
+	_, _ = io.WriteString(hash, r.Method+" "+r.URL.RequestURI()+"\n")
+	_, _ = io.WriteString(hash, r.Header.Get("Accept")+"\n")
+	_, _ = hash.Write(body)
+
+	var fingerprint [sha256.Size]byte
+	copy(fingerprint[:], hash.Sum(nil))
+	return fingerprint
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+type recordingWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (w *recordingWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
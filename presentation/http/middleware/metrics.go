@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	utils "github.com/Korjick/go-http-quote/presentation/http"
+)
+
+// RequestObserver records the outcome of every request, for instance as
+// Prometheus metrics. Route is the pattern the request was dispatched to,
+// so that the number of series does not grow with the IDs in paths.
+type RequestObserver interface {
+	ObserveRequest(method, route string, status int, duration time.Duration)
+}
+
+// Metrics reports every request to an observer. Requests that matched no
+// route are reported with the route "unmatched", and methods outside the
+// standard ones as "OTHER", since clients choose them freely.
+type Metrics struct {
+	observer RequestObserver
+}
+
+func NewMetrics(observer RequestObserver) *Metrics {
+	return &Metrics{observer: observer}
+}
+
+func (m *Metrics) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		r, route := utils.TrackRoute(r)
+		counter := &countingWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(counter, r)
+
+		pattern := route()
+		if pattern == "" {
+			pattern = "unmatched"
+		}
+		m.observer.ObserveRequest(standardMethod(r.Method), pattern, counter.status, time.Since(start))
+	})
+}
+
+func standardMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	default:
+		return "OTHER"
+	}
+}
@@ -0,0 +1,274 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"time"
+
+	utils "github.com/Korjick/go-http-quote/presentation/http"
+
+	"github.com/Korjick/go-http-quote/domain/apperror"
+)
+
+const (
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+	RateLimitPolicyHeader    = "RateLimit-Policy"
+)
+
+var ErrRateLimited = apperror.New(apperror.KindTooManyRequests, "rate_limited", "too many requests, retry later")
+
+var (
+	DefaultReadLimit  = RateLimit{Requests: 120, Per: time.Minute}
+	DefaultWriteLimit = RateLimit{Requests: 30, Per: time.Minute}
+	// DefaultFailureLimit bounds the rejected credentials an address may
+	// send, which keeps API keys and tokens from being guessed.
+	DefaultFailureLimit = RateLimit{Requests: 10, Per: time.Minute}
+)
+
+// RateLimit is a token bucket of Requests tokens refilled evenly over Per,
+// so clients may burst up to Requests and then sustain Requests per Per.
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
+}
+
+func (l RateLimit) rate() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+// policy is the RateLimit-Policy value of the limit.
+func (l RateLimit) policy() string {
+	return strconv.Itoa(l.Requests) + ";w=" + strconv.Itoa(int(l.Per.Seconds()))
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// Budget is the read and write limits of a group of routes.
+type Budget struct {
+	Read  RateLimit
+	Write RateLimit
+}
+
+type RateLimiterOption func(*RateLimiter)
+
+// WithGroupBudget gives the routes of group limits of their own instead of
+// the default ones.
+func WithGroupBudget(group string, budget Budget) RateLimiterOption {
+	return func(m *RateLimiter) {
+		m.groups[group] = budget
+	}
+}
+
+// WithFailureLimit replaces DefaultFailureLimit.
+func WithFailureLimit(limit RateLimit) RateLimiterOption {
+	return func(m *RateLimiter) {
+		m.failures = limit
+	}
+}
+
+// RateLimiter throttles every client with two token buckets per group of
+// routes, one for reads and one for writes, so a scraper cannot starve the
+// writers of its own budget and the other way round, and hammering one
+// group leaves the budget of the others alone. Clients are told apart by
+// the principal when authenticated, so Wrap and WrapGroup have to run after
+// Authentication, and by IP address otherwise. The address is taken from
+// X-Forwarded-For only when the request comes from a trusted proxy.
+//
+// Requests whose credentials are rejected never reach those buckets, so
+// WrapCredentials, which runs before Authentication, counts them against a
+// bucket of the address they came from.
+type RateLimiter struct {
+	budget         Budget
+	groups         map[string]Budget
+	failures       RateLimit
+	trustedProxies []netip.Prefix
+	buckets        map[string]*bucket
+	lastSweep      time.Time
+	mutex          sync.Mutex
+}
+
+func NewRateLimiter(read, write RateLimit, trustedProxies []netip.Prefix, opts ...RateLimiterOption) *RateLimiter {
+	m := &RateLimiter{
+		budget:         Budget{Read: read, Write: write},
+		groups:         make(map[string]Budget),
+		failures:       DefaultFailureLimit,
+		trustedProxies: trustedProxies,
+		buckets:        make(map[string]*bucket),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Wrap throttles next with the default buckets.
+func (m *RateLimiter) Wrap(next http.Handler) http.Handler {
+	return m.WrapGroup("", next)
+}
+
+// WrapGroup throttles next with the buckets of group.
+func (m *RateLimiter) WrapGroup(group string, next http.Handler) http.Handler {
+	budget, ok := m.groups[group]
+	if !ok {
+		budget = m.budget
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit, class := budget.Read, "read"
+		if isMutating(r.Method) {
+			limit, class = budget.Write, "write"
+		}
+
+		allowed, remaining, reset, retryAfter := m.take(class+"\x00"+group+"\x00"+m.client(r), limit, time.Now())
+		setRateLimitHeaders(w.Header(), limit, remaining, reset)
+		if !allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(seconds(retryAfter)))
+			utils.WriteError(w, r, ErrRateLimited)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// WrapCredentials refuses requests with credentials from addresses whose
+// failure bucket is empty, and spends a token of it for every request whose
+// credentials turn out to be rejected with 401.
+func (m *RateLimiter) WrapCredentials(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(APIKeyHeader) == "" && r.Header.Get("Authorization") == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key := "failure\x00ip:" + utils.ClientIP(r, m.trustedProxies).String()
+		if remaining, reset, retryAfter := m.peek(key, m.failures, time.Now()); remaining < 1 {
+			setRateLimitHeaders(w.Header(), m.failures, 0, reset)
+			w.Header().Set("Retry-After", strconv.Itoa(seconds(retryAfter)))
+			utils.WriteError(w, r, ErrRateLimited)
+			return
+		}
+		next.ServeHTTP(&failureWriter{ResponseWriter: w, limiter: m, key: key}, r)
+	})
+}
+
+// failureWriter spends a failure token when the response is a 401, and
+// reports the failure budget in its headers.
+type failureWriter struct {
+	http.ResponseWriter
+	limiter     *RateLimiter
+	key         string
+	wroteHeader bool
+}
+
+func (w *failureWriter) WriteHeader(status int) {
+	if !w.wroteHeader && status == http.StatusUnauthorized {
+		limit := w.limiter.failures
+		_, remaining, reset, _ := w.limiter.take(w.key, limit, time.Now())
+		setRateLimitHeaders(w.Header(), limit, remaining, reset)
+	}
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *failureWriter) Write(data []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *failureWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func setRateLimitHeaders(header http.Header, limit RateLimit, remaining int, reset time.Duration) {
+	header.Set(RateLimitLimitHeader, strconv.Itoa(limit.Requests))
+	header.Set(RateLimitRemainingHeader, strconv.Itoa(remaining))
+	header.Set(RateLimitResetHeader, strconv.Itoa(seconds(reset)))
+	header.Set(RateLimitPolicyHeader, limit.policy())
+}
+
+// take spends a token of the bucket at key. It reports the tokens left, the
+// time until the bucket is full again and, when no token was left, the time
+// until the next one.
+func (m *RateLimiter) take(key string, limit RateLimit, now time.Time) (allowed bool, remaining int, reset, retryAfter time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	b := m.refill(key, limit, now)
+	rate := limit.rate()
+	if b.tokens >= 1 {
+		b.tokens--
+		allowed = true
+	} else {
+		retryAfter = time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	}
+	reset = time.Duration((float64(limit.Requests) - b.tokens) / rate * float64(time.Second))
+	return allowed, int(b.tokens), reset, retryAfter
+}
+
+// peek reports what take would without spending a token.
+func (m *RateLimiter) peek(key string, limit RateLimit, now time.Time) (remaining int, reset, retryAfter time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	b := m.refill(key, limit, now)
+	rate := limit.rate()
+	if b.tokens < 1 {
+		retryAfter = time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	}
+	reset = time.Duration((float64(limit.Requests) - b.tokens) / rate * float64(time.Second))
+	return int(b.tokens), reset, retryAfter
+}
+
+// refill returns the bucket at key with the tokens earned since it was last
+// used. Callers must hold the lock.
+func (m *RateLimiter) refill(key string, limit RateLimit, now time.Time) *bucket {
+	m.sweep(now)
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Requests), updated: now}
+		m.buckets[key] = b
+	}
+	b.tokens = min(float64(limit.Requests), b.tokens+now.Sub(b.updated).Seconds()*limit.rate())
+	b.updated = now
+	return b
+}
+
+// sweep drops the buckets that have refilled completely, which behave
+// exactly like new ones. Callers must hold the lock.
+func (m *RateLimiter) sweep(now time.Time) {
+	window := max(m.budget.Read.Per, m.budget.Write.Per, m.failures.Per)
+	for _, budget := range m.groups {
+		window = max(window, budget.Read.Per, budget.Write.Per)
+	}
+	if now.Sub(m.lastSweep) < window {
+		return
+	}
+	m.lastSweep = now
+
+	for key, b := range m.buckets {
+		if now.Sub(b.updated) >= window {
+			delete(m.buckets, key)
+		}
+	}
+}
+
+func (m *RateLimiter) client(r *http.Request) string {
+	if principal := utils.PrincipalFrom(r); principal.Authenticated() {
+		return principal.Subject
+	}
+	return "ip:" + utils.ClientIP(r, m.trustedProxies).String()
+}
+
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	utils "github.com/Korjick/go-http-quote/presentation/http"
+
+	"github.com/Korjick/go-http-quote/application/service"
+)
+
+const TraceparentHeader = "traceparent"
+
+// ServerTracer starts the span of a request, continuing the trace of the
+// W3C traceparent header of the caller when there is a valid one.
+type ServerTracer interface {
+	StartServer(ctx context.Context, traceparent, name string, attrs ...slog.Attr) (context.Context, service.Span)
+}
+
+// Tracing wraps every request in a server span, the parent of the spans of
+// the service and repository. The span is named after the route pattern,
+// as in "GET /quotes/{id}", and fails on 5xx responses.
+type Tracing struct {
+	tracer ServerTracer
+}
+
+func NewTracing(tracer ServerTracer) *Tracing {
+	return &Tracing{tracer: tracer}
+}
+
+func (m *Tracing) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := m.tracer.StartServer(r.Context(), r.Header.Get(TraceparentHeader), r.Method,
+			slog.String("http.request.method", r.Method),
+			slog.String("url.path", r.URL.Path),
+		)
+		r, route := utils.TrackRoute(r.WithContext(ctx))
+		counter := &countingWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(counter, r)
+
+		if pattern := route(); pattern != "" {
+			method, path, ok := strings.Cut(pattern, " ")
+			if !ok {
+				method, path = r.Method, pattern
+			}
+			span.SetName(method + " " + path)
+			span.SetAttributes(slog.String("http.route", path))
+		}
+		span.SetAttributes(slog.Int("http.response.status_code", counter.status))
+
+		var err error
+		if counter.status >= http.StatusInternalServerError {
+			err = errors.New(http.StatusText(counter.status))
+		}
+		span.End(err)
+	})
+}
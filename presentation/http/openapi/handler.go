@@ -0,0 +1,66 @@
+package openapi
+
+import (
+	"bytes"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const ContentType = "application/openapi+json"
+
+//go:embed docs.html
+var docsTemplate string
+
+var docsPage = template.Must(template.New("docs").Parse(docsTemplate))
+
+// Handler serves the document as JSON. The document is encoded once; its
+// hash is the ETag, so clients can cache it across restarts of the same
+// build.
+func Handler(doc *Document) http.Handler {
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		panic("openapi: encoding document: " + err.Error())
+	}
+	sum := sha256.Sum256(data)
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", ContentType)
+		w.Header().Set("ETag", etag)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+	})
+}
+
+// DocsHandler serves a page that renders the document at specURL and lets
+// the operations be tried from the browser. The page is self-contained, so
+// it works without access to a CDN.
+func DocsHandler(title, specURL string) http.Handler {
+	var buf bytes.Buffer
+	if err := docsPage.Execute(&buf, map[string]string{"Title": title, "SpecURL": specURL}); err != nil {
+		panic("openapi: rendering docs page: " + err.Error())
+	}
+	page := buf.Bytes()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if _, err := w.Write(page); err != nil {
+			slog.ErrorContext(r.Context(), "writing docs page", "error", err)
+		}
+	})
+}
@@ -0,0 +1,24 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Korjick/go-http-quote/domain/auth"
+)
+
+type principalKey struct{}
+
+// WithPrincipal returns a shallow copy of r acting as principal.
+func WithPrincipal(r *http.Request, principal auth.Principal) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), principalKey{}, principal))
+}
+
+// PrincipalFrom returns the principal set by the authentication middleware,
+// or auth.Anonymous if the request has none.
+func PrincipalFrom(r *http.Request) auth.Principal {
+	if principal, ok := r.Context().Value(principalKey{}).(auth.Principal); ok {
+		return principal
+	}
+	return auth.Anonymous
+}
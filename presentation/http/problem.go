@@ -0,0 +1,167 @@
+package http
+
+import (
+	"encoding/xml"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Korjick/go-http-quote/domain/apperror"
+	"github.com/Korjick/go-http-quote/presentation/http/i18n"
+)
+
+const (
+	ProblemContentType = "application/problem+json"
+	problemTypePrefix  = "/problems/"
+	blankProblemType   = "about:blank"
+	// UnavailableRetryAfter is the Retry-After of errors of
+	// apperror.KindUnavailable, long enough for a dependency to be retried.
+	UnavailableRetryAfter = 30 * time.Second
+)
+
+// Problem is an RFC 9457 problem details object. Code duplicates the last
+// segment of Type for clients that prefer a bare identifier. Title and Detail
+// are in the language negotiated from the Accept-Language header.
+type Problem struct {
+	XMLName  xml.Name       `json:"-" xml:"urn:ietf:rfc:7807 problem"`
+	Type     string         `json:"type" xml:"type"`
+	Title    string         `json:"title" xml:"title"`
+	Status   int            `json:"status" xml:"status"`
+	Detail   string         `json:"detail,omitempty" xml:"detail,omitempty"`
+	Instance string         `json:"instance,omitempty" xml:"instance,omitempty"`
+	Code     string         `json:"code,omitempty" xml:"code,omitempty"`
+	Errors   []ProblemField `json:"errors,omitempty" xml:"errors>error,omitempty"`
+	// RequestID is the X-Request-ID of the request, to quote when
+	// reporting the problem.
+	RequestID string `json:"request_id,omitempty" xml:"request_id,omitempty"`
+	language  i18n.Language
+}
+
+// ProblemField points at one offending member of a request body, as in the
+// "errors" extension of RFC 9457. Pointer is a JSON Pointer (RFC 6901).
+type ProblemField struct {
+	Pointer string `json:"pointer" xml:"pointer"`
+	Code    string `json:"code" xml:"code"`
+	Detail  string `json:"detail" xml:"detail"`
+}
+
+func (p Problem) String() string {
+	if p.Detail == "" {
+		return p.Title
+	}
+	return p.Title + ": " + p.Detail
+}
+
+func NewProblem(r *http.Request, status int, code, detail string) Problem {
+	problemType := blankProblemType
+	if code != "" {
+		problemType = problemTypePrefix + code
+	}
+	lang := i18n.FromRequest(r)
+	return Problem{
+		Type:      problemType,
+		Title:     lang.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  r.URL.RequestURI(),
+		Code:      code,
+		RequestID: RequestIDFrom(r.Context()),
+		language:  lang,
+	}
+}
+
+// LocalizedProblem builds a problem whose detail is the catalog message for
+// code.
+func LocalizedProblem(r *http.Request, status int, code string) Problem {
+	detail, _ := i18n.FromRequest(r).Message(code)
+	return NewProblem(r, status, code, detail)
+}
+
+func StatusForKind(kind apperror.Kind) int {
+	switch kind {
+	case apperror.KindMalformed:
+		return http.StatusBadRequest
+	case apperror.KindInvalid:
+		return http.StatusUnprocessableEntity
+	case apperror.KindNotFound:
+		return http.StatusNotFound
+	case apperror.KindConflict:
+		return http.StatusConflict
+	case apperror.KindUnsupported:
+		return http.StatusUnsupportedMediaType
+	case apperror.KindNotAcceptable:
+		return http.StatusNotAcceptable
+	case apperror.KindTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case apperror.KindUnauthenticated:
+		return http.StatusUnauthorized
+	case apperror.KindForbidden:
+		return http.StatusForbidden
+	case apperror.KindTooManyRequests:
+		return http.StatusTooManyRequests
+	case apperror.KindTimeout:
+		return http.StatusServiceUnavailable
+	case apperror.KindCanceled:
+		return StatusClientClosedRequest
+	case apperror.KindUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func ProblemFromError(r *http.Request, err error) Problem {
+	err = contextError(err)
+	kind := apperror.KindOf(err)
+	if kind == apperror.KindInternal {
+		slog.ErrorContext(r.Context(), "internal error", "method", r.Method, "path", r.URL.Path, "error", err)
+	}
+
+	lang := i18n.FromRequest(r)
+	code, message := lang.Localize(err)
+	problem := NewProblem(r, StatusForKind(kind), code, message)
+
+	var bodyErr *BodyError
+	if errors.As(err, &bodyErr) {
+		for _, field := range bodyErr.Fields {
+			code, message := lang.Localize(field.Err)
+			problem.Errors = append(problem.Errors, ProblemField{Pointer: field.Pointer, Code: code, Detail: message})
+		}
+	}
+	return problem
+}
+
+// WriteProblem encodes the problem in the format negotiated from the Accept
+// header, falling back to JSON when none of the accepted formats is
+// available, since a 406 has to be reported somehow.
+func WriteProblem(w http.ResponseWriter, r *http.Request, problem Problem) {
+	encoder, err := DefaultEncoders.Negotiate(r.Header.Get("Accept"))
+	if err != nil {
+		encoder = jsonEncoder{}
+	}
+
+	contentType := encoder.ContentType()
+	if pe, ok := encoder.(problemEncoder); ok {
+		contentType = pe.ProblemContentType()
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	AddVary(w.Header(), "Accept")
+	AddVary(w.Header(), "Accept-Language")
+	if problem.language != "" {
+		w.Header().Set("Content-Language", string(problem.language))
+	}
+	w.WriteHeader(problem.Status)
+	if err := encoder.Encode(w, problem); err != nil {
+		slog.ErrorContext(r.Context(), "encoding problem response", "error", err)
+	}
+}
+
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	if apperror.KindOf(err) == apperror.KindUnavailable && w.Header().Get("Retry-After") == "" {
+		w.Header().Set("Retry-After", strconv.Itoa(int(UnavailableRetryAfter/time.Second)))
+	}
+	WriteProblem(w, r, ProblemFromError(r, err))
+}
@@ -0,0 +1,315 @@
+package bulk
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/Korjick/go-http-quote/domain/apperror"
+	"github.com/Korjick/go-http-quote/domain/quote/entity"
+	"github.com/Korjick/go-http-quote/domain/quote/repository"
+)
+
+const (
+	fortuneDelimiter   = '%'
+	fortuneAttribution = "-- "
+	fortuneDataVersion = 2
+	fortuneArchiveName = "quotes"
+)
+
+var ErrFortuneTooLarge = apperror.New(apperror.KindTooLarge, "fortune_too_large", "fortune file exceeds 4 GiB")
+
+// fortuneSource reads the classic fortune(6) format: entries separated by
+// lines holding a single '%', with an optional trailing "-- Author" line.
+// A text line that is a '%' preceded by backslashes loses one of them, which
+// undoes the escaping of formatFortuneEntry.
+type fortuneSource struct {
+	scanner *bufio.Scanner
+	row     int
+	done    bool
+}
+
+func newFortuneSource(r io.Reader) *fortuneSource {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	return &fortuneSource{scanner: scanner}
+}
+
+func (s *fortuneSource) Next() (entity.QuoteDraft, error) {
+	for !s.done {
+		var lines []string
+		for {
+			if !s.scanner.Scan() {
+				if err := s.scanner.Err(); err != nil {
+					return entity.QuoteDraft{}, malformed(err)
+				}
+				s.done = true
+				break
+			}
+			line := strings.TrimRight(s.scanner.Text(), "\r")
+			if line == string(fortuneDelimiter) {
+				break
+			}
+			lines = append(lines, unescapeFortuneLine(line))
+		}
+
+		entry := strings.TrimSpace(strings.Join(lines, "\n"))
+		if entry == "" {
+			continue
+		}
+		s.row++
+		return parseFortuneEntry(entry), nil
+	}
+	return entity.QuoteDraft{}, io.EOF
+}
+
+func parseFortuneEntry(entry string) entity.QuoteDraft {
+	text, last := "", entry
+	if i := strings.LastIndexByte(entry, '\n'); i >= 0 {
+		text, last = entry[:i], entry[i+1:]
+	}
+
+	author, ok := strings.CutPrefix(strings.TrimSpace(last), fortuneAttribution)
+	if !ok {
+		return entity.QuoteDraft{Text: entry}
+	}
+	return entity.QuoteDraft{Author: strings.TrimSpace(author), Text: strings.TrimSpace(text)}
+}
+
+// formatFortuneEntry writes a quote as a fortune entry. A text line that is
+// a single '%' would end the entry early, so it gets a backslash, as does
+// any line that already looks escaped.
+func formatFortuneEntry(quote *entity.Quote) string {
+	lines := strings.Split(quote.Text, "\n")
+	for i, line := range lines {
+		if isFortuneDelimiter(strings.TrimLeft(strings.TrimRight(line, "\r"), "\\")) {
+			lines[i] = `\` + line
+		}
+	}
+	return strings.Join(lines, "\n") + "\n\t\t" + fortuneAttribution + quote.Author + "\n" + string(fortuneDelimiter) + "\n"
+}
+
+func isFortuneDelimiter(line string) bool {
+	return line == string(fortuneDelimiter)
+}
+
+func unescapeFortuneLine(line string) string {
+	if escaped, ok := strings.CutPrefix(line, `\`); ok && isFortuneDelimiter(strings.TrimLeft(escaped, `\`)) {
+		return escaped
+	}
+	return line
+}
+
+type fortuneWriter struct {
+	w io.Writer
+}
+
+func (w *fortuneWriter) Write(quote *entity.Quote) error {
+	_, err := io.WriteString(w.w, formatFortuneEntry(quote))
+	return err
+}
+
+func (w *fortuneWriter) Close() error {
+	return nil
+}
+
+// fortuneArchiveWriter publishes a ready-to-install fortune database: the
+// text file and its strfile(8) index. The tar header of the text needs its
+// size, so the text is kept in a temporary file until Close, while the index
+// is built as entries are written.
+type fortuneArchiveWriter struct {
+	w        io.Writer
+	snapshot repository.Snapshot
+	index    *FortuneIndex
+	text     *os.File
+	buffer   *bufio.Writer
+	size     uint64
+}
+
+func newFortuneArchiveWriter(w io.Writer, snapshot repository.Snapshot) *fortuneArchiveWriter {
+	return &fortuneArchiveWriter{w: w, snapshot: snapshot, index: newFortuneIndex()}
+}
+
+func (w *fortuneArchiveWriter) Write(quote *entity.Quote) error {
+	if w.text == nil {
+		text, err := os.CreateTemp("", "quotes-*.fortune")
+		if err != nil {
+			return err
+		}
+		w.text, w.buffer = text, bufio.NewWriter(text)
+	}
+
+	entry := formatFortuneEntry(quote)
+	w.size += uint64(len(entry))
+	if w.size > math.MaxUint32 {
+		return ErrFortuneTooLarge
+	}
+	// Every entry ends with its delimiter line and has text before it.
+	w.index.add(uint64(len(entry)-len(string(fortuneDelimiter)+"\n")), w.size)
+	_, err := w.buffer.WriteString(entry)
+	return err
+}
+
+func (w *fortuneArchiveWriter) Close() error {
+	defer w.Abort()
+
+	if w.text != nil {
+		if err := w.buffer.Flush(); err != nil {
+			return err
+		}
+		if _, err := w.text.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+	}
+	var dat bytes.Buffer
+	if _, err := w.index.finish().WriteTo(&dat); err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(w.w)
+	archive := tar.NewWriter(gz)
+	modTime := w.snapshot.TakenAt.UTC()
+	header := &tar.Header{Name: fortuneArchiveName, Mode: 0o644, Size: int64(w.size), ModTime: modTime}
+	if err := archive.WriteHeader(header); err != nil {
+		return err
+	}
+	if w.text != nil {
+		if _, err := io.Copy(archive, w.text); err != nil {
+			return err
+		}
+	}
+	header = &tar.Header{Name: fortuneArchiveName + ".dat", Mode: 0o644, Size: int64(dat.Len()), ModTime: modTime}
+	if err := archive.WriteHeader(header); err != nil {
+		return err
+	}
+	if _, err := archive.Write(dat.Bytes()); err != nil {
+		return err
+	}
+
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// Abort removes the temporary file of an export that is not closed.
+func (w *fortuneArchiveWriter) Abort() {
+	if w.text == nil {
+		return
+	}
+	_ = w.text.Close()
+	_ = os.Remove(w.text.Name())
+	w.text, w.buffer = nil, nil
+}
+
+// FortuneIndex is the content of a strfile(8) ".dat" file.
+type FortuneIndex struct {
+	Version   uint32
+	LongLen   uint32
+	ShortLen  uint32
+	Flags     uint32
+	Delimiter byte
+	Offsets   []uint32
+}
+
+func (idx *FortuneIndex) NumStr() int {
+	return max(len(idx.Offsets)-1, 0)
+}
+
+// BuildFortuneIndex scans a fortune text file the same way strfile does:
+// every non-empty entry contributes the offset just past its delimiter line,
+// after the initial zero offset.
+func BuildFortuneIndex(r io.Reader) (*FortuneIndex, error) {
+	idx := newFortuneIndex()
+
+	reader := bufio.NewReader(r)
+	var pos, lastOff uint64
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		eof := err == io.EOF
+		pos += uint64(len(line))
+
+		if eof || line == string(fortuneDelimiter)+"\n" {
+			length := pos - lastOff
+			if !eof {
+				length -= uint64(len(line))
+			}
+			lastOff = pos
+
+			if length > 0 {
+				if pos > math.MaxUint32 {
+					return nil, ErrFortuneTooLarge
+				}
+				idx.add(length, pos)
+			}
+		}
+
+		if eof {
+			break
+		}
+	}
+
+	return idx.finish(), nil
+}
+
+func newFortuneIndex() *FortuneIndex {
+	return &FortuneIndex{
+		Version:   fortuneDataVersion,
+		Delimiter: fortuneDelimiter,
+		ShortLen:  math.MaxUint32,
+		Offsets:   []uint32{0},
+	}
+}
+
+// add records an entry of length bytes that ends, delimiter line included,
+// at offset next, which must fit in 32 bits.
+func (idx *FortuneIndex) add(length, next uint64) {
+	idx.Offsets = append(idx.Offsets, uint32(next))
+	idx.LongLen = max(idx.LongLen, uint32(length))
+	idx.ShortLen = min(idx.ShortLen, uint32(length))
+}
+
+// finish fixes up the lengths of an index without entries.
+func (idx *FortuneIndex) finish() *FortuneIndex {
+	if idx.NumStr() == 0 {
+		idx.ShortLen = 0
+	}
+	return idx
+}
+
+func (idx *FortuneIndex) WriteTo(w io.Writer) (int64, error) {
+	header := struct {
+		Version  uint32
+		NumStr   uint32
+		LongLen  uint32
+		ShortLen uint32
+		Flags    uint32
+		Stuff    [4]byte
+	}{
+		Version:  idx.Version,
+		NumStr:   uint32(idx.NumStr()),
+		LongLen:  idx.LongLen,
+		ShortLen: idx.ShortLen,
+		Flags:    idx.Flags,
+		Stuff:    [4]byte{idx.Delimiter},
+	}
+
+	buf := bufio.NewWriter(w)
+	if err := binary.Write(buf, binary.BigEndian, header); err != nil {
+		return 0, err
+	}
+	if err := binary.Write(buf, binary.BigEndian, idx.Offsets); err != nil {
+		return 0, err
+	}
+	n := int64(binary.Size(header) + 4*len(idx.Offsets))
+	return n, buf.Flush()
+}
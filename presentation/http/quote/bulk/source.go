@@ -0,0 +1,203 @@
+package bulk
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+
+	"github.com/Korjick/go-http-quote/application/service"
+	"github.com/Korjick/go-http-quote/domain/apperror"
+	"github.com/Korjick/go-http-quote/domain/quote/entity"
+	"github.com/Korjick/go-http-quote/presentation/http/quote/dto"
+)
+
+const maxLineSize = 1 << 20
+
+var (
+	ErrUnsupportedFormat = apperror.New(apperror.KindUnsupported, "unsupported_import_format", "unsupported import format")
+	ErrMissingColumn     = apperror.New(apperror.KindMalformed, "missing_column", "missing required column")
+	ErrNotAnArray        = apperror.New(apperror.KindMalformed, "not_an_array", "expected JSON array")
+	ErrMalformedRecord   = apperror.New(apperror.KindMalformed, "malformed_record", "malformed record")
+)
+
+// NewSource reads quotes in the format of contentType. JSON records and CSV
+// columns follow the bodies of the API version of mapper.
+func NewSource(contentType string, r io.Reader, mapper dto.Mapper) (service.QuoteSource, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, contentType)
+	}
+
+	switch mediaType {
+	case "application/json":
+		return newJSONArraySource(r, mapper), nil
+	case "application/x-ndjson", "application/jsonl", "application/x-jsonlines", "application/jsonlines":
+		return newJSONLinesSource(r, mapper), nil
+	case "text/csv":
+		return newCSVSource(r, mapper.TextField()), nil
+	case "application/x-fortune", "text/x-fortune":
+		return newFortuneSource(r), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, mediaType)
+	}
+}
+
+func malformed(err error) error {
+	return fmt.Errorf("%w: %v", ErrMalformedRecord, err)
+}
+
+// decodeDraft decodes a JSON record into the create request of mapper.
+func decodeDraft(data []byte, mapper dto.Mapper) (entity.QuoteDraft, error) {
+	req := mapper.CreateRequest()
+	if err := json.Unmarshal(data, req); err != nil {
+		return entity.QuoteDraft{}, err
+	}
+	return req.Draft(), nil
+}
+
+type jsonLinesSource struct {
+	scanner *bufio.Scanner
+	mapper  dto.Mapper
+	row     int
+}
+
+func newJSONLinesSource(r io.Reader, mapper dto.Mapper) *jsonLinesSource {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	return &jsonLinesSource{scanner: scanner, mapper: mapper}
+}
+
+func (s *jsonLinesSource) Next() (entity.QuoteDraft, error) {
+	for s.scanner.Scan() {
+		line := strings.TrimSpace(s.scanner.Text())
+		if line == "" {
+			continue
+		}
+		s.row++
+
+		draft, err := decodeDraft([]byte(line), s.mapper)
+		if err != nil {
+			return entity.QuoteDraft{}, &service.RowError{Row: s.row, Err: malformed(err)}
+		}
+		return draft, nil
+	}
+
+	if err := s.scanner.Err(); err != nil {
+		return entity.QuoteDraft{}, malformed(err)
+	}
+	return entity.QuoteDraft{}, io.EOF
+}
+
+type jsonArraySource struct {
+	decoder *json.Decoder
+	mapper  dto.Mapper
+	started bool
+	row     int
+}
+
+func newJSONArraySource(r io.Reader, mapper dto.Mapper) *jsonArraySource {
+	return &jsonArraySource{decoder: json.NewDecoder(r), mapper: mapper}
+}
+
+func (s *jsonArraySource) Next() (entity.QuoteDraft, error) {
+	if !s.started {
+		token, err := s.decoder.Token()
+		if err != nil {
+			return entity.QuoteDraft{}, malformed(err)
+		}
+		if delim, ok := token.(json.Delim); !ok || delim != '[' {
+			return entity.QuoteDraft{}, ErrNotAnArray
+		}
+		s.started = true
+	}
+
+	if !s.decoder.More() {
+		if _, err := s.decoder.Token(); err != nil {
+			return entity.QuoteDraft{}, malformed(err)
+		}
+		return entity.QuoteDraft{}, io.EOF
+	}
+	s.row++
+
+	var raw json.RawMessage
+	if err := s.decoder.Decode(&raw); err != nil {
+		return entity.QuoteDraft{}, malformed(err)
+	}
+
+	draft, err := decodeDraft(raw, s.mapper)
+	if err != nil {
+		return entity.QuoteDraft{}, &service.RowError{Row: s.row, Err: malformed(err)}
+	}
+	return draft, nil
+}
+
+type csvSource struct {
+	reader    *csv.Reader
+	textField string
+	authorCol int
+	quoteCol  int
+	started   bool
+	row       int
+}
+
+func newCSVSource(r io.Reader, textField string) *csvSource {
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+	return &csvSource{reader: reader, textField: textField}
+}
+
+func (s *csvSource) readHeader() error {
+	header, err := s.reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("%w: author, %s", ErrMissingColumn, s.textField)
+		}
+		return malformed(err)
+	}
+
+	s.authorCol, s.quoteCol = -1, -1
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "author":
+			s.authorCol = i
+		case s.textField:
+			s.quoteCol = i
+		}
+	}
+	if s.authorCol < 0 {
+		return fmt.Errorf("%w: author", ErrMissingColumn)
+	}
+	if s.quoteCol < 0 {
+		return fmt.Errorf("%w: %s", ErrMissingColumn, s.textField)
+	}
+	return nil
+}
+
+func (s *csvSource) Next() (entity.QuoteDraft, error) {
+	if !s.started {
+		if err := s.readHeader(); err != nil {
+			return entity.QuoteDraft{}, err
+		}
+		s.started = true
+	}
+
+	record, err := s.reader.Read()
+	if errors.Is(err, io.EOF) {
+		return entity.QuoteDraft{}, io.EOF
+	}
+	s.row++
+
+	if errors.Is(err, csv.ErrFieldCount) {
+		return entity.QuoteDraft{}, &service.RowError{Row: s.row, Err: malformed(csv.ErrFieldCount)}
+	}
+	if err != nil {
+		return entity.QuoteDraft{}, malformed(err)
+	}
+
+	return entity.QuoteDraft{Author: record[s.authorCol], Text: record[s.quoteCol]}, nil
+}
@@ -0,0 +1,277 @@
+package bulk
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/Korjick/go-http-quote/domain/apperror"
+	"github.com/Korjick/go-http-quote/domain/quote/entity"
+	"github.com/Korjick/go-http-quote/domain/quote/repository"
+	"github.com/Korjick/go-http-quote/presentation/http/quote/dto"
+)
+
+type Format string
+
+const (
+	FormatJSONLines Format = "jsonl"
+	FormatCSV       Format = "csv"
+	FormatTarGz     Format = "tar.gz"
+
+	FormatFortune        Format = "fortune"
+	FormatFortuneArchive Format = "fortune.tar.gz"
+)
+
+const (
+	archiveChunkSize    = 1000
+	archiveManifestName = "manifest.json"
+)
+
+var ErrUnsupportedExportFormat = apperror.New(apperror.KindMalformed, "unsupported_export_format", "unsupported export format")
+
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case "", FormatJSONLines:
+		return FormatJSONLines, nil
+	case FormatCSV:
+		return FormatCSV, nil
+	case FormatTarGz, "tgz":
+		return FormatTarGz, nil
+	case FormatFortune:
+		return FormatFortune, nil
+	case FormatFortuneArchive:
+		return FormatFortuneArchive, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedExportFormat, s)
+	}
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatTarGz, FormatFortuneArchive:
+		return "application/gzip"
+	case FormatFortune:
+		return "text/plain; charset=utf-8"
+	default:
+		return "application/x-ndjson"
+	}
+}
+
+func (f Format) Extension() string {
+	return string(f)
+}
+
+type Writer interface {
+	Write(quote *entity.Quote) error
+	Close() error
+}
+
+// Aborter is implemented by writers that hold resources, such as temporary
+// files, until Close. Abort releases them when the export fails instead.
+type Aborter interface {
+	Abort()
+}
+
+// Abort releases the resources of w if it holds any. It does nothing once
+// w is closed, so it can be deferred.
+func Abort(w Writer) {
+	if aborter, ok := w.(Aborter); ok {
+		aborter.Abort()
+	}
+}
+
+// NewWriter writes quotes in format. JSON records and CSV columns follow
+// the bodies of the API version of mapper.
+func NewWriter(format Format, w io.Writer, snapshot repository.Snapshot, mapper dto.Mapper) (Writer, error) {
+	switch format {
+	case FormatJSONLines:
+		return &jsonLinesWriter{encoder: json.NewEncoder(w), mapper: mapper}, nil
+	case FormatCSV:
+		return newCSVWriter(w, mapper.TextField()), nil
+	case FormatTarGz:
+		return newArchiveWriter(w, snapshot, mapper), nil
+	case FormatFortune:
+		return &fortuneWriter{w: w}, nil
+	case FormatFortuneArchive:
+		return newFortuneArchiveWriter(w, snapshot), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedExportFormat, format)
+	}
+}
+
+type jsonLinesWriter struct {
+	encoder *json.Encoder
+	mapper  dto.Mapper
+}
+
+func (w *jsonLinesWriter) Write(quote *entity.Quote) error {
+	return w.encoder.Encode(w.mapper.Quote(quote))
+}
+
+func (w *jsonLinesWriter) Close() error {
+	return nil
+}
+
+type csvWriter struct {
+	writer        *csv.Writer
+	textField     string
+	headerWritten bool
+}
+
+func newCSVWriter(w io.Writer, textField string) *csvWriter {
+	return &csvWriter{writer: csv.NewWriter(w), textField: textField}
+}
+
+func (w *csvWriter) writeHeader() error {
+	if w.headerWritten {
+		return nil
+	}
+	w.headerWritten = true
+	return w.writer.Write([]string{"id", "author", w.textField, "created_at"})
+}
+
+func (w *csvWriter) Write(quote *entity.Quote) error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	return w.writer.Write([]string{
+		strconv.FormatInt(int64(quote.ID), 10),
+		quote.Author,
+		quote.Text,
+		quote.CreatedAt.Format(time.RFC3339Nano),
+	})
+}
+
+func (w *csvWriter) Close() error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+type ArchiveManifest struct {
+	Version    int                   `json:"version"`
+	Snapshot   ArchiveSnapshot       `json:"snapshot"`
+	QuoteCount int                   `json:"quote_count"`
+	Files      []ArchiveManifestFile `json:"files"`
+}
+
+type ArchiveSnapshot struct {
+	Revision uint64    `json:"revision"`
+	MaxID    int64     `json:"max_id"`
+	TakenAt  time.Time `json:"taken_at"`
+}
+
+type ArchiveManifestFile struct {
+	Name       string `json:"name"`
+	Format     string `json:"format"`
+	QuoteCount int    `json:"quote_count"`
+}
+
+// archiveWriter streams quotes into a gzip'd tarball as a sequence of JSONL
+// chunks. Tar entries need their size up front, so only the current chunk is
+// buffered. The manifest is written last, once the totals are known.
+type archiveWriter struct {
+	gzip     *gzip.Writer
+	tar      *tar.Writer
+	chunk    bytes.Buffer
+	encoder  *json.Encoder
+	mapper   dto.Mapper
+	pending  int
+	manifest ArchiveManifest
+}
+
+func newArchiveWriter(w io.Writer, snapshot repository.Snapshot, mapper dto.Mapper) *archiveWriter {
+	gz := gzip.NewWriter(w)
+	aw := &archiveWriter{
+		gzip:   gz,
+		tar:    tar.NewWriter(gz),
+		mapper: mapper,
+		manifest: ArchiveManifest{
+			Version: 1,
+			Snapshot: ArchiveSnapshot{
+				Revision: snapshot.Revision,
+				MaxID:    int64(snapshot.MaxID),
+				TakenAt:  snapshot.TakenAt.UTC(),
+			},
+			Files: make([]ArchiveManifestFile, 0),
+		},
+	}
+	aw.encoder = json.NewEncoder(&aw.chunk)
+	return aw
+}
+
+func (w *archiveWriter) Write(quote *entity.Quote) error {
+	if err := w.encoder.Encode(w.mapper.Quote(quote)); err != nil {
+		return err
+	}
+	w.pending++
+	w.manifest.QuoteCount++
+
+	if w.pending >= archiveChunkSize {
+		return w.flushChunk()
+	}
+	return nil
+}
+
+func (w *archiveWriter) flushChunk() error {
+	if w.pending == 0 {
+		return nil
+	}
+
+	name := fmt.Sprintf("quotes/%05d.jsonl", len(w.manifest.Files)+1)
+	if err := w.writeEntry(name, w.chunk.Bytes()); err != nil {
+		return err
+	}
+
+	w.manifest.Files = append(w.manifest.Files, ArchiveManifestFile{
+		Name:       name,
+		Format:     string(FormatJSONLines),
+		QuoteCount: w.pending,
+	})
+	w.chunk.Reset()
+	w.pending = 0
+	return nil
+}
+
+func (w *archiveWriter) writeEntry(name string, data []byte) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    int64(len(data)),
+		ModTime: w.manifest.Snapshot.TakenAt,
+	}
+	if err := w.tar.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := w.tar.Write(data)
+	return err
+}
+
+func (w *archiveWriter) Close() error {
+	if err := w.flushChunk(); err != nil {
+		return err
+	}
+
+	manifest, err := json.MarshalIndent(w.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := w.writeEntry(archiveManifestName, manifest); err != nil {
+		return err
+	}
+
+	if err := w.tar.Close(); err != nil {
+		return err
+	}
+	return w.gzip.Close()
+}
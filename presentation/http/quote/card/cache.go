@@ -0,0 +1,64 @@
+package card
+
+import (
+	"container/list"
+	"sync"
+)
+
+type cacheKey struct {
+	digest string
+	theme  string
+	size   string
+	format Format
+}
+
+type cacheEntry struct {
+	key  cacheKey
+	data []byte
+}
+
+// cache keeps the most recently requested rendered cards.
+type cache struct {
+	capacity int
+	entries  map[cacheKey]*list.Element
+	order    *list.List
+	mutex    sync.Mutex
+}
+
+func newCache(capacity int) *cache {
+	return &cache{
+		capacity: capacity,
+		entries:  make(map[cacheKey]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (c *cache) get(key cacheKey) ([]byte, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*cacheEntry).data, true
+}
+
+func (c *cache) put(key cacheKey, data []byte) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*cacheEntry).data = data
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, data: data})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
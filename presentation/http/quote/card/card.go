@@ -0,0 +1,260 @@
+package card
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"image/color"
+	"math"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/Korjick/go-http-quote/domain/apperror"
+)
+
+//go:embed fonts/DejaVuSerif.ttf
+var serifFontData []byte
+
+// fontFamily is what SVG viewers are asked for. SVG cards embed the glyphs
+// they use as 'DejaVu Serif', so line breaks match the layout exactly; the
+// fallbacks are for viewers that ignore @font-face.
+const (
+	embeddedFontFamily = "DejaVu Serif"
+	fontFamily         = "'" + embeddedFontFamily + "', Georgia, serif"
+)
+
+var loadFont = sync.OnceValues(func() (*font, error) {
+	return parseFont(serifFontData)
+})
+
+var (
+	ErrUnknownTheme = apperror.New(apperror.KindMalformed, "unknown_card_theme", "unknown card theme")
+	ErrUnknownSize  = apperror.New(apperror.KindMalformed, "unknown_card_size", "unknown card size")
+)
+
+type Format string
+
+const (
+	FormatPNG Format = "png"
+	FormatSVG Format = "svg"
+)
+
+func (f Format) ContentType() string {
+	if f == FormatSVG {
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+type Theme struct {
+	Name       string
+	Background color.NRGBA
+	Text       color.NRGBA
+	Accent     color.NRGBA
+}
+
+type Size struct {
+	Name          string
+	Width, Height int
+}
+
+// The first theme and size are the defaults.
+var (
+	Themes = []Theme{
+		{Name: "light", Background: rgb(0xfaf7f0), Text: rgb(0x1f1f1f), Accent: rgb(0xc2410c)},
+		{Name: "dark", Background: rgb(0x111827), Text: rgb(0xf9fafb), Accent: rgb(0xf59e0b)},
+		{Name: "sepia", Background: rgb(0xf4ecd8), Text: rgb(0x3b2f2f), Accent: rgb(0x8b5e3c)},
+	}
+	Sizes = []Size{
+		{Name: "landscape", Width: 1200, Height: 630},
+		{Name: "square", Width: 1080, Height: 1080},
+		{Name: "portrait", Width: 1080, Height: 1350},
+		{Name: "story", Width: 1080, Height: 1920},
+	}
+)
+
+type Options struct {
+	Theme Theme
+	Size  Size
+}
+
+// ParseOptions reads the "theme" and "size" query parameters.
+func ParseOptions(query url.Values) (Options, error) {
+	opts := Options{Theme: Themes[0], Size: Sizes[0]}
+
+	if name := query.Get("theme"); name != "" {
+		i := slices.IndexFunc(Themes, func(t Theme) bool { return t.Name == name })
+		if i < 0 {
+			return Options{}, fmt.Errorf("%w: %q", ErrUnknownTheme, name)
+		}
+		opts.Theme = Themes[i]
+	}
+
+	if name := query.Get("size"); name != "" {
+		i := slices.IndexFunc(Sizes, func(s Size) bool { return s.Name == name })
+		if i < 0 {
+			return Options{}, fmt.Errorf("%w: %q", ErrUnknownSize, name)
+		}
+		opts.Size = Sizes[i]
+	}
+	return opts, nil
+}
+
+// layout is the placement of the text on a card, shared by the PNG and SVG
+// renderers. Coordinates are in pixels from the top left corner; y values
+// are baselines.
+type layout struct {
+	size       Size
+	x          float64
+	textSize   float64
+	lineHeight float64
+	lines      []string
+	firstLine  float64
+	author     string
+	authorSize float64
+	authorLine float64
+}
+
+const (
+	lineSpacing = 1.35
+	minTextSize = 14
+	// maxTextLength and maxAuthorLength, in characters, are more than a
+	// card can show even at minTextSize, and bound the work of the layout.
+	maxTextLength   = 1000
+	maxAuthorLength = 200
+)
+
+// newLayout fits the text with the largest size that fits the card, in steps
+// of a pixel down to minTextSize, below which the text is truncated. Text
+// that fits at one size fits at every smaller one, so the size is found by
+// binary search.
+func newLayout(ctx context.Context, f *font, text, author string, size Size) (layout, error) {
+	short := float64(min(size.Width, size.Height))
+	padding := 0.08 * short
+	width := float64(size.Width) - 2*padding
+
+	l := layout{
+		size:       size,
+		x:          padding,
+		author:     "— " + clip(author, maxAuthorLength),
+		authorSize: max(16, 0.04*short),
+	}
+	if measure(f, l.author, l.authorSize) > width {
+		l.author = truncate(f, []string{l.author}, l.authorSize, width)[0]
+	}
+	authorBlock := 2.2 * l.authorSize
+	available := float64(size.Height) - 2*padding - authorBlock
+
+	quoted := "“" + clip(strings.TrimSpace(text), maxTextLength) + "”"
+	largest := 0.09 * short
+	fits := func(textSize float64) bool {
+		return float64(len(wrap(f, quoted, textSize, width)))*lineSpacing*textSize <= available
+	}
+	// The answer is a step in [lo, hi]; the last step, at or below
+	// minTextSize, is taken even if the text does not fit.
+	lo, hi := 0, max(0, int(math.Ceil(largest-minTextSize)))
+	for lo < hi {
+		if err := ctx.Err(); err != nil {
+			return layout{}, err
+		}
+		mid := (lo + hi) / 2
+		if fits(largest - float64(mid)) {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+
+	l.textSize = largest - float64(lo)
+	l.lineHeight = lineSpacing * l.textSize
+	l.lines = wrap(f, quoted, l.textSize, width)
+	if float64(len(l.lines))*l.lineHeight > available {
+		l.lines = truncate(f, l.lines[:max(1, int(available/l.lineHeight))], l.textSize, width)
+	}
+
+	ascent := float64(f.ascent) * l.textSize / float64(f.unitsPerEm)
+	total := float64(len(l.lines))*l.lineHeight + authorBlock
+	top := (float64(size.Height) - total) / 2
+	l.firstLine = top + ascent
+	l.authorLine = top + float64(len(l.lines))*l.lineHeight + 1.6*l.authorSize
+	return l, nil
+}
+
+// clip cuts s to at most n characters, marking the cut with an ellipsis.
+func clip(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return strings.TrimSpace(string([]rune(s)[:n])) + "…"
+}
+
+func measure(f *font, s string, size float64) float64 {
+	units := 0
+	for _, r := range s {
+		units += f.advance(f.index(r))
+	}
+	return float64(units) * size / float64(f.unitsPerEm)
+}
+
+// wrap breaks text into lines no wider than width, at spaces where possible
+// and inside words that do not fit on a line of their own. Line breaks in
+// the text are kept.
+func wrap(f *font, text string, size, width float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if measure(f, candidate, size) <= width {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			for measure(f, word, size) > width && utf8.RuneCountInString(word) > 1 {
+				head, tail := splitToWidth(f, word, size, width)
+				lines = append(lines, head)
+				word = tail
+			}
+			line = word
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func splitToWidth(f *font, word string, size, width float64) (string, string) {
+	runes := []rune(word)
+	n := 1
+	for n < len(runes) && measure(f, string(runes[:n+1]), size) <= width {
+		n++
+	}
+	return string(runes[:n]), string(runes[n:])
+}
+
+// truncate ends the last of lines with an ellipsis that fits within width.
+func truncate(f *font, lines []string, size, width float64) []string {
+	last := []rune(lines[len(lines)-1])
+	for len(last) > 0 && measure(f, string(last)+"…", size) > width {
+		last = last[:len(last)-1]
+	}
+	lines[len(lines)-1] = strings.TrimSpace(string(last)) + "…"
+	return lines
+}
+
+func rgb(v uint32) color.NRGBA {
+	return color.NRGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}
+}
+
+func cssColor(c color.NRGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
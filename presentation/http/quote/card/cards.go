@@ -0,0 +1,100 @@
+package card
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strconv"
+	"time"
+
+	utils "github.com/Korjick/go-http-quote/presentation/http"
+
+	"github.com/Korjick/go-http-quote/application/service"
+	"github.com/Korjick/go-http-quote/domain/quote/entity"
+)
+
+const (
+	DefaultCacheSize = 256
+	cacheMaxAge      = 24 * time.Hour
+)
+
+// Cards renders quotes as images for sharing. Rendered cards are cached by
+// the content of the quote rather than its ID, so an edited quote gets a new
+// card and a new ETag while a deleted and re-created ID never sees a stale
+// one.
+//
+// Rendering is CPU bound, so at most one card per processor is rendered at a
+// time and further requests wait for their turn.
+type Cards struct {
+	service *service.QuoteService
+	cache   *cache
+	renders chan struct{}
+}
+
+func NewCards(service *service.QuoteService) *Cards {
+	return &Cards{
+		service: service,
+		cache:   newCache(DefaultCacheSize),
+		renders: make(chan struct{}, runtime.GOMAXPROCS(0)),
+	}
+}
+
+func (c *Cards) Serve(w http.ResponseWriter, r *http.Request, id entity.QuoteID, format Format) {
+	opts, err := ParseOptions(r.URL.Query())
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	quote, err := c.service.GetQuote(r.Context(), id)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	key := cacheKey{digest: quoteDigest(quote), theme: opts.Theme.Name, size: opts.Size.Name, format: format}
+	data, ok := c.cache.get(key)
+	if !ok {
+		data, err = c.render(r.Context(), quote, opts, format)
+		if err != nil {
+			utils.WriteError(w, r, err)
+			return
+		}
+		c.cache.put(key, data)
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(cacheMaxAge.Seconds())))
+	w.Header().Set("ETag", fmt.Sprintf(`"%s-%s-%s"`, key.digest[:16], key.theme, key.size))
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+}
+
+// render waits for a free render slot, giving up when ctx is done.
+func (c *Cards) render(ctx context.Context, quote *entity.Quote, opts Options, format Format) ([]byte, error) {
+	select {
+	case c.renders <- struct{}{}:
+		defer func() { <-c.renders }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	render := RenderPNG
+	if format == FormatSVG {
+		render = RenderSVG
+	}
+
+	var buf bytes.Buffer
+	if err := render(ctx, &buf, quote.Text, quote.Author, opts); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func quoteDigest(quote *entity.Quote) string {
+	sum := sha256.Sum256([]byte(quote.Author + "\x00" + quote.Text))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,454 @@
+package card
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// font is a minimal TrueType reader: enough of cmap, hmtx and glyf to lay out
+// and draw unhinted outlines. Kerning and OpenType layout are not supported.
+type font struct {
+	data       []byte
+	unitsPerEm int
+	ascent     int
+	descent    int
+	lineGap    int
+	numGlyphs  int
+	numMetrics int
+	longLoca   bool
+
+	glyf, loca, hmtx []byte
+	cmap             func(r rune) uint16
+	// tables holds every table by tag, for subsetting.
+	tables map[string][]byte
+}
+
+// point is an outline point in font units. Off-curve points are quadratic
+// Bézier control points.
+type point struct {
+	x, y    float64
+	onCurve bool
+}
+
+var errMalformedFont = errors.New("malformed font")
+
+func parseFont(data []byte) (*font, error) {
+	if len(data) < 12 {
+		return nil, errMalformedFont
+	}
+
+	tables := make(map[string][]byte)
+	numTables := int(u16(data, 4))
+	for i := range numTables {
+		record := 12 + 16*i
+		if record+16 > len(data) {
+			return nil, errMalformedFont
+		}
+		tag := string(data[record : record+4])
+		offset, length := int(u32(data, record+8)), int(u32(data, record+12))
+		if offset+length > len(data) {
+			return nil, fmt.Errorf("%w: table %s out of bounds", errMalformedFont, tag)
+		}
+		tables[tag] = data[offset : offset+length]
+	}
+
+	for _, tag := range []string{"head", "hhea", "maxp", "cmap", "hmtx", "loca", "glyf"} {
+		if _, ok := tables[tag]; !ok {
+			return nil, fmt.Errorf("%w: missing %s table", errMalformedFont, tag)
+		}
+	}
+
+	head, hhea, maxp := tables["head"], tables["hhea"], tables["maxp"]
+	if len(head) < 54 || len(hhea) < 36 || len(maxp) < 6 {
+		return nil, errMalformedFont
+	}
+
+	f := &font{
+		data:       data,
+		unitsPerEm: int(u16(head, 18)),
+		longLoca:   u16(head, 50) != 0,
+		ascent:     int(int16(u16(hhea, 4))),
+		descent:    int(int16(u16(hhea, 6))),
+		lineGap:    int(int16(u16(hhea, 8))),
+		numMetrics: int(u16(hhea, 34)),
+		numGlyphs:  int(u16(maxp, 4)),
+		glyf:       tables["glyf"],
+		loca:       tables["loca"],
+		hmtx:       tables["hmtx"],
+		tables:     tables,
+	}
+	if f.unitsPerEm == 0 || f.numMetrics == 0 || len(f.hmtx) < 4*f.numMetrics {
+		return nil, errMalformedFont
+	}
+
+	cmap, err := parseCmap(tables["cmap"])
+	if err != nil {
+		return nil, err
+	}
+	f.cmap = cmap
+	return f, nil
+}
+
+// parseCmap picks a Unicode subtable, preferring the full-repertoire format
+// 12 over the BMP-only format 4.
+func parseCmap(data []byte) (func(rune) uint16, error) {
+	if len(data) < 4 {
+		return nil, errMalformedFont
+	}
+
+	var format4, format12 []byte
+	for i := range int(u16(data, 2)) {
+		record := 4 + 8*i
+		if record+8 > len(data) {
+			return nil, errMalformedFont
+		}
+		platform, encoding := u16(data, record), u16(data, record+2)
+		offset := int(u32(data, record+4))
+		if offset+4 > len(data) {
+			return nil, errMalformedFont
+		}
+		unicode := platform == 0 || (platform == 3 && (encoding == 1 || encoding == 10))
+		if !unicode {
+			continue
+		}
+		switch u16(data, offset) {
+		case 4:
+			format4 = data[offset:]
+		case 12:
+			format12 = data[offset:]
+		}
+	}
+
+	switch {
+	case format12 != nil:
+		return cmapFormat12(format12)
+	case format4 != nil:
+		return cmapFormat4(format4)
+	default:
+		return nil, fmt.Errorf("%w: no Unicode cmap", errMalformedFont)
+	}
+}
+
+func cmapFormat4(data []byte) (func(rune) uint16, error) {
+	if len(data) < 14 {
+		return nil, errMalformedFont
+	}
+	segments := int(u16(data, 6)) / 2
+	endCodes := 14
+	startCodes := endCodes + 2*segments + 2
+	deltas := startCodes + 2*segments
+	rangeOffsets := deltas + 2*segments
+	if rangeOffsets+2*segments > len(data) {
+		return nil, errMalformedFont
+	}
+
+	return func(r rune) uint16 {
+		if r > 0xffff {
+			return 0
+		}
+		c := uint16(r)
+		for i := range segments {
+			if c > u16(data, endCodes+2*i) {
+				continue
+			}
+			if c < u16(data, startCodes+2*i) {
+				return 0
+			}
+			delta := u16(data, deltas+2*i)
+			rangeOffset := int(u16(data, rangeOffsets+2*i))
+			if rangeOffset == 0 {
+				return c + delta
+			}
+			index := rangeOffsets + 2*i + rangeOffset + 2*int(c-u16(data, startCodes+2*i))
+			if index+2 > len(data) {
+				return 0
+			}
+			if glyph := u16(data, index); glyph != 0 {
+				return glyph + delta
+			}
+			return 0
+		}
+		return 0
+	}, nil
+}
+
+func cmapFormat12(data []byte) (func(rune) uint16, error) {
+	if len(data) < 16 {
+		return nil, errMalformedFont
+	}
+	groups := int(u32(data, 12))
+	if 16+12*groups > len(data) {
+		return nil, errMalformedFont
+	}
+
+	return func(r rune) uint16 {
+		c := uint32(r)
+		lo, hi := 0, groups
+		for lo < hi {
+			mid := (lo + hi) / 2
+			group := 16 + 12*mid
+			start, end := u32(data, group), u32(data, group+4)
+			switch {
+			case c < start:
+				hi = mid
+			case c > end:
+				lo = mid + 1
+			default:
+				return uint16(u32(data, group+8) + c - start)
+			}
+		}
+		return 0
+	}, nil
+}
+
+func (f *font) index(r rune) uint16 {
+	return f.cmap(r)
+}
+
+// advance returns the advance width of a glyph in font units.
+func (f *font) advance(glyph uint16) int {
+	i := min(int(glyph), f.numMetrics-1)
+	return int(u16(f.hmtx, 4*i))
+}
+
+func (f *font) glyphData(glyph uint16) ([]byte, error) {
+	g := int(glyph)
+	if g >= f.numGlyphs {
+		return nil, fmt.Errorf("%w: glyph %d out of range", errMalformedFont, g)
+	}
+
+	var start, end int
+	if f.longLoca {
+		if 4*g+8 > len(f.loca) {
+			return nil, errMalformedFont
+		}
+		start, end = int(u32(f.loca, 4*g)), int(u32(f.loca, 4*g+4))
+	} else {
+		if 2*g+4 > len(f.loca) {
+			return nil, errMalformedFont
+		}
+		start, end = 2*int(u16(f.loca, 2*g)), 2*int(u16(f.loca, 2*g+2))
+	}
+	if start > end || end > len(f.glyf) {
+		return nil, errMalformedFont
+	}
+	return f.glyf[start:end], nil
+}
+
+// contours returns the outline of a glyph, resolving composite glyphs.
+func (f *font) contours(glyph uint16) ([][]point, error) {
+	return f.contoursDepth(glyph, 0)
+}
+
+const maxCompositeDepth = 8
+
+func (f *font) contoursDepth(glyph uint16, depth int) ([][]point, error) {
+	if depth > maxCompositeDepth {
+		return nil, fmt.Errorf("%w: composite glyphs nested too deep", errMalformedFont)
+	}
+
+	data, err := f.glyphData(glyph)
+	if err != nil || len(data) == 0 {
+		return nil, err
+	}
+	if len(data) < 10 {
+		return nil, errMalformedFont
+	}
+
+	numContours := int(int16(u16(data, 0)))
+	if numContours >= 0 {
+		return simpleContours(data, numContours)
+	}
+	return f.compositeContours(data, depth)
+}
+
+const (
+	flagOnCurve      = 0x01
+	flagXShort       = 0x02
+	flagYShort       = 0x04
+	flagRepeat       = 0x08
+	flagXSameOrPlus  = 0x10
+	flagYSameOrPlus  = 0x20
+	componentWords   = 0x0001
+	componentXY      = 0x0002
+	componentScale   = 0x0008
+	componentMore    = 0x0020
+	componentXYScale = 0x0040
+	component2x2     = 0x0080
+)
+
+func simpleContours(data []byte, numContours int) ([][]point, error) {
+	offset := 10
+	if offset+2*numContours+2 > len(data) {
+		return nil, errMalformedFont
+	}
+
+	ends := make([]int, numContours)
+	for i := range ends {
+		ends[i] = int(u16(data, offset+2*i))
+	}
+	offset += 2 * numContours
+	numPoints := 0
+	if numContours > 0 {
+		numPoints = ends[numContours-1] + 1
+	}
+
+	offset += 2 + int(u16(data, offset))
+	flags := make([]byte, 0, numPoints)
+	for len(flags) < numPoints {
+		if offset >= len(data) {
+			return nil, errMalformedFont
+		}
+		flag := data[offset]
+		offset++
+		flags = append(flags, flag)
+		if flag&flagRepeat != 0 {
+			if offset >= len(data) {
+				return nil, errMalformedFont
+			}
+			for range data[offset] {
+				flags = append(flags, flag)
+			}
+			offset++
+		}
+	}
+	flags = flags[:numPoints]
+
+	points := make([]point, numPoints)
+	var err error
+	if offset, err = readCoordinates(data, offset, flags, flagXShort, flagXSameOrPlus, func(i int, v float64) { points[i].x = v }); err != nil {
+		return nil, err
+	}
+	if _, err = readCoordinates(data, offset, flags, flagYShort, flagYSameOrPlus, func(i int, v float64) { points[i].y = v }); err != nil {
+		return nil, err
+	}
+	for i, flag := range flags {
+		points[i].onCurve = flag&flagOnCurve != 0
+	}
+
+	contours := make([][]point, 0, numContours)
+	start := 0
+	for _, end := range ends {
+		if end < start || end >= numPoints {
+			return nil, errMalformedFont
+		}
+		contours = append(contours, points[start:end+1])
+		start = end + 1
+	}
+	return contours, nil
+}
+
+func readCoordinates(data []byte, offset int, flags []byte, short, sameOrPlus byte, set func(int, float64)) (int, error) {
+	value := 0
+	for i, flag := range flags {
+		switch {
+		case flag&short != 0:
+			if offset >= len(data) {
+				return 0, errMalformedFont
+			}
+			delta := int(data[offset])
+			offset++
+			if flag&sameOrPlus == 0 {
+				delta = -delta
+			}
+			value += delta
+		case flag&sameOrPlus == 0:
+			if offset+2 > len(data) {
+				return 0, errMalformedFont
+			}
+			value += int(int16(u16(data, offset)))
+			offset += 2
+		}
+		set(i, float64(value))
+	}
+	return offset, nil
+}
+
+func (f *font) compositeContours(data []byte, depth int) ([][]point, error) {
+	var contours [][]point
+	offset := 10
+	for {
+		if offset+4 > len(data) {
+			return nil, errMalformedFont
+		}
+		flags := u16(data, offset)
+		component := u16(data, offset+2)
+		offset += 4
+
+		var dx, dy float64
+		if flags&componentWords != 0 {
+			if offset+4 > len(data) {
+				return nil, errMalformedFont
+			}
+			dx, dy = float64(int16(u16(data, offset))), float64(int16(u16(data, offset+2)))
+			offset += 4
+		} else {
+			if offset+2 > len(data) {
+				return nil, errMalformedFont
+			}
+			dx, dy = float64(int8(data[offset])), float64(int8(data[offset+1]))
+			offset += 2
+		}
+		if flags&componentXY == 0 {
+			// Components positioned by matching points are rare in text
+			// fonts and are drawn unshifted.
+			dx, dy = 0, 0
+		}
+
+		a, b, c, d := 1.0, 0.0, 0.0, 1.0
+		switch {
+		case flags&componentScale != 0:
+			if offset+2 > len(data) {
+				return nil, errMalformedFont
+			}
+			a = f2dot14(data, offset)
+			d = a
+			offset += 2
+		case flags&componentXYScale != 0:
+			if offset+4 > len(data) {
+				return nil, errMalformedFont
+			}
+			a, d = f2dot14(data, offset), f2dot14(data, offset+2)
+			offset += 4
+		case flags&component2x2 != 0:
+			if offset+8 > len(data) {
+				return nil, errMalformedFont
+			}
+			a, b, c, d = f2dot14(data, offset), f2dot14(data, offset+2), f2dot14(data, offset+4), f2dot14(data, offset+6)
+			offset += 8
+		}
+
+		parts, err := f.contoursDepth(component, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		for _, part := range parts {
+			transformed := make([]point, len(part))
+			for i, p := range part {
+				transformed[i] = point{
+					x:       a*p.x + c*p.y + dx,
+					y:       b*p.x + d*p.y + dy,
+					onCurve: p.onCurve,
+				}
+			}
+			contours = append(contours, transformed)
+		}
+
+		if flags&componentMore == 0 {
+			return contours, nil
+		}
+	}
+}
+
+func u16(b []byte, offset int) uint16 {
+	return binary.BigEndian.Uint16(b[offset:])
+}
+
+func u32(b []byte, offset int) uint32 {
+	return binary.BigEndian.Uint32(b[offset:])
+}
+
+func f2dot14(b []byte, offset int) float64 {
+	return float64(int16(u16(b, offset))) / (1 << 14)
+}
@@ -0,0 +1,191 @@
+package card
+
+import (
+	"image"
+	"math"
+)
+
+// rasterizer fills outlines into an anti-aliased alpha mask. Each line
+// segment adds its signed area coverage to an accumulation buffer; a running
+// sum over the buffer then yields the coverage of every pixel. The sum runs
+// across row boundaries, which is harmless because every closed contour
+// contributes zero to each row in total.
+type rasterizer struct {
+	width, height int
+	acc           []float32
+}
+
+func newRasterizer(width, height int) *rasterizer {
+	return &rasterizer{width: width, height: height, acc: make([]float32, width*height+4)}
+}
+
+type vec struct {
+	x, y float64
+}
+
+func (r *rasterizer) line(p0, p1 vec) {
+	if p0.y == p1.y {
+		return
+	}
+
+	dir := float32(1)
+	if p0.y > p1.y {
+		dir = -1
+		p0, p1 = p1, p0
+	}
+	dxdy := (p1.x - p0.x) / (p1.y - p0.y)
+	x := p0.x
+	if p0.y < 0 {
+		x -= p0.y * dxdy
+	}
+
+	for y := max(0, int(p0.y)); y < min(r.height, int(math.Ceil(p1.y))); y++ {
+		rowStart := y * r.width
+		dy := math.Min(float64(y+1), p1.y) - math.Max(float64(y), p0.y)
+		xNext := x + dxdy*dy
+		d := float32(dy) * dir
+
+		x0, x1 := x, xNext
+		if x0 > x1 {
+			x0, x1 = x1, x0
+		}
+		x0 = math.Max(x0, 0)
+		x1 = math.Min(x1, float64(r.width))
+		x0Floor := math.Floor(x0)
+		x0i := int(x0Floor)
+		x1Ceil := math.Ceil(x1)
+		x1i := int(x1Ceil)
+
+		if x1i <= x0i+1 {
+			xmf := float32(0.5*(x0+x1) - x0Floor)
+			r.add(rowStart+x0i, d-d*xmf)
+			r.add(rowStart+x0i+1, d*xmf)
+		} else {
+			s := float32(1 / (x1 - x0))
+			x0f := float32(x0 - x0Floor)
+			a0 := 0.5 * s * (1 - x0f) * (1 - x0f)
+			x1f := float32(x1 - x1Ceil + 1)
+			am := 0.5 * s * x1f * x1f
+			r.add(rowStart+x0i, d*a0)
+			if x1i == x0i+2 {
+				r.add(rowStart+x0i+1, d*(1-a0-am))
+			} else {
+				a1 := s * (1.5 - x0f)
+				r.add(rowStart+x0i+1, d*(a1-a0))
+				for xi := x0i + 2; xi < x1i-1; xi++ {
+					r.add(rowStart+xi, d*s)
+				}
+				a2 := a1 + float32(x1i-x0i-3)*s
+				r.add(rowStart+x1i-1, d*(1-a2-am))
+			}
+			r.add(rowStart+x1i, d*am)
+		}
+		x = xNext
+	}
+}
+
+func (r *rasterizer) add(i int, v float32) {
+	if i >= 0 && i < len(r.acc) {
+		r.acc[i] += v
+	}
+}
+
+// quad flattens a quadratic Bézier curve into line segments short enough
+// not to be told apart from the curve.
+func (r *rasterizer) quad(p0, p1, p2 vec) {
+	devX := p0.x - 2*p1.x + p2.x
+	devY := p0.y - 2*p1.y + p2.y
+	devSq := devX*devX + devY*devY
+	if devSq < 0.333 {
+		r.line(p0, p2)
+		return
+	}
+
+	const tolerance = 3.0
+	n := 1 + int(math.Sqrt(math.Sqrt(tolerance*devSq)))
+	prev := p0
+	for i := 1; i <= n; i++ {
+		t := float64(i) / float64(n)
+		mt := 1 - t
+		next := vec{
+			x: mt*mt*p0.x + 2*mt*t*p1.x + t*t*p2.x,
+			y: mt*mt*p0.y + 2*mt*t*p1.y + t*t*p2.y,
+		}
+		r.line(prev, next)
+		prev = next
+	}
+}
+
+// contour draws a TrueType contour, inserting the on-curve points implied
+// between consecutive off-curve points.
+func (r *rasterizer) contour(points []point, transform func(point) vec) {
+	n := len(points)
+	if n == 0 {
+		return
+	}
+
+	start := 0
+	for start < n && !points[start].onCurve {
+		start++
+	}
+
+	var first vec
+	if start == n {
+		first = midpoint(transform(points[0]), transform(points[1%n]))
+		start = 0
+	} else {
+		first = transform(points[start])
+	}
+
+	current := first
+	var control *vec
+	for i := 1; i <= n; i++ {
+		p := points[(start+i)%n]
+		v := transform(p)
+		switch {
+		case p.onCurve && control == nil:
+			r.line(current, v)
+			current = v
+		case p.onCurve:
+			r.quad(current, *control, v)
+			control = nil
+			current = v
+		case control == nil:
+			c := v
+			control = &c
+		default:
+			mid := midpoint(*control, v)
+			r.quad(current, *control, mid)
+			current = mid
+			c := v
+			control = &c
+		}
+	}
+
+	if control != nil {
+		r.quad(current, *control, first)
+	} else if current != first {
+		r.line(current, first)
+	}
+}
+
+func midpoint(a, b vec) vec {
+	return vec{x: (a.x + b.x) / 2, y: (a.y + b.y) / 2}
+}
+
+func (r *rasterizer) mask() *image.Alpha {
+	mask := image.NewAlpha(image.Rect(0, 0, r.width, r.height))
+	var sum float32
+	for i := range r.width * r.height {
+		sum += r.acc[i]
+		coverage := sum
+		if coverage < 0 {
+			coverage = -coverage
+		}
+		if coverage > 1 {
+			coverage = 1
+		}
+		mask.Pix[i] = uint8(coverage*255 + 0.5)
+	}
+	return mask
+}
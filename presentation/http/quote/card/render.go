@@ -0,0 +1,119 @@
+package card
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"slices"
+	"strings"
+)
+
+func RenderPNG(ctx context.Context, w io.Writer, text, author string, opts Options) error {
+	f, err := loadFont()
+	if err != nil {
+		return err
+	}
+	l, err := newLayout(ctx, f, text, author, opts.Size)
+	if err != nil {
+		return err
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, l.size.Width, l.size.Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(opts.Theme.Background), image.Point{}, draw.Src)
+
+	textColor := image.NewUniform(opts.Theme.Text)
+	for i, line := range l.lines {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := drawText(img, f, line, l.x, l.firstLine+float64(i)*l.lineHeight, l.textSize, textColor); err != nil {
+			return err
+		}
+	}
+	if err := drawText(img, f, l.author, l.x, l.authorLine, l.authorSize, image.NewUniform(opts.Theme.Accent)); err != nil {
+		return err
+	}
+
+	encoder := png.Encoder{CompressionLevel: png.DefaultCompression}
+	return encoder.Encode(w, img)
+}
+
+// drawText rasterizes s with its baseline starting at (x, y).
+func drawText(dst draw.Image, f *font, s string, x, y, size float64, src image.Image) error {
+	scale := size / float64(f.unitsPerEm)
+	ascent := float64(f.ascent) * scale
+	descent := float64(-f.descent) * scale
+
+	originX, originY := math.Floor(x), math.Floor(y-ascent)
+	offsetX, offsetY := x-originX+1, y-originY+1
+	width := int(math.Ceil(measure(f, s, size)+offsetX)) + 2
+	height := int(math.Ceil(ascent+descent)) + 3
+	raster := newRasterizer(width, height)
+
+	pen := offsetX
+	for _, r := range s {
+		glyph := f.index(r)
+		contours, err := f.contours(glyph)
+		if err != nil {
+			return err
+		}
+		transform := func(p point) vec {
+			return vec{x: pen + p.x*scale, y: offsetY - p.y*scale}
+		}
+		for _, contour := range contours {
+			raster.contour(contour, transform)
+		}
+		pen += float64(f.advance(glyph)) * scale
+	}
+
+	origin := image.Pt(int(originX)-1, int(originY)-1)
+	draw.DrawMask(dst, image.Rectangle{Min: origin, Max: origin.Add(image.Pt(width, height))}, src, image.Point{}, raster.mask(), image.Point{}, draw.Over)
+	return nil
+}
+
+// RenderSVG lays out the card as SVG text. The glyphs it uses are embedded
+// as a font subset, so viewers draw the text as laid out.
+func RenderSVG(ctx context.Context, w io.Writer, text, author string, opts Options) error {
+	f, err := loadFont()
+	if err != nil {
+		return err
+	}
+	l, err := newLayout(ctx, f, text, author, opts.Size)
+	if err != nil {
+		return err
+	}
+	subset, err := f.subset(append(slices.Clone(l.lines), l.author)...)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		l.size.Width, l.size.Height, l.size.Width, l.size.Height)
+	fmt.Fprintf(&b, "  <style>@font-face { font-family: '%s'; src: url(data:font/ttf;base64,%s) format('truetype'); }</style>\n",
+		embeddedFontFamily, base64.StdEncoding.EncodeToString(subset))
+	fmt.Fprintf(&b, `  <rect width="100%%" height="100%%" fill="%s"/>`+"\n", cssColor(opts.Theme.Background))
+	fmt.Fprintf(&b, `  <text font-family="%s" font-size="%.1f" fill="%s">`+"\n", fontFamily, l.textSize, cssColor(opts.Theme.Text))
+	for i, line := range l.lines {
+		fmt.Fprintf(&b, `    <tspan x="%.1f" y="%.1f">%s</tspan>`+"\n", l.x, l.firstLine+float64(i)*l.lineHeight, escape(line))
+	}
+	b.WriteString("  </text>\n")
+	fmt.Fprintf(&b, `  <text font-family="%s" font-size="%.1f" fill="%s" x="%.1f" y="%.1f">%s</text>`+"\n",
+		fontFamily, l.authorSize, cssColor(opts.Theme.Accent), l.x, l.authorLine, escape(l.author))
+	b.WriteString("</svg>\n")
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
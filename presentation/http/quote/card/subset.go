@@ -0,0 +1,185 @@
+package card
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"slices"
+)
+
+// subsetTables are the tables kept in a subset: the outlines and metrics,
+// the hinting programs the outlines refer to, and the names and OS/2
+// metrics browsers require. Layout tables such as GPOS and kern are not
+// used by the renderers and are dropped, and post is reduced to its header.
+var subsetTables = []string{"OS/2", "cmap", "cvt ", "fpgm", "gasp", "glyf", "head", "hhea", "hmtx", "loca", "maxp", "name", "post", "prep"}
+
+// subset returns a TrueType font with the outlines of the glyphs of texts
+// only. Glyph IDs are kept, so cmap, hmtx and composite glyphs stay valid;
+// the outlines of the other glyphs are left empty.
+func (f *font) subset(texts ...string) ([]byte, error) {
+	keep := make(map[uint16]bool)
+	if err := f.keepGlyph(keep, 0, 0); err != nil {
+		return nil, err
+	}
+	for _, text := range texts {
+		for _, r := range text {
+			if err := f.keepGlyph(keep, f.index(r), 0); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var glyf []byte
+	offsets := make([]int, f.numGlyphs+1)
+	for g := range f.numGlyphs {
+		offsets[g] = len(glyf)
+		if !keep[uint16(g)] {
+			continue
+		}
+		data, err := f.glyphData(uint16(g))
+		if err != nil {
+			return nil, err
+		}
+		glyf = append(glyf, data...)
+		// Glyphs start at even offsets for the short loca format.
+		for len(glyf)%4 != 0 {
+			glyf = append(glyf, 0)
+		}
+	}
+	offsets[f.numGlyphs] = len(glyf)
+
+	longLoca := len(glyf)/2 > 0xffff
+	var loca []byte
+	for _, offset := range offsets {
+		if longLoca {
+			loca = binary.BigEndian.AppendUint32(loca, uint32(offset))
+		} else {
+			loca = binary.BigEndian.AppendUint16(loca, uint16(offset/2))
+		}
+	}
+
+	head := slices.Clone(f.tables["head"])
+	binary.BigEndian.PutUint32(head[8:], 0)
+	binary.BigEndian.PutUint16(head[50:], 0)
+	if longLoca {
+		binary.BigEndian.PutUint16(head[50:], 1)
+	}
+
+	tables := map[string][]byte{"glyf": glyf, "loca": loca, "head": head}
+	if post := f.tables["post"]; len(post) >= 32 {
+		post = slices.Clone(post[:32])
+		binary.BigEndian.PutUint32(post, 0x00030000)
+		tables["post"] = post
+	}
+	for _, tag := range subsetTables {
+		if _, ok := tables[tag]; !ok && f.tables[tag] != nil {
+			tables[tag] = f.tables[tag]
+		}
+	}
+
+	data := writeFont(tables)
+	// checkSumAdjustment makes the whole font sum to a fixed value.
+	headOffset := tableOffset(data, "head")
+	binary.BigEndian.PutUint32(data[headOffset+8:], 0xb1b0afba-checksum(data))
+	return data, nil
+}
+
+// keepGlyph marks glyph and, for composite glyphs, their components.
+func (f *font) keepGlyph(keep map[uint16]bool, glyph uint16, depth int) error {
+	if keep[glyph] {
+		return nil
+	}
+	if depth > maxCompositeDepth {
+		return fmt.Errorf("%w: composite glyphs nested too deep", errMalformedFont)
+	}
+	keep[glyph] = true
+
+	data, err := f.glyphData(glyph)
+	if err != nil || len(data) < 10 || int16(u16(data, 0)) >= 0 {
+		return err
+	}
+	offset := 10
+	for {
+		if offset+4 > len(data) {
+			return errMalformedFont
+		}
+		flags, component := u16(data, offset), u16(data, offset+2)
+		if err := f.keepGlyph(keep, component, depth+1); err != nil {
+			return err
+		}
+		offset += 4
+		if flags&componentWords != 0 {
+			offset += 4
+		} else {
+			offset += 2
+		}
+		switch {
+		case flags&componentScale != 0:
+			offset += 2
+		case flags&componentXYScale != 0:
+			offset += 4
+		case flags&component2x2 != 0:
+			offset += 8
+		}
+		if flags&componentMore == 0 {
+			return nil
+		}
+	}
+}
+
+// writeFont lays out an sfnt file with tables in tag order.
+func writeFont(tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	slices.Sort(tags)
+
+	n := len(tags)
+	entrySelector := bits.Len(uint(n)) - 1
+	searchRange := 16 << entrySelector
+
+	data := binary.BigEndian.AppendUint32(nil, 0x00010000)
+	data = binary.BigEndian.AppendUint16(data, uint16(n))
+	data = binary.BigEndian.AppendUint16(data, uint16(searchRange))
+	data = binary.BigEndian.AppendUint16(data, uint16(entrySelector))
+	data = binary.BigEndian.AppendUint16(data, uint16(16*n-searchRange))
+
+	offset := 12 + 16*n
+	for _, tag := range tags {
+		table := tables[tag]
+		data = append(data, tag...)
+		data = binary.BigEndian.AppendUint32(data, checksum(table))
+		data = binary.BigEndian.AppendUint32(data, uint32(offset))
+		data = binary.BigEndian.AppendUint32(data, uint32(len(table)))
+		offset += (len(table) + 3) &^ 3
+	}
+	for _, tag := range tags {
+		data = append(data, tables[tag]...)
+		for len(data)%4 != 0 {
+			data = append(data, 0)
+		}
+	}
+	return data
+}
+
+func tableOffset(data []byte, tag string) int {
+	for i := range int(u16(data, 4)) {
+		record := 12 + 16*i
+		if string(data[record:record+4]) == tag {
+			return int(u32(data, record+8))
+		}
+	}
+	return -1
+}
+
+// checksum sums data as big-endian uint32 words, zero-padding the last one.
+func checksum(data []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(data); i += 4 {
+		var word [4]byte
+		copy(word[:], data[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
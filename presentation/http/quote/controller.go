@@ -1,121 +1,362 @@
 package quote
 
 import (
-	"encoding/json"
 	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"strconv"
-	"strings"
+	"time"
 
 	utils "github.com/Korjick/go-http-quote/presentation/http"
 
 	"github.com/Korjick/go-http-quote/application/service"
+	"github.com/Korjick/go-http-quote/domain/apperror"
 	"github.com/Korjick/go-http-quote/domain/quote/entity"
+	"github.com/Korjick/go-http-quote/presentation/http/i18n"
+	"github.com/Korjick/go-http-quote/presentation/http/quote/bulk"
+	"github.com/Korjick/go-http-quote/presentation/http/quote/card"
 	"github.com/Korjick/go-http-quote/presentation/http/quote/dto"
+	"github.com/Korjick/go-http-quote/presentation/http/quote/feed"
+	"github.com/Korjick/go-http-quote/presentation/http/quote/page"
 )
 
+const (
+	DefaultRequestTimeout = 10 * time.Second
+	DefaultBulkTimeout    = 5 * time.Minute
+
+	// ExportStatusTrailer ends an export with "complete", "snapshot_changed"
+	// when quotes of the snapshot were deleted meanwhile, or "failed".
+	ExportStatusTrailer = "X-Export-Status"
+)
+
+// Timeouts bound the time the service may spend on a request: Request for
+// most routes and Bulk for import and export, which go through the whole
+// collection. Zero disables a timeout.
+type Timeouts struct {
+	Request time.Duration
+	Bulk    time.Duration
+}
+
+type options struct {
+	timeouts  Timeouts
+	publicURL string
+	// cards is shared by the controllers of every version, so they render
+	// and cache each card once.
+	cards *card.Cards
+}
+
+type Option func(*options)
+
+// WithTimeouts replaces the default timeouts, DefaultRequestTimeout and
+// DefaultBulkTimeout.
+func WithTimeouts(timeouts Timeouts) Option {
+	return func(o *options) {
+		o.timeouts = timeouts
+	}
+}
+
+// WithPublicURL sets the URL the service is reached at, such as
+// https://quotes.example.com, for the links of the feeds. The default is
+// feed.DefaultBaseURL.
+func WithPublicURL(url string) Option {
+	return func(o *options) {
+		o.publicURL = url
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		timeouts:  Timeouts{Request: DefaultRequestTimeout, Bulk: DefaultBulkTimeout},
+		publicURL: feed.DefaultBaseURL,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
 type Controller struct {
-	service *service.QuoteService
-	prefix  string
+	service  *service.QuoteService
+	prefix   string
+	mapper   dto.Mapper
+	timeouts Timeouts
+	pages    *page.Pages
+	feeds    *feed.Feeds
+	cards    *card.Cards
+	router   *utils.Router
+}
+
+func NewQuoteController(service *service.QuoteService, prefix string, opts ...Option) *Controller {
+	o := newOptions(opts)
+	o.cards = card.NewCards(service)
+	return newController(service, prefix, dto.MapperFor(dto.DefaultVersion), o)
 }
 
-func NewQuoteController(service *service.QuoteService, prefix string) *Controller {
+func newController(service *service.QuoteService, prefix string, mapper dto.Mapper, o options) *Controller {
 	controller := &Controller{
-		service: service,
-		prefix:  prefix,
+		service:  service,
+		prefix:   prefix,
+		mapper:   mapper,
+		timeouts: o.timeouts,
+		pages:    page.NewPages(service, prefix),
+		feeds:    feed.NewFeeds(service, prefix, o.publicURL),
+		cards:    o.cards,
+		router:   utils.NewRouter(),
 	}
+	controller.registerRoutes()
 	return controller
 }
 
+func (h *Controller) registerRoutes() {
+	h.handle(http.MethodGet, h.prefix, h.timeouts.Request, htmlOr(h.pages.List, utils.Negotiated(h.getQuotes)))
+	h.handle(http.MethodPost, h.prefix, h.timeouts.Request, utils.Negotiated(h.createQuote))
+	h.handle(http.MethodGet, h.prefix+"/random", h.timeouts.Request, htmlOr(h.pages.Random, utils.Negotiated(h.getRandomQuote)))
+	h.handle(http.MethodGet, h.prefix+"/authors/{author}", h.timeouts.Request, htmlOr(h.pages.Author, utils.Negotiated(h.getAuthorQuotes)))
+	h.handle(http.MethodGet, h.prefix+"/{id}", h.timeouts.Request, htmlOr(h.pages.Detail, utils.Negotiated(h.getQuote)))
+	h.handle(http.MethodGet, h.prefix+"/{id}/card.png", h.timeouts.Request, h.quoteCard(card.FormatPNG))
+	h.handle(http.MethodGet, h.prefix+"/{id}/card.svg", h.timeouts.Request, h.quoteCard(card.FormatSVG))
+	h.handle(http.MethodGet, h.prefix+"/export", h.timeouts.Bulk, h.exportQuotes)
+	h.handle(http.MethodGet, h.prefix+"/feed.rss", h.timeouts.Request, h.feeds.RSS)
+	h.handle(http.MethodGet, h.prefix+"/feed.atom", h.timeouts.Request, h.feeds.Atom)
+	h.handle(http.MethodPost, h.prefix+"/import", h.timeouts.Bulk, utils.Negotiated(h.importQuotes))
+	h.handle(http.MethodPost, h.prefix+"/batch", h.timeouts.Request, utils.Negotiated(h.batchQuotes))
+	h.handle(http.MethodDelete, h.prefix+"/{id}", h.timeouts.Request, utils.Negotiated(h.deleteQuote))
+
+	h.router.NotFound = htmlOr(h.pages.NotFound, func(w http.ResponseWriter, r *http.Request) {
+		utils.WriteProblem(w, r, utils.LocalizedProblem(r, http.StatusNotFound, i18n.CodeRouteNotFound))
+	})
+	h.router.MethodNotAllowed = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		problem := utils.LocalizedProblem(r, http.StatusMethodNotAllowed, i18n.CodeMethodNotAllowed)
+		problem.Detail += fmt.Sprintf(": %s (%s)", r.Method, w.Header().Get("Allow"))
+		utils.WriteProblem(w, r, problem)
+	})
+}
+
+// handle registers a route whose context expires after timeout.
+func (h *Controller) handle(method, pattern string, timeout time.Duration, handler http.HandlerFunc) {
+	h.router.HandleFunc(method, pattern, utils.WithTimeout(timeout, handler))
+}
+
+func (h *Controller) Routes() []string {
+	return h.router.Routes()
+}
+
 func (h *Controller) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	r.URL.Path = strings.TrimPrefix(r.URL.Path, h.prefix)
-	switch r.Method {
-	case http.MethodPost:
-		h.createQuote(w, r)
-	case http.MethodGet:
-		switch {
-		case strings.HasPrefix(r.URL.Path, "/random"):
-			h.getRandomQuote(w, r)
-		default:
-			h.getQuotes(w, r)
+	h.router.ServeHTTP(w, r)
+}
+
+// htmlOr serves the page to browsers and the API response to everyone else.
+func htmlOr(page, api http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		utils.AddVary(w.Header(), "Accept")
+		if utils.AcceptsHTML(r) {
+			page(w, r)
+			return
 		}
-	case http.MethodDelete:
-		h.deleteQuote(w, r)
-	default:
-		h.handleDomainError(w, errors.ErrUnsupported)
+		api(w, r)
 	}
 }
 
-func (h *Controller) handleDomainError(w http.ResponseWriter, err error) {
-	switch {
-	case errors.Is(err, entity.ErrEmptyAuthor), errors.Is(err, entity.ErrEmptyText), errors.Is(err, entity.ErrQuoteNotFound):
-		utils.WriteJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: err.Error()})
-	default:
-		utils.WriteJSON(w, http.StatusInternalServerError, dto.ErrorResponse{Error: http.StatusText(http.StatusInternalServerError)})
-	}
+func (h *Controller) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	utils.WriteError(w, r, err)
 }
 
 func (h *Controller) createQuote(w http.ResponseWriter, r *http.Request) {
-	var req dto.CreateQuoteRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		utils.WriteJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: http.StatusText(http.StatusBadRequest)})
+	req := h.mapper.CreateRequest()
+	if err := utils.DecodeJSON(w, r, req); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	draft := req.Draft()
+	quote, err := h.service.CreateQuote(r.Context(), utils.PrincipalFrom(r), draft.Author, draft.Text)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response := h.mapper.Quote(quote)
+	utils.WriteResponse(w, r, http.StatusCreated, response)
+}
+
+func (h *Controller) importQuotes(w http.ResponseWriter, r *http.Request) {
+	mode, err := service.ParseImportMode(r.URL.Query().Get("mode"))
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	src, err := bulk.NewSource(r.Header.Get("Content-Type"), r.Body, h.mapper)
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	report, err := h.service.ImportQuotes(r.Context(), utils.PrincipalFrom(r), src, mode)
+	if err != nil {
+		h.handleError(w, r, err)
 		return
 	}
 
-	quote, err := h.service.CreateQuote(req.Author, req.Quote)
+	status := http.StatusOK
+	if mode == service.ImportModeAtomic && report.Failed() > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+	utils.WriteResponse(w, r, status, dto.ImportReportToDTO(report, i18n.FromRequest(r)))
+}
+
+func (h *Controller) batchQuotes(w http.ResponseWriter, r *http.Request) {
+	req := h.mapper.BatchRequest()
+	if err := utils.DecodeJSON(w, r, req); err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	ops, err := req.ToOperations()
 	if err != nil {
-		h.handleDomainError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
-	response := dto.EntityToDTO(quote)
-	utils.WriteJSON(w, http.StatusCreated, response)
+	results, err := h.service.ExecuteBatch(r.Context(), utils.PrincipalFrom(r), ops)
+	switch {
+	case errors.Is(err, entity.ErrBatchAborted):
+		utils.WriteResponse(w, r, utils.StatusForKind(apperror.KindOf(err)), h.mapper.BatchResults(ops, results, false, i18n.FromRequest(r)))
+	case err != nil:
+		h.handleError(w, r, err)
+	default:
+		utils.WriteResponse(w, r, http.StatusOK, h.mapper.BatchResults(ops, results, true, i18n.FromRequest(r)))
+	}
 }
 
 func (h *Controller) getQuotes(w http.ResponseWriter, r *http.Request) {
-	author := r.URL.Query().Get("author")
+	h.writeQuotes(w, r, r.URL.Query().Get("author"))
+}
 
+func (h *Controller) getAuthorQuotes(w http.ResponseWriter, r *http.Request) {
+	h.writeQuotes(w, r, r.PathValue("author"))
+}
+
+func (h *Controller) writeQuotes(w http.ResponseWriter, r *http.Request, author string) {
 	var quotes []*entity.Quote
 	var err error
 
 	if author != "" {
-		quotes, err = h.service.GetQuotesByAuthor(author)
+		quotes, err = h.service.GetQuotesByAuthor(r.Context(), author)
 	} else {
-		quotes, err = h.service.GetAllQuotes()
+		quotes, err = h.service.GetAllQuotes(r.Context())
+	}
+
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response := h.mapper.Quotes(quotes)
+	utils.WriteResponse(w, r, http.StatusOK, response)
+}
+
+func (h *Controller) getQuote(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		h.handleError(w, r, ErrInvalidQuoteID)
+		return
 	}
 
+	quote, err := h.service.GetQuote(r.Context(), entity.QuoteID(id))
 	if err != nil {
-		h.handleDomainError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
-	response := dto.EntitiesToDTO(quotes)
-	utils.WriteJSON(w, http.StatusOK, response)
+	utils.WriteResponse(w, r, http.StatusOK, h.mapper.Quote(quote))
+}
+
+func (h *Controller) quoteCard(format card.Format) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			h.handleError(w, r, ErrInvalidQuoteID)
+			return
+		}
+		h.cards.Serve(w, r, entity.QuoteID(id), format)
+	}
 }
 
 func (h *Controller) getRandomQuote(w http.ResponseWriter, r *http.Request) {
-	quote, err := h.service.GetRandomQuote()
+	quote, err := h.service.GetRandomQuote(r.Context())
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	response := h.mapper.Quote(quote)
+	utils.WriteResponse(w, r, http.StatusOK, response)
+}
+
+func (h *Controller) exportQuotes(w http.ResponseWriter, r *http.Request) {
+	format, err := bulk.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	snapshot, err := h.service.Snapshot(r.Context())
+	if err != nil {
+		h.handleError(w, r, err)
+		return
+	}
+
+	writer, err := bulk.NewWriter(format, w, snapshot, h.mapper)
 	if err != nil {
-		h.handleDomainError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
+	defer bulk.Abort(writer)
+
+	filename := fmt.Sprintf("quotes-%d.%s", snapshot.Revision, format.Extension())
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("X-Snapshot-Revision", strconv.FormatUint(snapshot.Revision, 10))
+	w.Header().Set("X-Snapshot-Max-ID", strconv.FormatInt(int64(snapshot.MaxID), 10))
+	w.Header().Set("X-Snapshot-Taken-At", snapshot.TakenAt.UTC().Format(time.RFC3339Nano))
+	w.Header().Set("Trailer", ExportStatusTrailer)
+	w.WriteHeader(http.StatusOK)
 
-	response := dto.EntityToDTO(quote)
-	utils.WriteJSON(w, http.StatusOK, response)
+	// The status is already sent, so the trailer tells whether the body
+	// holds the whole snapshot.
+	exportStatus := "failed"
+	defer func() { w.Header().Set(ExportStatusTrailer, exportStatus) }()
+	if err := h.service.ExportQuotes(r.Context(), snapshot, writer.Write); err != nil {
+		if errors.Is(err, service.ErrSnapshotChanged) {
+			exportStatus = service.ErrSnapshotChanged.Code()
+		}
+		// An expired or abandoned export can only be cut short.
+		if r.Context().Err() != nil {
+			slog.InfoContext(r.Context(), "quote export interrupted", "error", err)
+			return
+		}
+		slog.ErrorContext(r.Context(), "exporting quotes", "error", err)
+		return
+	}
+	if err := writer.Close(); err != nil {
+		slog.ErrorContext(r.Context(), "finishing quote export", "error", err)
+		return
+	}
+	exportStatus = "complete"
 }
 
 func (h *Controller) deleteQuote(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/")
-	id, err := strconv.ParseInt(idStr, 10, 64)
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
 	if err != nil {
-		utils.WriteJSON(w, http.StatusBadRequest, dto.ErrorResponse{Error: "Invalid quote ID"})
+		h.handleError(w, r, ErrInvalidQuoteID)
 		return
 	}
 
-	err = h.service.DeleteQuote(entity.QuoteID(id))
+	err = h.service.DeleteQuote(r.Context(), utils.PrincipalFrom(r), entity.QuoteID(id))
 	if err != nil {
-		h.handleDomainError(w, err)
+		h.handleError(w, r, err)
 		return
 	}
 
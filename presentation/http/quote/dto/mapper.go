@@ -1,9 +1,17 @@
 package dto
 
 import (
+	"fmt"
+
+	"github.com/Korjick/go-http-quote/application/service"
+	"github.com/Korjick/go-http-quote/domain/apperror"
 	"github.com/Korjick/go-http-quote/domain/quote/entity"
+	"github.com/Korjick/go-http-quote/domain/quote/repository"
+	"github.com/Korjick/go-http-quote/presentation/http/i18n"
 )
 
+var ErrUnknownOperation = apperror.New(apperror.KindMalformed, "unknown_operation", "unknown batch operation")
+
 func EntityToDTO(quote *entity.Quote) QuoteResponse {
 	return QuoteResponse{
 		ID:        int64(quote.ID),
@@ -20,3 +28,64 @@ func EntitiesToDTO(quotes []*entity.Quote) []QuoteResponse {
 	}
 	return dtos
 }
+
+func ImportReportToDTO(report *service.ImportReport, lang i18n.Language) ImportResponse {
+	errs := make([]ImportErrorResponse, len(report.Errors))
+	for i, rowErr := range report.Errors {
+		code, message := lang.Localize(rowErr.Err)
+		errs[i] = ImportErrorResponse{Row: rowErr.Row, Code: code, Error: message}
+	}
+	return ImportResponse{
+		Mode:     string(report.Mode),
+		Total:    report.Total,
+		Imported: report.Imported,
+		Failed:   report.Failed(),
+		Errors:   errs,
+	}
+}
+
+func BatchRequestToOperations(req BatchRequest) ([]repository.Operation, error) {
+	ops := make([]repository.Operation, len(req.Operations))
+	for i, op := range req.Operations {
+		switch repository.OperationKind(op.Op) {
+		case repository.OperationCreate:
+			ops[i] = repository.Operation{
+				Kind:  repository.OperationCreate,
+				Draft: entity.QuoteDraft{Author: op.Author, Text: op.Quote},
+			}
+		case repository.OperationDelete:
+			ops[i] = repository.Operation{Kind: repository.OperationDelete, ID: entity.QuoteID(op.ID)}
+		default:
+			return nil, fmt.Errorf("%w: %q [%d]", ErrUnknownOperation, op.Op, i)
+		}
+	}
+	return ops, nil
+}
+
+func BatchResultsToDTO(ops []repository.Operation, results []repository.OperationResult, applied bool, lang i18n.Language) BatchResponse {
+	responses := make([]BatchOperationResponse, len(results))
+	for i, result := range results {
+		response := BatchOperationResponse{Op: string(ops[i].Kind)}
+		response.Status, response.Code, response.Error = batchStatus(ops[i], result, applied, lang)
+		if result.Quote != nil && applied {
+			quote := EntityToDTO(result.Quote)
+			response.Quote = &quote
+		}
+		responses[i] = response
+	}
+	return BatchResponse{Applied: applied, Results: responses}
+}
+
+func batchStatus(op repository.Operation, result repository.OperationResult, applied bool, lang i18n.Language) (status, code, message string) {
+	switch {
+	case result.Err != nil:
+		code, message = lang.Localize(result.Err)
+		return "failed", code, message
+	case !applied:
+		return "skipped", "", ""
+	case op.Kind == repository.OperationCreate:
+		return "created", "", ""
+	default:
+		return "deleted", "", ""
+	}
+}
@@ -1,6 +1,30 @@
 package dto
 
+import (
+	"github.com/Korjick/go-http-quote/domain/quote/entity"
+	"github.com/Korjick/go-http-quote/domain/quote/repository"
+)
+
 type CreateQuoteRequest struct {
 	Author string `json:"author"`
 	Quote  string `json:"quote"`
 }
+
+func (r CreateQuoteRequest) Draft() entity.QuoteDraft {
+	return entity.QuoteDraft{Author: r.Author, Text: r.Quote}
+}
+
+type BatchRequest struct {
+	Operations []BatchOperationRequest `json:"operations"`
+}
+
+func (r BatchRequest) ToOperations() ([]repository.Operation, error) {
+	return BatchRequestToOperations(r)
+}
+
+type BatchOperationRequest struct {
+	Op     string `json:"op"`
+	ID     int64  `json:"id,omitempty"`
+	Author string `json:"author,omitempty"`
+	Quote  string `json:"quote,omitempty"`
+}
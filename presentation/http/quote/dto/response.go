@@ -1,14 +1,47 @@
 package dto
 
-import "time"
+import (
+	"encoding/xml"
+	"time"
+)
 
 type QuoteResponse struct {
-	ID        int64     `json:"id"`
-	Author    string    `json:"author"`
-	Quote     string    `json:"quote"`
-	CreatedAt time.Time `json:"created_at"`
+	XMLName   xml.Name  `json:"-" xml:"quote"`
+	ID        int64     `json:"id" xml:"id"`
+	Author    string    `json:"author" xml:"author"`
+	Quote     string    `json:"quote" xml:"text"`
+	CreatedAt time.Time `json:"created_at" xml:"created_at"`
 }
 
-type ErrorResponse struct {
-	Error string `json:"error"`
+func (q QuoteResponse) String() string {
+	return q.Quote + " — " + q.Author
+}
+
+type ImportResponse struct {
+	XMLName  xml.Name              `json:"-" xml:"import"`
+	Mode     string                `json:"mode" xml:"mode"`
+	Total    int                   `json:"total" xml:"total"`
+	Imported int                   `json:"imported" xml:"imported"`
+	Failed   int                   `json:"failed" xml:"failed"`
+	Errors   []ImportErrorResponse `json:"errors" xml:"errors>error"`
+}
+
+type ImportErrorResponse struct {
+	Row   int    `json:"row" xml:"row"`
+	Code  string `json:"code" xml:"code"`
+	Error string `json:"error" xml:"message"`
+}
+
+type BatchResponse struct {
+	XMLName xml.Name                 `json:"-" xml:"batch"`
+	Applied bool                     `json:"applied" xml:"applied"`
+	Results []BatchOperationResponse `json:"results" xml:"results>result"`
+}
+
+type BatchOperationResponse struct {
+	Op     string         `json:"op" xml:"op"`
+	Status string         `json:"status" xml:"status"`
+	Quote  *QuoteResponse `json:"quote,omitempty" xml:"quote,omitempty"`
+	Code   string         `json:"code,omitempty" xml:"code,omitempty"`
+	Error  string         `json:"error,omitempty" xml:"message,omitempty"`
 }
@@ -0,0 +1,100 @@
+package dto
+
+import (
+	"encoding/xml"
+	"time"
+
+	"github.com/Korjick/go-http-quote/domain/quote/entity"
+	"github.com/Korjick/go-http-quote/domain/quote/repository"
+	"github.com/Korjick/go-http-quote/presentation/http/i18n"
+)
+
+// Version 2 of the API names the quote body "text" instead of "quote", in
+// requests and responses alike, and tells who added a quote.
+
+type QuoteResponseV2 struct {
+	XMLName   xml.Name  `json:"-" xml:"quote"`
+	ID        int64     `json:"id" xml:"id"`
+	Author    string    `json:"author" xml:"author"`
+	Text      string    `json:"text" xml:"text"`
+	CreatedAt time.Time `json:"created_at" xml:"created_at"`
+	CreatedBy string    `json:"created_by,omitempty" xml:"created_by,omitempty"`
+}
+
+func (q QuoteResponseV2) String() string {
+	return q.Text + " — " + q.Author
+}
+
+type CreateQuoteRequestV2 struct {
+	Author string `json:"author"`
+	Text   string `json:"text"`
+}
+
+func (r CreateQuoteRequestV2) Draft() entity.QuoteDraft {
+	return entity.QuoteDraft{Author: r.Author, Text: r.Text}
+}
+
+type BatchRequestV2 struct {
+	Operations []BatchOperationRequestV2 `json:"operations"`
+}
+
+type BatchOperationRequestV2 struct {
+	Op     string `json:"op"`
+	ID     int64  `json:"id,omitempty"`
+	Author string `json:"author,omitempty"`
+	Text   string `json:"text,omitempty"`
+}
+
+func (r BatchRequestV2) ToOperations() ([]repository.Operation, error) {
+	req := BatchRequest{Operations: make([]BatchOperationRequest, len(r.Operations))}
+	for i, op := range r.Operations {
+		req.Operations[i] = BatchOperationRequest{Op: op.Op, ID: op.ID, Author: op.Author, Quote: op.Text}
+	}
+	return BatchRequestToOperations(req)
+}
+
+type BatchResponseV2 struct {
+	XMLName xml.Name                   `json:"-" xml:"batch"`
+	Applied bool                       `json:"applied" xml:"applied"`
+	Results []BatchOperationResponseV2 `json:"results" xml:"results>result"`
+}
+
+type BatchOperationResponseV2 struct {
+	Op     string           `json:"op" xml:"op"`
+	Status string           `json:"status" xml:"status"`
+	Quote  *QuoteResponseV2 `json:"quote,omitempty" xml:"quote,omitempty"`
+	Code   string           `json:"code,omitempty" xml:"code,omitempty"`
+	Error  string           `json:"error,omitempty" xml:"message,omitempty"`
+}
+
+func EntityToDTOV2(quote *entity.Quote) QuoteResponseV2 {
+	return QuoteResponseV2{
+		ID:        int64(quote.ID),
+		Author:    quote.Author,
+		Text:      quote.Text,
+		CreatedAt: quote.CreatedAt,
+		CreatedBy: quote.CreatedBy,
+	}
+}
+
+func EntitiesToDTOV2(quotes []*entity.Quote) []QuoteResponseV2 {
+	dtos := make([]QuoteResponseV2, len(quotes))
+	for i, quote := range quotes {
+		dtos[i] = EntityToDTOV2(quote)
+	}
+	return dtos
+}
+
+func BatchResultsToDTOV2(ops []repository.Operation, results []repository.OperationResult, applied bool, lang i18n.Language) BatchResponseV2 {
+	responses := make([]BatchOperationResponseV2, len(results))
+	for i, result := range results {
+		response := BatchOperationResponseV2{Op: string(ops[i].Kind)}
+		response.Status, response.Code, response.Error = batchStatus(ops[i], result, applied, lang)
+		if result.Quote != nil && applied {
+			quote := EntityToDTOV2(result.Quote)
+			response.Quote = &quote
+		}
+		responses[i] = response
+	}
+	return BatchResponseV2{Applied: applied, Results: responses}
+}
@@ -0,0 +1,120 @@
+package dto
+
+import (
+	"strconv"
+
+	"github.com/Korjick/go-http-quote/domain/quote/entity"
+	"github.com/Korjick/go-http-quote/domain/quote/repository"
+	"github.com/Korjick/go-http-quote/presentation/http/i18n"
+)
+
+type Version int
+
+const (
+	V1 Version = 1
+	V2 Version = 2
+
+	DefaultVersion = V1
+)
+
+var Versions = []Version{V1, V2}
+
+func (v Version) String() string {
+	return "v" + strconv.Itoa(int(v))
+}
+
+func (v Version) Supported() bool {
+	return v >= V1 && v <= V2
+}
+
+type DraftRequest interface {
+	Draft() entity.QuoteDraft
+}
+
+type OperationsRequest interface {
+	ToOperations() ([]repository.Operation, error)
+}
+
+// Mapper converts between entities and the request and response bodies of
+// one API version. CreateRequest and BatchRequest return pointers ready to be
+// decoded into.
+type Mapper interface {
+	Version() Version
+	Quote(quote *entity.Quote) any
+	Quotes(quotes []*entity.Quote) any
+	CreateRequest() DraftRequest
+	BatchRequest() OperationsRequest
+	// TextField names the quote body in the bodies of the version, and the
+	// column of CSV imports and exports.
+	TextField() string
+	BatchResults(ops []repository.Operation, results []repository.OperationResult, applied bool, lang i18n.Language) any
+}
+
+func MapperFor(v Version) Mapper {
+	switch v {
+	case V2:
+		return v2Mapper{}
+	default:
+		return v1Mapper{}
+	}
+}
+
+type v1Mapper struct{}
+
+func (v1Mapper) Version() Version {
+	return V1
+}
+
+func (v1Mapper) Quote(quote *entity.Quote) any {
+	return EntityToDTO(quote)
+}
+
+func (v1Mapper) Quotes(quotes []*entity.Quote) any {
+	return EntitiesToDTO(quotes)
+}
+
+func (v1Mapper) CreateRequest() DraftRequest {
+	return &CreateQuoteRequest{}
+}
+
+func (v1Mapper) BatchRequest() OperationsRequest {
+	return &BatchRequest{}
+}
+
+func (v1Mapper) TextField() string {
+	return "quote"
+}
+
+func (v1Mapper) BatchResults(ops []repository.Operation, results []repository.OperationResult, applied bool, lang i18n.Language) any {
+	return BatchResultsToDTO(ops, results, applied, lang)
+}
+
+type v2Mapper struct{}
+
+func (v2Mapper) Version() Version {
+	return V2
+}
+
+func (v2Mapper) Quote(quote *entity.Quote) any {
+	return EntityToDTOV2(quote)
+}
+
+func (v2Mapper) Quotes(quotes []*entity.Quote) any {
+	return EntitiesToDTOV2(quotes)
+}
+
+func (v2Mapper) CreateRequest() DraftRequest {
+	return &CreateQuoteRequestV2{}
+}
+
+func (v2Mapper) BatchRequest() OperationsRequest {
+	return &BatchRequestV2{}
+}
+
+func (v2Mapper) TextField() string {
+	return "text"
+}
+
+func (v2Mapper) BatchResults(ops []repository.Operation, results []repository.OperationResult, applied bool, lang i18n.Language) any {
+	return BatchResultsToDTOV2(ops, results, applied, lang)
+}
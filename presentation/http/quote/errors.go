@@ -0,0 +1,12 @@
+package quote
+
+import (
+	utils "github.com/Korjick/go-http-quote/presentation/http"
+
+	"github.com/Korjick/go-http-quote/domain/apperror"
+)
+
+var (
+	ErrMalformedJSON  = utils.ErrMalformedJSON
+	ErrInvalidQuoteID = apperror.New(apperror.KindMalformed, "invalid_quote_id", "invalid quote ID")
+)
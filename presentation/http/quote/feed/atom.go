@@ -0,0 +1,78 @@
+package feed
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+const atomNamespace = "http://www.w3.org/2005/Atom"
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	ID        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Updated   string      `xml:"updated"`
+	Published string      `xml:"published"`
+	Author    atomPerson  `xml:"author"`
+	Content   atomContent `xml:"content"`
+	Link      atomLink    `xml:"link"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+func newAtom(data feed) atomFeed {
+	// Atom requires updated; a collection that never changed has no entries,
+	// so the epoch is as good as any other time.
+	updated := data.modifiedAt
+	if updated.IsZero() {
+		updated = time.Unix(0, 0)
+	}
+
+	doc := atomFeed{
+		ID:       feedGUID(data.author),
+		Title:    data.title,
+		Subtitle: data.description,
+		Updated:  updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: data.selfURL},
+			{Rel: "alternate", Type: "text/html", Href: data.siteURL},
+		},
+		Entries: make([]atomEntry, len(data.items)),
+	}
+
+	for i, item := range data.items {
+		created := item.createdAt.UTC().Format(time.RFC3339)
+		doc.Entries[i] = atomEntry{
+			ID:        item.id,
+			Title:     item.title,
+			Updated:   created,
+			Published: created,
+			Author:    atomPerson{Name: item.author},
+			Content:   atomContent{Type: "text", Value: item.text},
+			Link:      atomLink{Rel: "alternate", Href: item.url},
+		}
+	}
+	return doc
+}
@@ -0,0 +1,183 @@
+package feed
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	utils "github.com/Korjick/go-http-quote/presentation/http"
+
+	"github.com/Korjick/go-http-quote/application/service"
+	"github.com/Korjick/go-http-quote/domain/quote/entity"
+	"github.com/Korjick/go-http-quote/presentation/http/i18n"
+)
+
+const (
+	DefaultLimit = 50
+	// DefaultBaseURL is where the service listens by default.
+	DefaultBaseURL = "http://localhost:8080"
+
+	RSSContentType  = "application/rss+xml; charset=utf-8"
+	AtomContentType = "application/atom+xml; charset=utf-8"
+
+	titleLength = 80
+)
+
+// Feeds serves the most recently added quotes as RSS 2.0 and Atom feeds,
+// optionally limited to one author with the "author" query parameter.
+// Conditional requests are answered from the time and revision of the last
+// change to the collection, so deletions invalidate cached feeds as well.
+// The revision-based ETag covers changes made within the one-second
+// resolution of Last-Modified. The title and description follow
+// Accept-Language, so the ETag names the language too.
+//
+// Links start with baseURL, the public URL of the service, rather than the
+// Host of the request: the client controls that header, and a feed cached
+// by a proxy would carry its links to every reader.
+type Feeds struct {
+	service *service.QuoteService
+	prefix  string
+	baseURL string
+	limit   int
+}
+
+func NewFeeds(service *service.QuoteService, prefix, baseURL string) *Feeds {
+	return &Feeds{service: service, prefix: prefix, baseURL: strings.TrimSuffix(baseURL, "/"), limit: DefaultLimit}
+}
+
+type feed struct {
+	title       string
+	description string
+	language    i18n.Language
+	author      string
+	selfURL     string
+	siteURL     string
+	modifiedAt  time.Time
+	revision    uint64
+	items       []item
+}
+
+type item struct {
+	id        string
+	title     string
+	text      string
+	author    string
+	url       string
+	createdAt time.Time
+}
+
+func (f *Feeds) RSS(w http.ResponseWriter, r *http.Request) {
+	f.serve(w, r, RSSContentType, func(data feed) any { return newRSS(data) })
+}
+
+func (f *Feeds) Atom(w http.ResponseWriter, r *http.Request) {
+	f.serve(w, r, AtomContentType, func(data feed) any { return newAtom(data) })
+}
+
+func (f *Feeds) serve(w http.ResponseWriter, r *http.Request, contentType string, document func(feed) any) {
+	data, err := f.load(r)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document(data)); err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	utils.AddVary(w.Header(), "Accept-Language")
+	w.Header().Set("ETag", fmt.Sprintf(`W/"%d-%s"`, data.revision, data.language))
+	http.ServeContent(w, r, "", data.modifiedAt, bytes.NewReader(buf.Bytes()))
+}
+
+func (f *Feeds) load(r *http.Request) (feed, error) {
+	// The snapshot is taken first, so a change made while the quotes are
+	// read can only make Last-Modified older than the content, never newer.
+	snapshot, err := f.service.Snapshot(r.Context())
+	if err != nil {
+		return feed{}, err
+	}
+
+	author := r.URL.Query().Get("author")
+	quotes, err := f.service.RecentQuotes(r.Context(), author, f.limit)
+	if err != nil {
+		return feed{}, err
+	}
+
+	lang := i18n.FromRequest(r)
+	base := f.baseURL
+	data := feed{
+		title:       message(lang, i18n.CodeFeedTitle),
+		description: message(lang, i18n.CodeFeedDescription),
+		language:    lang,
+		author:      author,
+		selfURL:     base + r.URL.RequestURI(),
+		siteURL:     base + f.prefix,
+		modifiedAt:  snapshot.ModifiedAt,
+		revision:    snapshot.Revision,
+		items:       make([]item, len(quotes)),
+	}
+	if author != "" {
+		data.title = fmt.Sprintf(message(lang, i18n.CodeFeedAuthorTitle), author)
+	}
+	for i, quote := range quotes {
+		data.items[i] = item{
+			id:        QuoteGUID(quote.ID),
+			title:     summarize(quote.Text, titleLength),
+			text:      quote.Text,
+			author:    quote.Author,
+			url:       fmt.Sprintf("%s%s/%d", base, f.prefix, quote.ID),
+			createdAt: quote.CreatedAt,
+		}
+	}
+	return data, nil
+}
+
+func message(lang i18n.Language, code string) string {
+	text, _ := lang.Message(code)
+	return text
+}
+
+// QuoteGUID returns a name-based (version 5) UUID URN derived from the quote
+// ID. IDs are never reused, so the GUID identifies the quote for good,
+// whatever host or API version the feed was fetched from.
+func QuoteGUID(id entity.QuoteID) string {
+	return "urn:uuid:" + uuid5(fmt.Sprintf("quote:%d", id))
+}
+
+func feedGUID(author string) string {
+	return "urn:uuid:" + uuid5("feed:"+strings.ToLower(author))
+}
+
+// quoteNamespace is the UUID namespace of all quote GUIDs.
+var quoteNamespace = [16]byte{0x3c, 0x5e, 0x8a, 0x71, 0x2f, 0x0d, 0x4b, 0x6e, 0x9a, 0x41, 0xd7, 0x25, 0x6b, 0x18, 0xe0, 0x93}
+
+func uuid5(name string) string {
+	hash := sha1.New()
+	hash.Write(quoteNamespace[:])
+	hash.Write([]byte(name))
+	sum := hash.Sum(nil)
+
+	sum[6] = sum[6]&0x0f | 0x50
+	sum[8] = sum[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+func summarize(text string, length int) string {
+	if utf8.RuneCountInString(text) <= length {
+		return text
+	}
+	runes := []rune(text)
+	return strings.TrimSpace(string(runes[:length-1])) + "…"
+}
@@ -0,0 +1,67 @@
+package feed
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	DCNS    string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	SelfLink      atomLink  `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Description string  `xml:"description"`
+	Author      string  `xml:"dc:creator"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+func newRSS(data feed) rssDocument {
+	doc := rssDocument{
+		Version: "2.0",
+		AtomNS:  atomNamespace,
+		DCNS:    "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:       data.title,
+			Link:        data.siteURL,
+			Description: data.description,
+			SelfLink:    atomLink{Rel: "self", Type: "application/rss+xml", Href: data.selfURL},
+			Items:       make([]rssItem, len(data.items)),
+		},
+	}
+	if !data.modifiedAt.IsZero() {
+		doc.Channel.LastBuildDate = data.modifiedAt.UTC().Format(time.RFC1123Z)
+	}
+
+	for i, item := range data.items {
+		doc.Channel.Items[i] = rssItem{
+			Title:       item.title,
+			Link:        item.url,
+			Description: item.text + " — " + item.author,
+			Author:      item.author,
+			GUID:        rssGUID{Value: item.id},
+			PubDate:     item.createdAt.UTC().Format(time.RFC1123Z),
+		}
+	}
+	return doc
+}
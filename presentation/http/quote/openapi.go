@@ -0,0 +1,261 @@
+package quote
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	utils "github.com/Korjick/go-http-quote/presentation/http"
+
+	"github.com/Korjick/go-http-quote/application/service"
+	"github.com/Korjick/go-http-quote/domain/quote/entity"
+	"github.com/Korjick/go-http-quote/presentation/http/openapi"
+	"github.com/Korjick/go-http-quote/presentation/http/quote/bulk"
+	"github.com/Korjick/go-http-quote/presentation/http/quote/card"
+	"github.com/Korjick/go-http-quote/presentation/http/quote/dto"
+	"github.com/Korjick/go-http-quote/presentation/http/quote/feed"
+)
+
+// Describe adds every route of the controller to doc. Versioned prefixes
+// are documented with the DTOs of their version; the unversioned prefix
+// lists the bodies of all versions under their vendor media types.
+func (c *VersionedController) Describe(doc *openapi.Document) {
+	mappers := []dto.Mapper{dto.MapperFor(dto.DefaultVersion)}
+	for _, version := range dto.Versions {
+		if version != dto.DefaultVersion {
+			mappers = append(mappers, dto.MapperFor(version))
+		}
+	}
+	c.bare[dto.DefaultVersion].describe(spec{doc: doc, tag: "quotes", mappers: mappers})
+
+	for _, version := range dto.Versions {
+		c.versioned[version].describe(spec{
+			doc:     doc,
+			tag:     "quotes " + version.String(),
+			suffix:  strings.ToUpper(version.String()),
+			mappers: []dto.Mapper{dto.MapperFor(version)},
+		})
+	}
+}
+
+func (h *Controller) describe(s spec) {
+	id := openapi.PathParameter("id", "Quote ID")
+	id.Schema = &openapi.Schema{Type: "integer", Format: "int64"}
+	author := openapi.QueryParameter("author", "Only quotes of this author", openapi.String())
+	quote := s.response(func(m dto.Mapper) any { return m.Quote(&entity.Quote{}) })
+	quotes := s.response(func(m dto.Mapper) any { return m.Quotes(nil) })
+
+	s.add(http.MethodGet, h.prefix, &openapi.Operation{
+		OperationID: "listQuotes",
+		Summary:     "List quotes",
+		Parameters:  []openapi.Parameter{author},
+		Responses:   s.responses(http.StatusOK, "Quotes", quotes, http.StatusNotAcceptable),
+	})
+	s.add(http.MethodPost, h.prefix, &openapi.Operation{
+		OperationID: "createQuote",
+		Summary:     "Create a quote",
+		RequestBody: &openapi.RequestBody{Required: true, Content: s.request(func(m dto.Mapper) any { return m.CreateRequest() })},
+		Responses: s.responses(http.StatusCreated, "Created quote", quote,
+			http.StatusBadRequest, http.StatusNotAcceptable, http.StatusRequestEntityTooLarge,
+			http.StatusUnsupportedMediaType, http.StatusUnprocessableEntity),
+	})
+	s.add(http.MethodGet, h.prefix+"/random", &openapi.Operation{
+		OperationID: "getRandomQuote",
+		Summary:     "Get a random quote",
+		Responses:   s.responses(http.StatusOK, "Random quote", quote, http.StatusNotFound, http.StatusNotAcceptable),
+	})
+	s.add(http.MethodGet, h.prefix+"/authors/{author}", &openapi.Operation{
+		OperationID: "listAuthorQuotes",
+		Summary:     "List quotes of an author",
+		Parameters:  []openapi.Parameter{openapi.PathParameter("author", "Author name")},
+		Responses:   s.responses(http.StatusOK, "Quotes", quotes, http.StatusNotAcceptable),
+	})
+	s.add(http.MethodGet, h.prefix+"/{id}", &openapi.Operation{
+		OperationID: "getQuote",
+		Summary:     "Get a quote",
+		Description: "Browsers preferring text/html get an HTML page instead.",
+		Parameters:  []openapi.Parameter{id},
+		Responses:   s.responses(http.StatusOK, "Quote", quote, http.StatusBadRequest, http.StatusNotFound, http.StatusNotAcceptable),
+	})
+	s.add(http.MethodDelete, h.prefix+"/{id}", &openapi.Operation{
+		OperationID: "deleteQuote",
+		Summary:     "Delete a quote",
+		Parameters:  []openapi.Parameter{id},
+		Responses:   s.responses(http.StatusNoContent, "Deleted", nil, http.StatusBadRequest, http.StatusNotFound, http.StatusNotAcceptable),
+	})
+
+	themes := make([]string, len(card.Themes))
+	for i, theme := range card.Themes {
+		themes[i] = theme.Name
+	}
+	sizes := make([]string, len(card.Sizes))
+	for i, size := range card.Sizes {
+		sizes[i] = size.Name
+	}
+	cardParameters := []openapi.Parameter{
+		id,
+		openapi.QueryParameter("theme", "Color theme, "+themes[0]+" by default", openapi.Enum(themes...)),
+		openapi.QueryParameter("size", "Image size, "+sizes[0]+" by default", openapi.Enum(sizes...)),
+	}
+	for _, format := range []card.Format{card.FormatPNG, card.FormatSVG} {
+		schema := openapi.String()
+		if format == card.FormatPNG {
+			schema = openapi.Binary()
+		}
+		s.add(http.MethodGet, h.prefix+"/{id}/card."+string(format), &openapi.Operation{
+			OperationID: "getQuoteCard" + strings.ToUpper(string(format)),
+			Summary:     "Render a quote card as " + strings.ToUpper(string(format)),
+			Parameters:  cardParameters,
+			Responses: s.responses(http.StatusOK, "Card image", media(format.ContentType(), schema),
+				http.StatusNotModified, http.StatusBadRequest, http.StatusNotFound),
+		})
+	}
+
+	exportFormats := []bulk.Format{bulk.FormatJSONLines, bulk.FormatCSV, bulk.FormatTarGz, bulk.FormatFortune, bulk.FormatFortuneArchive}
+	exportContent := make(map[string]openapi.MediaType)
+	formatNames := make([]string, len(exportFormats))
+	for i, format := range exportFormats {
+		formatNames[i] = string(format)
+		exportContent[format.ContentType()] = openapi.MediaType{Schema: openapi.Binary()}
+	}
+	s.add(http.MethodGet, h.prefix+"/export", &openapi.Operation{
+		OperationID: "exportQuotes",
+		Summary:     "Export all quotes",
+		Parameters: []openapi.Parameter{
+			openapi.QueryParameter("format", "Export format, "+formatNames[0]+" by default", openapi.Enum(formatNames...)),
+		},
+		Responses: s.responses(http.StatusOK, "Quotes as of the start of the export", exportContent, http.StatusBadRequest),
+	})
+
+	s.add(http.MethodGet, h.prefix+"/feed.rss", &openapi.Operation{
+		OperationID: "getRSSFeed",
+		Summary:     "Recently added quotes as RSS 2.0",
+		Parameters:  []openapi.Parameter{author},
+		Responses:   s.responses(http.StatusOK, "Feed", media(feed.RSSContentType, openapi.String()), http.StatusNotModified),
+	})
+	s.add(http.MethodGet, h.prefix+"/feed.atom", &openapi.Operation{
+		OperationID: "getAtomFeed",
+		Summary:     "Recently added quotes as Atom",
+		Parameters:  []openapi.Parameter{author},
+		Responses:   s.responses(http.StatusOK, "Feed", media(feed.AtomContentType, openapi.String()), http.StatusNotModified),
+	})
+
+	record := s.doc.SchemaOf(dto.CreateQuoteRequest{})
+	importReport := media("application/json", s.doc.SchemaOf(dto.ImportResponse{}))
+	importResponses := s.responses(http.StatusOK, "Import report", importReport, http.StatusBadRequest, http.StatusNotAcceptable, http.StatusUnsupportedMediaType)
+	importResponses[strconv.Itoa(http.StatusUnprocessableEntity)] = openapi.Response{
+		Description: "Import report of an atomic import with invalid rows; nothing was imported",
+		Content:     importReport,
+	}
+	s.add(http.MethodPost, h.prefix+"/import", &openapi.Operation{
+		OperationID: "importQuotes",
+		Summary:     "Import quotes",
+		Parameters: []openapi.Parameter{
+			openapi.QueryParameter("mode", "Import mode, atomic by default",
+				openapi.Enum(string(service.ImportModeAtomic), string(service.ImportModeBestEffort))),
+		},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content: map[string]openapi.MediaType{
+				"application/json":      {Schema: &openapi.Schema{Type: "array", Items: record}},
+				"application/x-ndjson":  {Schema: openapi.String()},
+				"text/csv":              {Schema: openapi.String()},
+				"application/x-fortune": {Schema: openapi.String()},
+			},
+		},
+		Responses: importResponses,
+	})
+
+	batchResults := s.response(func(m dto.Mapper) any { return m.BatchResults(nil, nil, true, "") })
+	batchResponses := s.responses(http.StatusOK, "Applied operations", batchResults,
+		http.StatusBadRequest, http.StatusNotAcceptable, http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType)
+	batchResponses[strconv.Itoa(http.StatusUnprocessableEntity)] = openapi.Response{
+		Description: "Results of a batch that was not applied because an operation failed",
+		Content:     batchResults,
+	}
+	s.add(http.MethodPost, h.prefix+"/batch", &openapi.Operation{
+		OperationID: "batchQuotes",
+		Summary:     "Create and delete quotes atomically",
+		RequestBody: &openapi.RequestBody{Required: true, Content: s.request(func(m dto.Mapper) any { return m.BatchRequest() })},
+		Responses:   batchResponses,
+	})
+}
+
+type spec struct {
+	doc     *openapi.Document
+	tag     string
+	suffix  string
+	mappers []dto.Mapper
+}
+
+func (s spec) add(method, path string, op *openapi.Operation) {
+	op.OperationID += s.suffix
+	op.Tags = []string{s.tag}
+	// Reading is open to everyone unless the operation says otherwise;
+	// changes need credentials.
+	if op.Security == nil {
+		op.Security = s.doc.Requirements(method == http.MethodGet)
+	}
+	if method != http.MethodGet {
+		op.Parameters = append(op.Parameters, openapi.HeaderParameter(
+			"Idempotency-Key", "Replays the stored response to a retry with the same key"))
+		op.Responses[strconv.Itoa(http.StatusUnauthorized)] = s.problem(http.StatusUnauthorized)
+		op.Responses[strconv.Itoa(http.StatusForbidden)] = s.problem(http.StatusForbidden)
+	}
+	op.Responses[strconv.Itoa(http.StatusTooManyRequests)] = s.problem(http.StatusTooManyRequests)
+	notAcceptable := strconv.Itoa(http.StatusNotAcceptable)
+	if _, ok := op.Responses[notAcceptable]; !ok && len(s.mappers) > 1 {
+		op.Responses[notAcceptable] = s.problem(http.StatusNotAcceptable)
+	}
+	s.doc.Add(method, path, op)
+}
+
+// request documents a JSON request body, which on the unversioned prefix
+// may follow any version.
+func (s spec) request(value func(dto.Mapper) any) map[string]openapi.MediaType {
+	schemas := make([]*openapi.Schema, len(s.mappers))
+	for i, m := range s.mappers {
+		schemas[i] = s.doc.SchemaOf(value(m))
+	}
+	return media("application/json", openapi.OneOf(schemas...))
+}
+
+// response documents a JSON response body. The first mapper is the one used
+// without a vendor media type.
+func (s spec) response(value func(dto.Mapper) any) map[string]openapi.MediaType {
+	content := media("application/json", s.doc.SchemaOf(value(s.mappers[0])))
+	if len(s.mappers) > 1 {
+		for _, m := range s.mappers {
+			content[VendorMediaType(m.Version())] = openapi.MediaType{Schema: s.doc.SchemaOf(value(m))}
+		}
+	}
+	return content
+}
+
+// responses builds the response map of an operation: the successful
+// response, followed by the statuses it may fail or short-circuit with. On
+// the unversioned prefix every operation may fail with 406.
+func (s spec) responses(status int, description string, content map[string]openapi.MediaType, others ...int) map[string]openapi.Response {
+	responses := map[string]openapi.Response{
+		strconv.Itoa(status): {Description: description, Content: content},
+	}
+	for _, other := range others {
+		if other == http.StatusNotModified {
+			responses[strconv.Itoa(other)] = openapi.Response{Description: http.StatusText(other)}
+			continue
+		}
+		responses[strconv.Itoa(other)] = s.problem(other)
+	}
+	return responses
+}
+
+func (s spec) problem(status int) openapi.Response {
+	return openapi.Response{
+		Description: http.StatusText(status),
+		Content:     media(utils.ProblemContentType, s.doc.SchemaOf(utils.Problem{})),
+	}
+}
+
+func media(contentType string, schema *openapi.Schema) map[string]openapi.MediaType {
+	return map[string]openapi.MediaType{contentType: {Schema: schema}}
+}
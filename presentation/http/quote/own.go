@@ -0,0 +1,82 @@
+package quote
+
+import (
+	"fmt"
+	"net/http"
+
+	utils "github.com/Korjick/go-http-quote/presentation/http"
+
+	"github.com/Korjick/go-http-quote/application/service"
+	"github.com/Korjick/go-http-quote/presentation/http/i18n"
+	"github.com/Korjick/go-http-quote/presentation/http/openapi"
+	"github.com/Korjick/go-http-quote/presentation/http/quote/dto"
+)
+
+// OwnQuotesController serves the quotes of the calling principal under
+// prefix+"/quotes", such as /me/quotes. Like the unversioned quote prefix it
+// picks the API version from the Accept header.
+type OwnQuotesController struct {
+	service *service.QuoteService
+	prefix  string
+	router  *utils.Router
+}
+
+func NewOwnQuotesController(service *service.QuoteService, prefix string, opts ...Option) *OwnQuotesController {
+	o := newOptions(opts)
+	controller := &OwnQuotesController{service: service, prefix: prefix, router: utils.NewRouter()}
+	controller.router.HandleFunc(http.MethodGet, prefix+"/quotes",
+		utils.WithTimeout(o.timeouts.Request, utils.Negotiated(controller.getOwnQuotes)))
+	controller.router.NotFound = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		utils.WriteProblem(w, r, utils.LocalizedProblem(r, http.StatusNotFound, i18n.CodeRouteNotFound))
+	})
+	controller.router.MethodNotAllowed = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		problem := utils.LocalizedProblem(r, http.StatusMethodNotAllowed, i18n.CodeMethodNotAllowed)
+		problem.Detail += fmt.Sprintf(": %s (%s)", r.Method, w.Header().Get("Allow"))
+		utils.WriteProblem(w, r, problem)
+	})
+	return controller
+}
+
+func (h *OwnQuotesController) Routes() []string {
+	return h.router.Routes()
+}
+
+func (h *OwnQuotesController) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.router.ServeHTTP(w, r)
+}
+
+func (h *OwnQuotesController) getOwnQuotes(w http.ResponseWriter, r *http.Request) {
+	utils.AddVary(w.Header(), "Accept")
+	version, err := VersionFromAccept(r.Header.Get("Accept"))
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	w.Header().Set(APIVersionHeader, version.String())
+
+	quotes, err := h.service.OwnQuotes(r.Context(), utils.PrincipalFrom(r))
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	utils.WriteResponse(w, r, http.StatusOK, dto.MapperFor(version).Quotes(quotes))
+}
+
+// Describe adds the route of the controller to doc.
+func (h *OwnQuotesController) Describe(doc *openapi.Document) {
+	s := spec{doc: doc, tag: "quotes", mappers: []dto.Mapper{dto.MapperFor(dto.DefaultVersion)}}
+	for _, version := range dto.Versions {
+		if version != dto.DefaultVersion {
+			s.mappers = append(s.mappers, dto.MapperFor(version))
+		}
+	}
+
+	s.add(http.MethodGet, h.prefix+"/quotes", &openapi.Operation{
+		OperationID: "listOwnQuotes",
+		Summary:     "List the quotes of the caller",
+		Description: "Quotes owned by the authenticated caller, which it may delete without moderator rights.",
+		Security:    doc.Requirements(false),
+		Responses: s.responses(http.StatusOK, "Own quotes", s.response(func(m dto.Mapper) any { return m.Quotes(nil) }),
+			http.StatusUnauthorized, http.StatusNotAcceptable),
+	})
+}
@@ -0,0 +1,94 @@
+package page
+
+import (
+	"fmt"
+
+	"github.com/Korjick/go-http-quote/presentation/http/i18n"
+)
+
+var labels = map[string]map[i18n.Language]string{
+	"site_name": {
+		i18n.English: "Quotes",
+		i18n.Russian: "Цитаты",
+	},
+	"all_quotes": {
+		i18n.English: "All quotes",
+		i18n.Russian: "Все цитаты",
+	},
+	"random_quote": {
+		i18n.English: "Random quote",
+		i18n.Russian: "Случайная цитата",
+	},
+	"another_quote": {
+		i18n.English: "Another one",
+		i18n.Russian: "Еще одна",
+	},
+	"quotes_by": {
+		i18n.English: "Quotes by %s",
+		i18n.Russian: "Цитаты автора %s",
+	},
+	"search_results": {
+		i18n.English: "Search results for “%s”",
+		i18n.Russian: "Результаты поиска «%s»",
+	},
+	"search": {
+		i18n.English: "Search",
+		i18n.Russian: "Найти",
+	},
+	"search_text": {
+		i18n.English: "Text or author",
+		i18n.Russian: "Текст или автор",
+	},
+	"search_author": {
+		i18n.English: "Author",
+		i18n.Russian: "Автор",
+	},
+	"no_quotes": {
+		i18n.English: "No quotes found.",
+		i18n.Russian: "Цитаты не найдены.",
+	},
+	"total": {
+		i18n.English: "Quotes found: %d",
+		i18n.Russian: "Найдено цитат: %d",
+	},
+	"page_of": {
+		i18n.English: "Page %d of %d",
+		i18n.Russian: "Страница %d из %d",
+	},
+	"previous": {
+		i18n.English: "← Previous",
+		i18n.Russian: "← Назад",
+	},
+	"next": {
+		i18n.English: "Next →",
+		i18n.Russian: "Вперед →",
+	},
+	"added_on": {
+		i18n.English: "Added on %s",
+		i18n.Russian: "Добавлена %s",
+	},
+	"back_to_list": {
+		i18n.English: "Back to all quotes",
+		i18n.Russian: "Ко всем цитатам",
+	},
+}
+
+var dateLayouts = map[i18n.Language]string{
+	i18n.English: "January 2, 2006",
+	i18n.Russian: "02.01.2006",
+}
+
+func translate(lang i18n.Language, key string, args ...any) string {
+	texts, ok := labels[key]
+	if !ok {
+		return key
+	}
+	text, ok := texts[lang]
+	if !ok {
+		text = texts[i18n.DefaultLanguage]
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+	return text
+}
@@ -0,0 +1,223 @@
+package page
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	utils "github.com/Korjick/go-http-quote/presentation/http"
+
+	"github.com/Korjick/go-http-quote/application/service"
+	"github.com/Korjick/go-http-quote/domain/quote/entity"
+	"github.com/Korjick/go-http-quote/presentation/http/i18n"
+)
+
+const DefaultPageSize = 20
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+var pageNames = []string{"list", "quote", "error"}
+
+// Pages renders the quote collection as HTML for browsers. It is served from
+// the same paths as the JSON API when the Accept header prefers text/html.
+type Pages struct {
+	service   *service.QuoteService
+	prefix    string
+	pageSize  int
+	templates map[string]*template.Template
+}
+
+func NewPages(service *service.QuoteService, prefix string) *Pages {
+	p := &Pages{
+		service:   service,
+		prefix:    prefix,
+		pageSize:  DefaultPageSize,
+		templates: make(map[string]*template.Template),
+	}
+
+	funcs := template.FuncMap{
+		"t":          translate,
+		"quotePath":  p.quotePath,
+		"authorPath": p.authorPath,
+		"date": func(lang i18n.Language, t time.Time) string {
+			return t.Format(dateLayouts[lang])
+		},
+	}
+	for _, name := range pageNames {
+		p.templates[name] = template.Must(template.New(name).Funcs(funcs).ParseFS(templateFS,
+			"templates/layout.html", "templates/partials.html", "templates/"+name+".html"))
+	}
+	return p
+}
+
+type pageData struct {
+	Lang       i18n.Language
+	Prefix     string
+	Title      string
+	Heading    string
+	Query      string
+	Author     string
+	Quotes     []*entity.Quote
+	Quote      *entity.Quote
+	Random     bool
+	Pagination pagination
+	Status     int
+	Detail     string
+}
+
+type pagination struct {
+	Page    int
+	Pages   int
+	Total   int
+	PrevURL string
+	NextURL string
+}
+
+func (p *Pages) List(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	data := p.newData(r)
+	data.Query = query.Get("q")
+	data.Author = query.Get("author")
+	data.Title = translate(data.Lang, "all_quotes")
+	data.Heading = data.Title
+	if data.Query != "" {
+		data.Heading = translate(data.Lang, "search_results", data.Query)
+	}
+
+	p.renderQuotes(w, r, data)
+}
+
+func (p *Pages) Author(w http.ResponseWriter, r *http.Request) {
+	data := p.newData(r)
+	data.Author = r.PathValue("author")
+	data.Query = r.URL.Query().Get("q")
+	data.Title = translate(data.Lang, "quotes_by", data.Author)
+	data.Heading = data.Title
+
+	p.renderQuotes(w, r, data)
+}
+
+func (p *Pages) renderQuotes(w http.ResponseWriter, r *http.Request, data pageData) {
+	quotes, err := p.service.SearchQuotes(r.Context(), data.Author, data.Query)
+	if err != nil {
+		p.renderError(w, r, err)
+		return
+	}
+
+	data.Quotes, data.Pagination = p.paginate(r, quotes)
+	p.render(w, r, http.StatusOK, "list", data)
+}
+
+func (p *Pages) Detail(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		p.renderError(w, r, entity.ErrQuoteNotFound)
+		return
+	}
+
+	quote, err := p.service.GetQuote(r.Context(), entity.QuoteID(id))
+	if err != nil {
+		p.renderError(w, r, err)
+		return
+	}
+
+	data := p.newData(r)
+	data.Quote = quote
+	data.Title = quote.Author
+	p.render(w, r, http.StatusOK, "quote", data)
+}
+
+func (p *Pages) Random(w http.ResponseWriter, r *http.Request) {
+	quote, err := p.service.GetRandomQuote(r.Context())
+	if err != nil {
+		p.renderError(w, r, err)
+		return
+	}
+
+	data := p.newData(r)
+	data.Quote = quote
+	data.Random = true
+	data.Title = translate(data.Lang, "random_quote")
+	w.Header().Set("Cache-Control", "no-store")
+	p.render(w, r, http.StatusOK, "quote", data)
+}
+
+func (p *Pages) NotFound(w http.ResponseWriter, r *http.Request) {
+	p.renderProblem(w, r, utils.LocalizedProblem(r, http.StatusNotFound, i18n.CodeRouteNotFound))
+}
+
+func (p *Pages) renderError(w http.ResponseWriter, r *http.Request, err error) {
+	p.renderProblem(w, r, utils.ProblemFromError(r, err))
+}
+
+func (p *Pages) renderProblem(w http.ResponseWriter, r *http.Request, problem utils.Problem) {
+	data := p.newData(r)
+	data.Title = problem.Title
+	data.Status = problem.Status
+	data.Detail = problem.Detail
+	p.render(w, r, problem.Status, "error", data)
+}
+
+func (p *Pages) render(w http.ResponseWriter, r *http.Request, status int, name string, data pageData) {
+	var buf bytes.Buffer
+	if err := p.templates[name].ExecuteTemplate(&buf, "layout", data); err != nil {
+		slog.ErrorContext(r.Context(), "rendering page", "page", name, "error", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Language", string(data.Lang))
+	utils.AddVary(w.Header(), "Accept")
+	utils.AddVary(w.Header(), "Accept-Language")
+	w.WriteHeader(status)
+	_, _ = w.Write(buf.Bytes())
+}
+
+func (p *Pages) newData(r *http.Request) pageData {
+	return pageData{Lang: i18n.FromRequest(r), Prefix: p.prefix}
+}
+
+// paginate cuts the page requested by the "page" query parameter out of
+// quotes. Pages past the end are clamped to the last one.
+func (p *Pages) paginate(r *http.Request, quotes []*entity.Quote) ([]*entity.Quote, pagination) {
+	pages := max(1, (len(quotes)+p.pageSize-1)/p.pageSize)
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	page = min(page, pages)
+
+	result := pagination{Page: page, Pages: pages, Total: len(quotes)}
+	if page > 1 {
+		result.PrevURL = pageURL(r, page-1)
+	}
+	if page < pages {
+		result.NextURL = pageURL(r, page+1)
+	}
+
+	start := (page - 1) * p.pageSize
+	end := min(start+p.pageSize, len(quotes))
+	return quotes[start:end], result
+}
+
+func pageURL(r *http.Request, page int) string {
+	query := r.URL.Query()
+	query.Set("page", strconv.Itoa(page))
+	return r.URL.Path + "?" + query.Encode()
+}
+
+func (p *Pages) quotePath(id entity.QuoteID) string {
+	return fmt.Sprintf("%s/%d", p.prefix, id)
+}
+
+func (p *Pages) authorPath(author string) string {
+	return p.prefix + "/authors/" + url.PathEscape(author)
+}
@@ -0,0 +1,128 @@
+package quote
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	utils "github.com/Korjick/go-http-quote/presentation/http"
+
+	"github.com/Korjick/go-http-quote/application/service"
+	"github.com/Korjick/go-http-quote/domain/apperror"
+	"github.com/Korjick/go-http-quote/presentation/http/quote/card"
+	"github.com/Korjick/go-http-quote/presentation/http/quote/dto"
+)
+
+const (
+	APIVersionHeader = "API-Version"
+
+	vendorMediaTypePrefix = "application/vnd.quotes.v"
+	vendorMediaTypeSuffix = "+json"
+)
+
+var ErrUnsupportedAPIVersion = apperror.New(apperror.KindNotAcceptable, "unsupported_api_version", "unsupported API version")
+
+// VersionedController serves every API version of the quote endpoints. The
+// version is taken from the path ("/v2/quotes") or, for the unversioned
+// prefix, from a vendor media type in the Accept header
+// ("application/vnd.quotes.v2+json"), falling back to dto.DefaultVersion.
+type VersionedController struct {
+	prefix    string
+	versioned map[dto.Version]*Controller
+	bare      map[dto.Version]*Controller
+}
+
+func NewVersionedController(service *service.QuoteService, prefix string, opts ...Option) *VersionedController {
+	o := newOptions(opts)
+	o.cards = card.NewCards(service)
+	controller := &VersionedController{
+		prefix:    prefix,
+		versioned: make(map[dto.Version]*Controller),
+		bare:      make(map[dto.Version]*Controller),
+	}
+	for _, version := range dto.Versions {
+		mapper := dto.MapperFor(version)
+		controller.versioned[version] = newController(service, VersionPrefix(version)+prefix, mapper, o)
+		controller.bare[version] = newController(service, prefix, mapper, o)
+	}
+	return controller
+}
+
+func VersionPrefix(version dto.Version) string {
+	return "/" + version.String()
+}
+
+// VendorMediaType is the Accept media type that selects version on the
+// unversioned prefix.
+func VendorMediaType(version dto.Version) string {
+	return vendorMediaTypePrefix + strconv.Itoa(int(version)) + vendorMediaTypeSuffix
+}
+
+// Prefixes lists the path prefixes the controller has to be mounted on.
+func (c *VersionedController) Prefixes() []string {
+	prefixes := []string{c.prefix}
+	for _, version := range dto.Versions {
+		prefixes = append(prefixes, VersionPrefix(version)+c.prefix)
+	}
+	return prefixes
+}
+
+// Routes lists the routes of every version, as Router.Routes does.
+func (c *VersionedController) Routes() []string {
+	routes := c.bare[dto.DefaultVersion].Routes()
+	for _, version := range dto.Versions {
+		routes = append(routes, c.versioned[version].Routes()...)
+	}
+	return routes
+}
+
+func (c *VersionedController) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	for _, version := range dto.Versions {
+		if hasPathPrefix(r.URL.Path, VersionPrefix(version)+c.prefix) {
+			w.Header().Set(APIVersionHeader, version.String())
+			c.versioned[version].ServeHTTP(w, r)
+			return
+		}
+	}
+
+	utils.AddVary(w.Header(), "Accept")
+	version, err := VersionFromAccept(r.Header.Get("Accept"))
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	w.Header().Set(APIVersionHeader, version.String())
+	c.bare[version].ServeHTTP(w, r)
+}
+
+// VersionFromAccept picks the API version requested through vendor media
+// types in an Accept header. Headers without vendor media types select the
+// default version; an error is returned only if every vendor media type names
+// an unsupported version.
+func VersionFromAccept(accept string) (dto.Version, error) {
+	var unsupported []string
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || !strings.HasPrefix(mediaType, vendorMediaTypePrefix) {
+			continue
+		}
+
+		number := strings.TrimSuffix(strings.TrimPrefix(mediaType, vendorMediaTypePrefix), vendorMediaTypeSuffix)
+		n, err := strconv.Atoi(number)
+		if err == nil && dto.Version(n).Supported() && strings.HasSuffix(mediaType, vendorMediaTypeSuffix) {
+			return dto.Version(n), nil
+		}
+		unsupported = append(unsupported, mediaType)
+	}
+
+	if len(unsupported) > 0 {
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedAPIVersion, strings.Join(unsupported, ", "))
+	}
+	return dto.DefaultVersion, nil
+}
+
+func hasPathPrefix(path, prefix string) bool {
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
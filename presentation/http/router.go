@@ -0,0 +1,160 @@
+package http
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+type route struct {
+	method   string
+	pattern  string
+	segments []string
+	handler  http.Handler
+}
+
+// Router dispatches requests by method and path pattern. Patterns consist of
+// literal segments and "{name}" wildcards whose values are available through
+// Request.PathValue, as with http.ServeMux. Literal segments take precedence
+// over wildcards. Unlike http.ServeMux, the responses for unknown paths and
+// unsupported methods are produced by NotFound and MethodNotAllowed, so they
+// can share the error format of the API.
+type Router struct {
+	routes           []route
+	NotFound         http.Handler
+	MethodNotAllowed http.Handler
+}
+
+func NewRouter() *Router {
+	return &Router{
+		NotFound:         http.NotFoundHandler(),
+		MethodNotAllowed: http.HandlerFunc(defaultMethodNotAllowed),
+	}
+}
+
+func (rt *Router) Handle(method, pattern string, handler http.Handler) {
+	rt.routes = append(rt.routes, route{
+		method:   method,
+		pattern:  pattern,
+		segments: splitPath(pattern),
+		handler:  handler,
+	})
+}
+
+func (rt *Router) HandleFunc(method, pattern string, handler http.HandlerFunc) {
+	rt.Handle(method, pattern, handler)
+}
+
+// Routes lists the registered routes as "METHOD pattern", in registration
+// order.
+func (rt *Router) Routes() []string {
+	routes := make([]string, len(rt.routes))
+	for i, rte := range rt.routes {
+		routes[i] = rte.method + " " + rte.pattern
+	}
+	return routes
+}
+
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	segments := pathSegments(r.URL)
+
+	var best *route
+	bestScore := -1
+	var allowed []string
+	for i := range rt.routes {
+		rte := &rt.routes[i]
+		score, ok := rte.match(segments)
+		if !ok {
+			continue
+		}
+
+		allowed = appendMethod(allowed, rte.method)
+		if rte.allows(r.Method) && score > bestScore {
+			best, bestScore = rte, score
+		}
+	}
+
+	switch {
+	case best != nil:
+		for i, segment := range best.segments {
+			if name, ok := wildcardName(segment); ok {
+				r.SetPathValue(name, segments[i])
+			}
+		}
+		r.Pattern = best.method + " " + best.pattern
+		recordRoute(r, r.Pattern)
+		best.handler.ServeHTTP(w, r)
+	case len(allowed) > 0:
+		slices.Sort(allowed)
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		rt.MethodNotAllowed.ServeHTTP(w, r)
+	default:
+		rt.NotFound.ServeHTTP(w, r)
+	}
+}
+
+// match reports whether the route matches the path and how specific the
+// match is: every literal segment outweighs any number of wildcards.
+func (rte *route) match(segments []string) (int, bool) {
+	if len(segments) != len(rte.segments) {
+		return 0, false
+	}
+
+	score := 0
+	for i, segment := range rte.segments {
+		if _, ok := wildcardName(segment); ok {
+			continue
+		}
+		if segment != segments[i] {
+			return 0, false
+		}
+		score++
+	}
+	return score, true
+}
+
+func (rte *route) allows(method string) bool {
+	return method == rte.method || (method == http.MethodHead && rte.method == http.MethodGet)
+}
+
+func appendMethod(methods []string, method string) []string {
+	if !slices.Contains(methods, method) {
+		methods = append(methods, method)
+	}
+	if method == http.MethodGet && !slices.Contains(methods, http.MethodHead) {
+		methods = append(methods, http.MethodHead)
+	}
+	return methods
+}
+
+func wildcardName(segment string) (string, bool) {
+	if len(segment) > 2 && segment[0] == '{' && segment[len(segment)-1] == '}' {
+		return segment[1 : len(segment)-1], true
+	}
+	return "", false
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// pathSegments splits the escaped path of u and unescapes each segment, so
+// an escaped slash, as in /quotes/authors/AC%2FDC, stays inside its segment.
+func pathSegments(u *url.URL) []string {
+	segments := splitPath(u.EscapedPath())
+	for i, segment := range segments {
+		if unescaped, err := url.PathUnescape(segment); err == nil {
+			segments[i] = unescaped
+		}
+	}
+	return segments
+}
+
+func defaultMethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Config holds the limits of the HTTP server. Routes that need more time
+// than ReadTimeout or WriteTimeout, such as imports and exports, extend the
+// deadlines of their connection themselves.
+type Config struct {
+	Addr              string
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	// ShutdownTimeout is how long in-flight requests may drain after a
+	// shutdown begins. Requests still running then are canceled and their
+	// connections closed.
+	ShutdownTimeout time.Duration
+}
+
+var DefaultConfig = Config{
+	Addr:              ":8080",
+	ReadHeaderTimeout: 5 * time.Second,
+	ReadTimeout:       30 * time.Second,
+	WriteTimeout:      30 * time.Second,
+	IdleTimeout:       2 * time.Minute,
+	MaxHeaderBytes:    64 << 10,
+	ShutdownTimeout:   30 * time.Second,
+}
+
+// Server serves a handler until its context is done, then drains the
+// requests in flight and runs the shutdown hooks.
+type Server struct {
+	config Config
+	server *http.Server
+	hooks  []hook
+}
+
+type hook struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+func New(config Config, handler http.Handler) *Server {
+	return &Server{
+		config: config,
+		server: &http.Server{
+			Addr:              config.Addr,
+			Handler:           handler,
+			ReadHeaderTimeout: config.ReadHeaderTimeout,
+			ReadTimeout:       config.ReadTimeout,
+			WriteTimeout:      config.WriteTimeout,
+			IdleTimeout:       config.IdleTimeout,
+			MaxHeaderBytes:    config.MaxHeaderBytes,
+			ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+		},
+	}
+}
+
+// OnShutdown registers a hook, such as flushing a repository or exporting
+// the last spans, that runs after the requests have drained. Hooks run in
+// the order they were registered, within ShutdownTimeout altogether.
+func (s *Server) OnShutdown(name string, run func(ctx context.Context) error) {
+	s.hooks = append(s.hooks, hook{name: name, run: run})
+}
+
+// Run listens on the configured address and serves until ctx is done.
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.config.Addr)
+	if err != nil {
+		return errors.Join(err, s.runHooks())
+	}
+	return s.Serve(ctx, listener)
+}
+
+// Serve serves on listener until ctx is done or serving fails, then shuts
+// down. It returns once the hooks have run.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	// Requests get a context of their own that outlives ctx, so that
+	// they are not canceled as soon as the shutdown begins.
+	requests, cancelRequests := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelRequests()
+	s.server.BaseContext = func(net.Listener) context.Context { return requests }
+
+	served := make(chan error, 1)
+	go func() { served <- s.server.Serve(listener) }()
+	slog.InfoContext(ctx, "server started", "addr", listener.Addr().String())
+
+	var err error
+	select {
+	case err = <-served:
+	case <-ctx.Done():
+		err = s.drain(cancelRequests)
+		<-served
+	}
+	return errors.Join(err, s.runHooks())
+}
+
+// drain stops accepting connections and waits for the requests in flight.
+// Past ShutdownTimeout it cancels them and closes their connections.
+func (s *Server) drain(cancelRequests context.CancelFunc) error {
+	slog.Info("shutting down", "timeout", s.config.ShutdownTimeout.String())
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
+	defer cancel()
+	if err := s.server.Shutdown(ctx); err != nil {
+		slog.Warn("requests did not drain in time, canceling them", "error", err)
+		cancelRequests()
+		return s.server.Close()
+	}
+	return nil
+}
+
+func (s *Server) runHooks() error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	for _, hook := range s.hooks {
+		if err := hook.run(ctx); err != nil {
+			slog.Error("shutdown hook failed", "hook", hook.name, "error", err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,66 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Korjick/go-http-quote/domain/apperror"
+)
+
+// StatusClientClosedRequest is the unregistered status nginx logs for
+// requests the client abandoned. The client never sees it, but it keeps
+// such requests apart from server errors in logs and metrics.
+const StatusClientClosedRequest = 499
+
+// timeoutGrace is how long the connection outlives the context of a request,
+// which leaves time to write the response about the timeout.
+const timeoutGrace = 5 * time.Second
+
+var (
+	ErrTimeout  = apperror.New(apperror.KindTimeout, "timeout", "the request took too long, retry later")
+	ErrCanceled = apperror.New(apperror.KindCanceled, "request_canceled", "the request was canceled by the client")
+)
+
+// WithTimeout runs handler with a context that expires after timeout, so
+// that the service and repository give up on the request. The read and write
+// deadlines of the connection move to shortly after it, which lets routes
+// outlast the server timeouts. A timeout of zero or less means no limit: the
+// context keeps the deadline of the request, if any, and the connection
+// deadlines are cleared.
+func WithTimeout(timeout time.Duration, handler http.HandlerFunc) http.HandlerFunc {
+	if timeout <= 0 {
+		return func(w http.ResponseWriter, r *http.Request) {
+			rc := http.NewResponseController(w)
+			_ = rc.SetReadDeadline(time.Time{})
+			_ = rc.SetWriteDeadline(time.Time{})
+			handler(w, r)
+		}
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		deadline := time.Now().Add(timeout)
+		// Writers that cannot change deadlines, such as recorders in
+		// tests, keep the ones of the server.
+		rc := http.NewResponseController(w)
+		_ = rc.SetReadDeadline(deadline.Add(timeoutGrace))
+		_ = rc.SetWriteDeadline(deadline.Add(timeoutGrace))
+
+		ctx, cancel := context.WithDeadline(r.Context(), deadline)
+		defer cancel()
+		handler(w, r.WithContext(ctx))
+	}
+}
+
+// contextError replaces the errors of an expired or canceled context, which
+// the layers below return as they are, with their application errors.
+func contextError(err error) error {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrTimeout
+	case errors.Is(err, context.Canceled):
+		return ErrCanceled
+	default:
+		return err
+	}
+}
@@ -0,0 +1,106 @@
+package service_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Korjick/go-http-quote/application/service"
+	"github.com/Korjick/go-http-quote/domain/auth"
+	"github.com/Korjick/go-http-quote/infrastructure/repository/in_memory"
+)
+
+func setupAPIKeyService() *service.APIKeyService {
+	return service.NewAPIKeyService(in_memory.NewInMemoryAPIKeyRepository())
+}
+
+func TestAPIKeyService_CreateAndAuthenticate(t *testing.T) {
+	svc := setupAPIKeyService()
+
+	key, secret, err := svc.CreateAPIKey(auth.System, "editor", auth.RoleContributor)
+	if err != nil {
+		t.Fatalf("CreateAPIKey() error = %v", err)
+	}
+	if !strings.HasPrefix(secret, key.Prefix) || key.Hash == secret || strings.Contains(key.Hash, secret) {
+		t.Errorf("CreateAPIKey() = prefix %q, hash %q for secret %q", key.Prefix, key.Hash, secret)
+	}
+	if key.CreatedBy != auth.System.Subject {
+		t.Errorf("CreatedBy = %q, want %q", key.CreatedBy, auth.System.Subject)
+	}
+
+	principal, err := svc.Authenticate(secret)
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	want := auth.Principal{Subject: "api-key:" + key.ID.String(), Role: auth.RoleContributor}
+	if principal != want {
+		t.Errorf("Authenticate() = %+v, want %+v", principal, want)
+	}
+
+	if _, err := svc.Authenticate(secret + "x"); !errors.Is(err, auth.ErrInvalidAPIKey) {
+		t.Errorf("Authenticate(wrong) error = %v, want %v", err, auth.ErrInvalidAPIKey)
+	}
+}
+
+func TestAPIKeyService_Validation(t *testing.T) {
+	svc := setupAPIKeyService()
+
+	if _, _, err := svc.CreateAPIKey(auth.System, " ", auth.RoleReader); !errors.Is(err, auth.ErrEmptyAPIKeyName) {
+		t.Errorf("CreateAPIKey(empty name) error = %v, want %v", err, auth.ErrEmptyAPIKeyName)
+	}
+	if _, _, err := svc.CreateAPIKey(auth.System, "root", "root"); !errors.Is(err, auth.ErrUnknownRole) {
+		t.Errorf("CreateAPIKey(unknown role) error = %v, want %v", err, auth.ErrUnknownRole)
+	}
+	_, err := svc.RegisterAPIKey(auth.System, "short", auth.RoleAdmin, "secret")
+	if !errors.Is(err, auth.ErrAPIKeyTooShort) {
+		t.Errorf("RegisterAPIKey(short) error = %v, want %v", err, auth.ErrAPIKeyTooShort)
+	}
+	if want := "API key is too short: it must be at least 16 characters"; err == nil || err.Error() != want {
+		t.Errorf("RegisterAPIKey(short) error = %v, want %q", err, want)
+	}
+
+	if _, _, err := svc.CreateAPIKey(auth.System, "editor", auth.RoleReader); err != nil {
+		t.Fatalf("CreateAPIKey() error = %v", err)
+	}
+	if _, _, err := svc.CreateAPIKey(auth.System, "editor", auth.RoleReader); !errors.Is(err, auth.ErrAPIKeyNameExists) {
+		t.Errorf("CreateAPIKey(duplicate) error = %v, want %v", err, auth.ErrAPIKeyNameExists)
+	}
+
+	secret := "quotes-admin-secret-0123456789"
+	if _, err := svc.RegisterAPIKey(auth.System, "first", auth.RoleAdmin, secret); err != nil {
+		t.Fatalf("RegisterAPIKey() error = %v", err)
+	}
+	if _, err := svc.RegisterAPIKey(auth.System, "second", auth.RoleReader, secret); !errors.Is(err, auth.ErrAPIKeyInUse) {
+		t.Errorf("RegisterAPIKey(same secret) error = %v, want %v", err, auth.ErrAPIKeyInUse)
+	}
+	if principal, err := svc.Authenticate(secret); err != nil || principal.Role != auth.RoleAdmin {
+		t.Errorf("Authenticate() = %v, %v, want the first key's admin principal", principal, err)
+	}
+}
+
+func TestAPIKeyService_RequiresAdmin(t *testing.T) {
+	svc := setupAPIKeyService()
+	moderator := auth.Principal{Subject: "api-key:1", Role: auth.RoleModerator}
+
+	if _, _, err := svc.CreateAPIKey(moderator, "editor", auth.RoleReader); !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("CreateAPIKey(moderator) error = %v, want %v", err, auth.ErrForbidden)
+	}
+	if _, err := svc.ListAPIKeys(auth.Anonymous); !errors.Is(err, auth.ErrUnauthenticated) {
+		t.Errorf("ListAPIKeys(anonymous) error = %v, want %v", err, auth.ErrUnauthenticated)
+	}
+}
+
+func TestAPIKeyService_Revoke(t *testing.T) {
+	svc := setupAPIKeyService()
+	key, secret, _ := svc.CreateAPIKey(auth.System, "editor", auth.RoleContributor)
+
+	if err := svc.RevokeAPIKey(auth.System, key.ID); err != nil {
+		t.Fatalf("RevokeAPIKey() error = %v", err)
+	}
+	if _, err := svc.Authenticate(secret); !errors.Is(err, auth.ErrInvalidAPIKey) {
+		t.Errorf("Authenticate(revoked) error = %v, want %v", err, auth.ErrInvalidAPIKey)
+	}
+	if err := svc.RevokeAPIKey(auth.System, key.ID); !errors.Is(err, auth.ErrAPIKeyNotFound) {
+		t.Errorf("RevokeAPIKey(again) error = %v, want %v", err, auth.ErrAPIKeyNotFound)
+	}
+}
@@ -1,11 +1,17 @@
 package service_test
 
 import (
+	"context"
 	"errors"
+	"io"
+	"log/slog"
 	"testing"
+	"time"
 
 	"github.com/Korjick/go-http-quote/application/service"
+	"github.com/Korjick/go-http-quote/domain/auth"
 	"github.com/Korjick/go-http-quote/domain/quote/entity"
+	"github.com/Korjick/go-http-quote/domain/quote/repository"
 	"github.com/Korjick/go-http-quote/infrastructure/repository/in_memory"
 )
 
@@ -16,7 +22,7 @@ func TestQuoteService_CreateQuoteValidInput(t *testing.T) {
 	author := "Albert Einstein"
 	text := "Imagination is more important than knowledge."
 
-	quote, err := svc.CreateQuote(author, text)
+	quote, err := svc.CreateQuote(context.Background(), auth.System, author, text)
 
 	if err != nil {
 		t.Errorf("CreateQuote() unexpected error = %v", err)
@@ -48,7 +54,7 @@ func TestQuoteService_CreateQuoteEmptyAuthor(t *testing.T) {
 	author := ""
 	text := "Some text"
 
-	quote, err := svc.CreateQuote(author, text)
+	quote, err := svc.CreateQuote(context.Background(), auth.System, author, text)
 
 	if err == nil {
 		t.Error("CreateQuote() expected error but got none")
@@ -71,7 +77,7 @@ func TestQuoteService_CreateQuoteEmptyText(t *testing.T) {
 	author := "Author"
 	text := ""
 
-	quote, err := svc.CreateQuote(author, text)
+	quote, err := svc.CreateQuote(context.Background(), auth.System, author, text)
 
 	if err == nil {
 		t.Error("CreateQuote() expected error but got none")
@@ -91,7 +97,7 @@ func TestQuoteService_GetAllQuotes(t *testing.T) {
 	repo := in_memory.NewInMemoryQuoteRepository()
 	svc := service.NewQuoteService(repo)
 
-	quotes, err := svc.GetAllQuotes()
+	quotes, err := svc.GetAllQuotes(context.Background())
 	if err != nil {
 		t.Errorf("GetAllQuotes() error = %v, want nil", err)
 	}
@@ -99,17 +105,17 @@ func TestQuoteService_GetAllQuotes(t *testing.T) {
 		t.Errorf("GetAllQuotes() returned %d quotes, want 0", len(quotes))
 	}
 
-	_, err = svc.CreateQuote("Author 1", "Quote 1")
+	_, err = svc.CreateQuote(context.Background(), auth.System, "Author 1", "Quote 1")
 	if err != nil {
 		t.Fatalf("CreateQuote() error = %v", err)
 	}
 
-	_, err = svc.CreateQuote("Author 2", "Quote 2")
+	_, err = svc.CreateQuote(context.Background(), auth.System, "Author 2", "Quote 2")
 	if err != nil {
 		t.Fatalf("CreateQuote() error = %v", err)
 	}
 
-	quotes, err = svc.GetAllQuotes()
+	quotes, err = svc.GetAllQuotes(context.Background())
 	if err != nil {
 		t.Errorf("GetAllQuotes() error = %v, want nil", err)
 	}
@@ -122,22 +128,22 @@ func TestQuoteService_GetQuotesByAuthor(t *testing.T) {
 	repo := in_memory.NewInMemoryQuoteRepository()
 	svc := service.NewQuoteService(repo)
 
-	_, err := svc.CreateQuote("Einstein", "Quote 1")
+	_, err := svc.CreateQuote(context.Background(), auth.System, "Einstein", "Quote 1")
 	if err != nil {
 		t.Fatalf("CreateQuote() error = %v", err)
 	}
 
-	_, err = svc.CreateQuote("Einstein", "Quote 2")
+	_, err = svc.CreateQuote(context.Background(), auth.System, "Einstein", "Quote 2")
 	if err != nil {
 		t.Fatalf("CreateQuote() error = %v", err)
 	}
 
-	_, err = svc.CreateQuote("Jobs", "Quote 3")
+	_, err = svc.CreateQuote(context.Background(), auth.System, "Jobs", "Quote 3")
 	if err != nil {
 		t.Fatalf("CreateQuote() error = %v", err)
 	}
 
-	einsteinQuotes, err := svc.GetQuotesByAuthor("Einstein")
+	einsteinQuotes, err := svc.GetQuotesByAuthor(context.Background(), "Einstein")
 	if err != nil {
 		t.Errorf("GetQuotesByAuthor() error = %v, want nil", err)
 	}
@@ -145,7 +151,7 @@ func TestQuoteService_GetQuotesByAuthor(t *testing.T) {
 		t.Errorf("GetQuotesByAuthor('Einstein') returned %d quotes, want 2", len(einsteinQuotes))
 	}
 
-	jobsQuotes, err := svc.GetQuotesByAuthor("Jobs")
+	jobsQuotes, err := svc.GetQuotesByAuthor(context.Background(), "Jobs")
 	if err != nil {
 		t.Errorf("GetQuotesByAuthor() error = %v, want nil", err)
 	}
@@ -153,7 +159,7 @@ func TestQuoteService_GetQuotesByAuthor(t *testing.T) {
 		t.Errorf("GetQuotesByAuthor('Jobs') returned %d quotes, want 1", len(jobsQuotes))
 	}
 
-	nonExistentQuotes, err := svc.GetQuotesByAuthor("Non-existent")
+	nonExistentQuotes, err := svc.GetQuotesByAuthor(context.Background(), "Non-existent")
 	if err != nil {
 		t.Errorf("GetQuotesByAuthor() error = %v, want nil", err)
 	}
@@ -162,21 +168,71 @@ func TestQuoteService_GetQuotesByAuthor(t *testing.T) {
 	}
 }
 
+func TestQuoteService_SearchQuotes(t *testing.T) {
+	repo := in_memory.NewInMemoryQuoteRepository()
+	svc := service.NewQuoteService(repo)
+
+	svc.CreateQuote(context.Background(), auth.System, "Einstein", "Imagination is more important than knowledge.")
+	svc.CreateQuote(context.Background(), auth.System, "Einstein", "Life is like riding a bicycle.")
+	svc.CreateQuote(context.Background(), auth.System, "Jobs", "Stay hungry, stay foolish.")
+
+	tests := []struct {
+		author string
+		query  string
+		want   int
+	}{
+		{"", "", 3},
+		{"", "STAY", 1},
+		{"", "einstein", 2},
+		{"einstein", "", 2},
+		{"Einstein", "bicycle", 1},
+		{"Jobs", "bicycle", 0},
+	}
+
+	for _, tt := range tests {
+		quotes, err := svc.SearchQuotes(context.Background(), tt.author, tt.query)
+		if err != nil {
+			t.Errorf("SearchQuotes(%q, %q) error = %v", tt.author, tt.query, err)
+			continue
+		}
+		if len(quotes) != tt.want {
+			t.Errorf("SearchQuotes(%q, %q) returned %d quotes, want %d", tt.author, tt.query, len(quotes), tt.want)
+		}
+	}
+}
+
+func TestQuoteService_RecentQuotes(t *testing.T) {
+	repo := in_memory.NewInMemoryQuoteRepository()
+	svc := service.NewQuoteService(repo)
+
+	for _, author := range []string{"Einstein", "Jobs", "Einstein", "Einstein"} {
+		svc.CreateQuote(context.Background(), auth.System, author, "Quote")
+	}
+
+	quotes, err := svc.RecentQuotes(context.Background(), "einstein", 2)
+	if err != nil {
+		t.Fatalf("RecentQuotes() error = %v", err)
+	}
+	if len(quotes) != 2 || quotes[0].ID != 4 || quotes[1].ID != 3 {
+		t.Errorf("RecentQuotes() = %v, want quotes 4 and 3", quotes)
+	}
+}
+
 func TestQuoteService_GetRandomQuote(t *testing.T) {
 	repo := in_memory.NewInMemoryQuoteRepository()
 	svc := service.NewQuoteService(repo)
 
-	_, err := svc.GetRandomQuote()
+	_, err := svc.GetRandomQuote(context.Background())
 	if !errors.Is(err, entity.ErrQuoteNotFound) {
 		t.Errorf("GetRandomQuote() error = %v, want %v", err, entity.ErrQuoteNotFound)
 	}
 
-	createdQuote, err := svc.CreateQuote("Test Author", "Test Quote")
+	createdQuote, err := svc.CreateQuote(context.Background(), auth.System, "Test Author", "Test Quote")
 	if err != nil {
 		t.Fatalf("CreateQuote() error = %v", err)
 	}
 
-	randomQuote, err := svc.GetRandomQuote()
+	randomQuote, err := svc.GetRandomQuote(context.Background())
 	if err != nil {
 		t.Errorf("GetRandomQuote() error = %v, want nil", err)
 	}
@@ -186,14 +242,14 @@ func TestQuoteService_GetRandomQuote(t *testing.T) {
 	}
 
 	for i := 2; i <= 10; i++ {
-		_, err = svc.CreateQuote("Author", "Quote")
+		_, err = svc.CreateQuote(context.Background(), auth.System, "Author", "Quote")
 		if err != nil {
 			t.Fatalf("CreateQuote() error = %v", err)
 		}
 	}
 
 	for i := 0; i < 5; i++ {
-		randomQuote, err = svc.GetRandomQuote()
+		randomQuote, err = svc.GetRandomQuote(context.Background())
 		if err != nil {
 			t.Errorf("GetRandomQuote() error = %v, want nil", err)
 		}
@@ -207,22 +263,22 @@ func TestQuoteService_DeleteQuote(t *testing.T) {
 	repo := in_memory.NewInMemoryQuoteRepository()
 	svc := service.NewQuoteService(repo)
 
-	err := svc.DeleteQuote(999)
+	err := svc.DeleteQuote(context.Background(), auth.System, 999)
 	if !errors.Is(err, entity.ErrQuoteNotFound) {
 		t.Errorf("DeleteQuote() error = %v, want %v", err, entity.ErrQuoteNotFound)
 	}
 
-	createdQuote, err := svc.CreateQuote("Test Author", "Test Quote")
+	createdQuote, err := svc.CreateQuote(context.Background(), auth.System, "Test Author", "Test Quote")
 	if err != nil {
 		t.Fatalf("CreateQuote() error = %v", err)
 	}
 
-	err = svc.DeleteQuote(createdQuote.ID)
+	err = svc.DeleteQuote(context.Background(), auth.System, createdQuote.ID)
 	if err != nil {
 		t.Errorf("DeleteQuote() error = %v, want nil", err)
 	}
 
-	quotes, err := svc.GetAllQuotes()
+	quotes, err := svc.GetAllQuotes(context.Background())
 	if err != nil {
 		t.Errorf("GetAllQuotes() error = %v", err)
 	}
@@ -230,3 +286,394 @@ func TestQuoteService_DeleteQuote(t *testing.T) {
 		t.Errorf("After deletion, GetAllQuotes() returned %d quotes, want 0", len(quotes))
 	}
 }
+
+type sliceSource struct {
+	drafts []entity.QuoteDraft
+	errs   map[int]error
+	pos    int
+}
+
+func (s *sliceSource) Next() (entity.QuoteDraft, error) {
+	if s.pos >= len(s.drafts) {
+		return entity.QuoteDraft{}, io.EOF
+	}
+	s.pos++
+	if err, ok := s.errs[s.pos]; ok {
+		return entity.QuoteDraft{}, err
+	}
+	return s.drafts[s.pos-1], nil
+}
+
+func TestQuoteService_ImportQuotesAtomic(t *testing.T) {
+	repo := in_memory.NewInMemoryQuoteRepository()
+	svc := service.NewQuoteService(repo)
+
+	src := &sliceSource{drafts: []entity.QuoteDraft{
+		{Author: "Einstein", Text: "Quote 1"},
+		{Author: "", Text: "Quote 2"},
+		{Author: "Jobs", Text: "Quote 3"},
+	}}
+
+	report, err := svc.ImportQuotes(context.Background(), auth.System, src, service.ImportModeAtomic)
+	if err != nil {
+		t.Fatalf("ImportQuotes() error = %v", err)
+	}
+	if report.Total != 3 || report.Imported != 0 || report.Failed() != 1 {
+		t.Errorf("ImportQuotes() report = %+v, want total 3, imported 0, failed 1", report)
+	}
+	if report.Errors[0].Row != 2 || !errors.Is(report.Errors[0].Err, entity.ErrEmptyAuthor) {
+		t.Errorf("ImportQuotes() row error = %+v, want row 2 %v", report.Errors[0], entity.ErrEmptyAuthor)
+	}
+
+	quotes, _ := svc.GetAllQuotes(context.Background())
+	if len(quotes) != 0 {
+		t.Errorf("After failed atomic import, found %d quotes, want 0", len(quotes))
+	}
+
+	src = &sliceSource{drafts: []entity.QuoteDraft{
+		{Author: "Einstein", Text: "Quote 1"},
+		{Author: "Jobs", Text: "Quote 3"},
+	}}
+	report, err = svc.ImportQuotes(context.Background(), auth.System, src, service.ImportModeAtomic)
+	if err != nil {
+		t.Fatalf("ImportQuotes() error = %v", err)
+	}
+	if report.Imported != 2 {
+		t.Errorf("ImportQuotes() imported = %d, want 2", report.Imported)
+	}
+}
+
+func TestQuoteService_ImportQuotesBestEffort(t *testing.T) {
+	repo := in_memory.NewInMemoryQuoteRepository()
+	svc := service.NewQuoteService(repo)
+
+	src := &sliceSource{
+		drafts: []entity.QuoteDraft{
+			{Author: "Einstein", Text: "Quote 1"},
+			{},
+			{Author: "Jobs", Text: ""},
+			{Author: "Jobs", Text: "Quote 4"},
+		},
+		errs: map[int]error{2: &service.RowError{Row: 2, Err: errors.New("malformed")}},
+	}
+
+	report, err := svc.ImportQuotes(context.Background(), auth.System, src, service.ImportModeBestEffort)
+	if err != nil {
+		t.Fatalf("ImportQuotes() error = %v", err)
+	}
+	if report.Total != 4 || report.Imported != 2 || report.Failed() != 2 {
+		t.Errorf("ImportQuotes() report = %+v, want total 4, imported 2, failed 2", report)
+	}
+
+	quotes, _ := svc.GetAllQuotes(context.Background())
+	if len(quotes) != 2 {
+		t.Errorf("After best-effort import, found %d quotes, want 2", len(quotes))
+	}
+}
+
+func TestQuoteService_ImportQuotesFatalSourceError(t *testing.T) {
+	repo := in_memory.NewInMemoryQuoteRepository()
+	svc := service.NewQuoteService(repo)
+
+	src := &sliceSource{
+		drafts: []entity.QuoteDraft{{Author: "Einstein", Text: "Quote 1"}, {}, {Author: "Jobs", Text: "Quote 3"}},
+		errs:   map[int]error{2: errors.New("unexpected EOF")},
+	}
+
+	report, err := svc.ImportQuotes(context.Background(), auth.System, src, service.ImportModeAtomic)
+	if err != nil {
+		t.Fatalf("ImportQuotes() error = %v", err)
+	}
+	if report.Total != 2 || report.Imported != 0 || report.Failed() != 1 {
+		t.Errorf("ImportQuotes() report = %+v, want total 2, imported 0, failed 1", report)
+	}
+}
+
+func TestQuoteService_ExportQuotes(t *testing.T) {
+	repo := in_memory.NewInMemoryQuoteRepository()
+	svc := service.NewQuoteService(repo)
+
+	for i := 0; i < 1200; i++ {
+		if _, err := svc.CreateQuote(context.Background(), auth.System, "Author", "Quote"); err != nil {
+			t.Fatalf("CreateQuote() error = %v", err)
+		}
+	}
+
+	snapshot, err := svc.Snapshot(context.Background())
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+
+	var visited []entity.QuoteID
+	err = svc.ExportQuotes(context.Background(), snapshot, func(quote *entity.Quote) error {
+		if len(visited) == 600 {
+			_, _ = svc.CreateQuote(context.Background(), auth.System, "Late Author", "Late Quote")
+		}
+		visited = append(visited, quote.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ExportQuotes() error = %v", err)
+	}
+
+	if len(visited) != 1200 {
+		t.Errorf("ExportQuotes() visited %d quotes, want 1200", len(visited))
+	}
+	for i, id := range visited {
+		if id != entity.QuoteID(i+1) {
+			t.Fatalf("ExportQuotes() visited ID %v at position %d, want %v", id, i, i+1)
+		}
+	}
+}
+
+func TestQuoteService_ExportQuotesDeletedDuringExport(t *testing.T) {
+	repo := in_memory.NewInMemoryQuoteRepository()
+	svc := service.NewQuoteService(repo)
+
+	for i := 0; i < 1200; i++ {
+		_, _ = svc.CreateQuote(context.Background(), auth.System, "Author", "Quote")
+	}
+	snapshot, _ := svc.Snapshot(context.Background())
+
+	visited := 0
+	err := svc.ExportQuotes(context.Background(), snapshot, func(*entity.Quote) error {
+		if visited++; visited == 100 {
+			_ = svc.DeleteQuote(context.Background(), auth.System, 900)
+		}
+		return nil
+	})
+	if !errors.Is(err, service.ErrSnapshotChanged) {
+		t.Errorf("ExportQuotes() error = %v, want %v", err, service.ErrSnapshotChanged)
+	}
+	if visited != 1199 {
+		t.Errorf("ExportQuotes() visited %d quotes, want the 1199 left", visited)
+	}
+}
+
+func TestQuoteService_ExportQuotesVisitError(t *testing.T) {
+	repo := in_memory.NewInMemoryQuoteRepository()
+	svc := service.NewQuoteService(repo)
+
+	_, _ = svc.CreateQuote(context.Background(), auth.System, "Author", "Quote")
+	snapshot, _ := svc.Snapshot(context.Background())
+
+	wantErr := errors.New("client went away")
+	err := svc.ExportQuotes(context.Background(), snapshot, func(*entity.Quote) error { return wantErr })
+	if !errors.Is(err, wantErr) {
+		t.Errorf("ExportQuotes() error = %v, want %v", err, wantErr)
+	}
+}
+
+func TestQuoteService_ExportQuotesCanceled(t *testing.T) {
+	repo := in_memory.NewInMemoryQuoteRepository()
+	svc := service.NewQuoteService(repo)
+
+	for i := 0; i < 1200; i++ {
+		_, _ = svc.CreateQuote(context.Background(), auth.System, "Author", "Quote")
+	}
+	snapshot, _ := svc.Snapshot(context.Background())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	visited := 0
+	err := svc.ExportQuotes(ctx, snapshot, func(*entity.Quote) error {
+		visited++
+		if visited == 100 {
+			cancel()
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("ExportQuotes() error = %v, want %v", err, context.Canceled)
+	}
+	if visited != 500 {
+		t.Errorf("ExportQuotes() visited %d quotes, want the first page of 500", visited)
+	}
+}
+
+func TestQuoteService_SearchQuotesDeadline(t *testing.T) {
+	repo := in_memory.NewInMemoryQuoteRepository()
+	svc := service.NewQuoteService(repo)
+	_, _ = svc.CreateQuote(context.Background(), auth.System, "Author", "Quote")
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	if _, err := svc.SearchQuotes(ctx, "", "quote"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("SearchQuotes() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestQuoteService_ExecuteBatch(t *testing.T) {
+	repo := in_memory.NewInMemoryQuoteRepository()
+	svc := service.NewQuoteService(repo)
+
+	_, err := svc.ExecuteBatch(context.Background(), auth.System, nil)
+	if !errors.Is(err, service.ErrEmptyBatch) {
+		t.Errorf("ExecuteBatch() error = %v, want %v", err, service.ErrEmptyBatch)
+	}
+
+	ops := make([]repository.Operation, service.MaxBatchSize+1)
+	_, err = svc.ExecuteBatch(context.Background(), auth.System, ops)
+	if !errors.Is(err, service.ErrBatchTooLarge) {
+		t.Errorf("ExecuteBatch() error = %v, want %v", err, service.ErrBatchTooLarge)
+	}
+
+	results, err := svc.ExecuteBatch(context.Background(), auth.System, []repository.Operation{
+		{Kind: repository.OperationCreate, Draft: entity.QuoteDraft{Author: "Author", Text: "Quote"}},
+	})
+	if err != nil {
+		t.Fatalf("ExecuteBatch() error = %v, want nil", err)
+	}
+	if len(results) != 1 || results[0].Quote == nil {
+		t.Errorf("ExecuteBatch() results = %+v, want one created quote", results)
+	}
+}
+
+func TestQuoteService_RecordsCreator(t *testing.T) {
+	svc := service.NewQuoteService(in_memory.NewInMemoryQuoteRepository())
+	editor := auth.Principal{Subject: "user-42", Role: auth.RoleContributor}
+
+	quote, err := svc.CreateQuote(context.Background(), editor, "Author", "Quote")
+	if err != nil {
+		t.Fatalf("CreateQuote() error = %v", err)
+	}
+	if quote.CreatedBy != "user-42" {
+		t.Errorf("CreateQuote() CreatedBy = %q, want user-42", quote.CreatedBy)
+	}
+
+	results, err := svc.ExecuteBatch(context.Background(), editor, []repository.Operation{
+		{Kind: repository.OperationCreate, Draft: entity.QuoteDraft{Author: "Author", Text: "Batch"}},
+	})
+	if err != nil {
+		t.Fatalf("ExecuteBatch() error = %v", err)
+	}
+	if results[0].Quote.CreatedBy != "user-42" {
+		t.Errorf("ExecuteBatch() CreatedBy = %q, want user-42", results[0].Quote.CreatedBy)
+	}
+}
+
+func TestQuoteService_OwnershipRules(t *testing.T) {
+	svc := service.NewQuoteService(in_memory.NewInMemoryQuoteRepository())
+	alice := auth.Principal{Subject: "alice", Role: auth.RoleContributor}
+	bob := auth.Principal{Subject: "bob", Role: auth.RoleContributor}
+	moderator := auth.Principal{Subject: "carol", Role: auth.RoleModerator}
+
+	first, _ := svc.CreateQuote(context.Background(), alice, "Author", "First")
+	second, _ := svc.CreateQuote(context.Background(), alice, "Author", "Second")
+	if first.Owner != "alice" {
+		t.Errorf("CreateQuote() Owner = %q, want alice", first.Owner)
+	}
+
+	own, err := svc.OwnQuotes(context.Background(), alice)
+	if err != nil || len(own) != 2 {
+		t.Errorf("OwnQuotes(alice) = %d quotes, %v, want 2", len(own), err)
+	}
+	if own, _ := svc.OwnQuotes(context.Background(), bob); len(own) != 0 {
+		t.Errorf("OwnQuotes(bob) = %d quotes, want 0", len(own))
+	}
+	if _, err := svc.OwnQuotes(context.Background(), auth.Anonymous); !errors.Is(err, auth.ErrUnauthenticated) {
+		t.Errorf("OwnQuotes(anonymous) error = %v, want %v", err, auth.ErrUnauthenticated)
+	}
+
+	if err := svc.DeleteQuote(context.Background(), bob, first.ID); !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("DeleteQuote() by another contributor error = %v, want %v", err, auth.ErrForbidden)
+	}
+	if _, err := svc.ExecuteBatch(context.Background(), bob, []repository.Operation{{Kind: repository.OperationDelete, ID: second.ID}}); !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("ExecuteBatch() deleting another's quote error = %v, want %v", err, auth.ErrForbidden)
+	}
+	results, err := svc.ExecuteBatch(context.Background(), bob, []repository.Operation{{Kind: repository.OperationDelete, ID: second.ID + 1}})
+	if !errors.Is(err, entity.ErrBatchAborted) || !errors.Is(results[0].Err, entity.ErrQuoteNotFound) {
+		t.Errorf("ExecuteBatch() deleting a missing quote = %v, %v, want a failed operation", results, err)
+	}
+	if err := svc.DeleteQuote(context.Background(), alice, first.ID); err != nil {
+		t.Errorf("DeleteQuote() by owner error = %v", err)
+	}
+	if err := svc.DeleteQuote(context.Background(), moderator, second.ID); err != nil {
+		t.Errorf("DeleteQuote() by moderator error = %v", err)
+	}
+}
+
+// staleReadRepository reports every quote as owned by owner, as a read
+// racing with a change of the quote would.
+type staleReadRepository struct {
+	repository.QuoteRepository
+	owner string
+}
+
+func (r staleReadRepository) GetByID(ctx context.Context, id entity.QuoteID) (*entity.Quote, error) {
+	quote, err := r.QuoteRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	stale := *quote
+	stale.Owner = r.owner
+	return &stale, nil
+}
+
+func TestQuoteService_DeleteQuoteChecksOwnerAtomically(t *testing.T) {
+	repo := in_memory.NewInMemoryQuoteRepository()
+	alice := auth.Principal{Subject: "alice", Role: auth.RoleContributor}
+	bob := auth.Principal{Subject: "bob", Role: auth.RoleContributor}
+	quote, err := service.NewQuoteService(repo).CreateQuote(context.Background(), alice, "Author", "Text")
+	if err != nil {
+		t.Fatalf("CreateQuote() error = %v", err)
+	}
+
+	svc := service.NewQuoteService(staleReadRepository{QuoteRepository: repo, owner: "bob"})
+	if err := svc.DeleteQuote(context.Background(), bob, quote.ID); !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("DeleteQuote() error = %v, want %v", err, auth.ErrForbidden)
+	}
+	if _, err := repo.GetByID(context.Background(), quote.ID); err != nil {
+		t.Errorf("Quote was deleted on a stale ownership check: %v", err)
+	}
+}
+
+type recordedSpan struct {
+	name   string
+	parent string
+	err    error
+}
+
+// recordingTracer records the spans and the span each was started under.
+type recordingTracer struct {
+	spans []*recordedSpan
+}
+
+type currentSpanKey struct{}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, _ ...slog.Attr) (context.Context, service.Span) {
+	parent, _ := ctx.Value(currentSpanKey{}).(string)
+	span := &recordedSpan{name: name, parent: parent}
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, currentSpanKey{}, name), span
+}
+
+func (s *recordedSpan) SetName(name string)        { s.name = name }
+func (s *recordedSpan) SetAttributes(...slog.Attr) {}
+func (s *recordedSpan) End(err error)              { s.err = err }
+
+func TestQuoteService_TracesOperations(t *testing.T) {
+	tracer := &recordingTracer{}
+	svc := service.NewQuoteService(in_memory.NewInMemoryQuoteRepository(), service.WithTracer(tracer))
+
+	if _, err := svc.RecentQuotes(context.Background(), "", 5); err != nil {
+		t.Fatalf("RecentQuotes() error = %v", err)
+	}
+	if err := svc.DeleteQuote(context.Background(), auth.System, 99); err == nil {
+		t.Fatal("DeleteQuote() of a missing quote succeeded")
+	}
+
+	want := []recordedSpan{
+		{name: "QuoteService.RecentQuotes"},
+		{name: "QuoteService.SearchQuotes", parent: "QuoteService.RecentQuotes"},
+		{name: "QuoteService.DeleteQuote", err: entity.ErrQuoteNotFound},
+	}
+	if len(tracer.spans) != len(want) {
+		t.Fatalf("Recorded %d spans, want %d", len(tracer.spans), len(want))
+	}
+	for i, span := range tracer.spans {
+		if span.name != want[i].name || span.parent != want[i].parent || !errors.Is(span.err, want[i].err) {
+			t.Errorf("Span %d = %+v, want %+v", i, *span, want[i])
+		}
+	}
+}
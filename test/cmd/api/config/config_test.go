@@ -0,0 +1,268 @@
+package config_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Korjick/go-http-quote/cmd/api/config"
+	"github.com/Korjick/go-http-quote/domain/auth"
+	"github.com/Korjick/go-http-quote/presentation/http/middleware"
+	"github.com/Korjick/go-http-quote/presentation/http/quote"
+	"github.com/Korjick/go-http-quote/presentation/http/quote/feed"
+	"github.com/Korjick/go-http-quote/presentation/http/server"
+)
+
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := vars[name]
+		return value, ok
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	loaded, err := config.Load(nil, env(nil))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	cfg := loaded.Config
+	if cfg.Server.Addr() != ":8080" || cfg.Quotes.Prefix != "/quotes" || cfg.Quotes.Backend != "memory" {
+		t.Errorf("Load() = %+v, want the defaults", cfg)
+	}
+	if loaded.PrintConfig {
+		t.Error("PrintConfig is set without the flag")
+	}
+
+	// The defaults are those of the packages the settings configure.
+	want := server.DefaultConfig
+	got := server.Config{
+		Addr:              cfg.Server.Addr(),
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+		ShutdownTimeout:   cfg.Server.ShutdownTimeout,
+	}
+	if got != want {
+		t.Errorf("Server defaults = %+v, want %+v", got, want)
+	}
+	if cfg.Quotes.RequestTimeout != quote.DefaultRequestTimeout || cfg.Quotes.BulkTimeout != quote.DefaultBulkTimeout {
+		t.Errorf("Quote timeouts = %v, %v, want %v, %v", cfg.Quotes.RequestTimeout, cfg.Quotes.BulkTimeout, quote.DefaultRequestTimeout, quote.DefaultBulkTimeout)
+	}
+	read := middleware.RateLimit{Requests: cfg.Server.ReadRateLimit, Per: cfg.Server.RateLimitWindow}
+	write := middleware.RateLimit{Requests: cfg.Server.WriteRateLimit, Per: cfg.Server.RateLimitWindow}
+	failures := middleware.RateLimit{Requests: cfg.Server.AuthFailureLimit, Per: cfg.Server.RateLimitWindow}
+	if read != middleware.DefaultReadLimit || write != middleware.DefaultWriteLimit || failures != middleware.DefaultFailureLimit {
+		t.Errorf("Rate limits = %+v, %+v, %+v, want %+v, %+v, %+v", read, write, failures,
+			middleware.DefaultReadLimit, middleware.DefaultWriteLimit, middleware.DefaultFailureLimit)
+	}
+	if cfg.Server.PublicURL != feed.DefaultBaseURL {
+		t.Errorf("PublicURL = %q, want %q", cfg.Server.PublicURL, feed.DefaultBaseURL)
+	}
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	path := writeFile(t, "quotes.toml", `
+# Settings of the quote API
+[server]
+port = 9000
+shutdown_timeout = "1m" # drain longer
+
+[quotes]
+prefix = '/api/quotes'
+request_timeout = "3s"
+`)
+	vars := map[string]string{
+		config.FileEnv:           path,
+		"QUOTES_PORT":            "9100",
+		"QUOTES_REQUEST_TIMEOUT": "",
+		"QUOTES_TRUSTED_PROXIES": "10.0.0.0/8,127.0.0.1",
+	}
+	loaded, err := config.Load([]string{"--server-port", "9200"}, env(vars))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	cfg := loaded.Config
+	if cfg.Server.Port != 9200 {
+		t.Errorf("Port = %d, want the flag over the environment and file", cfg.Server.Port)
+	}
+	if cfg.Server.ShutdownTimeout != time.Minute || cfg.Quotes.Prefix != "/api/quotes" {
+		t.Errorf("ShutdownTimeout = %v, Prefix = %q, want the file values", cfg.Server.ShutdownTimeout, cfg.Quotes.Prefix)
+	}
+	if cfg.Quotes.RequestTimeout != 3*time.Second {
+		t.Errorf("RequestTimeout = %v, want the file value under an empty variable", cfg.Quotes.RequestTimeout)
+	}
+	if len(cfg.Server.TrustedProxies) != 2 || cfg.Server.TrustedProxies[1].String() != "127.0.0.1/32" {
+		t.Errorf("TrustedProxies = %v", cfg.Server.TrustedProxies)
+	}
+}
+
+func TestLoad_YAML(t *testing.T) {
+	path := writeFile(t, "quotes.yaml", `
+server:
+  host: 127.0.0.1
+  port: 9000
+auth:
+  jwt_roles: "editors=contributor, ops=admin"
+log:
+  level: debug # verbose
+`)
+	loaded, err := config.Load([]string{"--config", path}, env(nil))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	cfg := loaded.Config
+	if cfg.Server.Addr() != "127.0.0.1:9000" || cfg.Log.Level.String() != "DEBUG" {
+		t.Errorf("Addr = %q, Level = %v", cfg.Server.Addr(), cfg.Log.Level)
+	}
+	if cfg.Auth.JWTRoles["ops"] != auth.RoleAdmin || cfg.Auth.JWTRoles["editors"] != auth.RoleContributor {
+		t.Errorf("JWTRoles = %v", cfg.Auth.JWTRoles)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		vars map[string]string
+		args []string
+		want []string
+	}{
+		{
+			name: "unknown setting",
+			file: "[server]\nprot = 80\n",
+			want: []string{`quotes.toml:2: unknown setting "server.prot"`},
+		},
+		{
+			name: "malformed file",
+			file: "[server]\nport = eighty\n",
+			want: []string{"quotes.toml:2", `invalid value "eighty"`},
+		},
+		{
+			name: "values of the wrong type",
+			vars: map[string]string{"QUOTES_PORT": "http", "QUOTES_LOG_LEVEL": "loud"},
+			want: []string{"server.port (env QUOTES_PORT): invalid integer", "log.level (env QUOTES_LOG_LEVEL): invalid level"},
+		},
+		{
+			name: "invalid values",
+			args: []string{"--quotes-prefix", "quotes/", "--quotes-backend", "postgres", "--server-port", "70000"},
+			want: []string{
+				"quotes.prefix (flag --quotes-prefix)",
+				`quotes.backend (flag --quotes-backend): unknown backend "postgres", want one of memory`,
+				"server.port (flag --server-port): 70000 is not a port number",
+			},
+		},
+		{
+			name: "file exporter without a file",
+			vars: map[string]string{"QUOTES_TRACES_EXPORTER": "file"},
+			want: []string{"tracing.file (default): must be set for the file exporter"},
+		},
+		{
+			name: "rate limits",
+			vars: map[string]string{"QUOTES_WRITE_RATE_LIMIT": "0", "QUOTES_RATE_LIMIT_WINDOW": "-1m"},
+			want: []string{
+				"server.write_rate_limit (env QUOTES_WRITE_RATE_LIMIT): must be positive",
+				"server.rate_limit_window (env QUOTES_RATE_LIMIT_WINDOW): must be positive",
+			},
+		},
+		{
+			name: "public URL without a scheme",
+			vars: map[string]string{"QUOTES_PUBLIC_URL": "quotes.example.org/"},
+			want: []string{`server.public_url (env QUOTES_PUBLIC_URL): "quotes.example.org/" must be an http or https URL`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vars := tt.vars
+			if tt.file != "" {
+				vars = map[string]string{config.FileEnv: writeFile(t, "quotes.toml", tt.file)}
+			}
+			_, err := config.Load(tt.args, env(vars))
+			if err == nil {
+				t.Fatal("Load() succeeded, want an error")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Load() error = %q, want it to mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoaded_PrintRedactsSecrets(t *testing.T) {
+	vars := map[string]string{"QUOTES_ADMIN_API_KEY": "qk_very-secret-admin-key"}
+	loaded, err := config.Load([]string{"--print-config", "--server-port=9000"}, env(vars))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !loaded.PrintConfig {
+		t.Error("PrintConfig is not set by --print-config")
+	}
+
+	var buf bytes.Buffer
+	if err := loaded.Print(&buf); err != nil {
+		t.Fatalf("Print() error = %v", err)
+	}
+	out := buf.String()
+	if strings.Contains(out, "very-secret") {
+		t.Errorf("Print() shows the secret:\n%s", out)
+	}
+	for _, want := range []string{
+		`# admin_api_key = "[redacted]" # env QUOTES_ADMIN_API_KEY`,
+		"port = 9000 # flag --server-port",
+		`prefix = "/quotes" # default`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Print() output lacks %q:\n%s", want, out)
+		}
+	}
+
+}
+
+func TestLoaded_PrintLoadsBack(t *testing.T) {
+	vars := map[string]string{
+		"QUOTES_ADMIN_API_KEY":   "qk_very-secret-admin-key",
+		"QUOTES_TRUSTED_PROXIES": "10.0.0.0/8",
+		"QUOTES_JWT_ROLES":       "editors=contributor",
+	}
+	loaded, err := config.Load([]string{"--server-port=9000", "--log-level=debug"}, env(vars))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	var buf bytes.Buffer
+	if err := loaded.Print(&buf); err != nil {
+		t.Fatalf("Print() error = %v", err)
+	}
+
+	// The secret is left to the environment, the rest comes from the file.
+	path := writeFile(t, "printed.toml", buf.String())
+	reloaded, err := config.Load([]string{"--config", path}, env(map[string]string{"QUOTES_ADMIN_API_KEY": vars["QUOTES_ADMIN_API_KEY"]}))
+	if err != nil {
+		t.Fatalf("Load() of the printed configuration error = %v\n%s", err, buf.String())
+	}
+	want := loaded.Config
+	if got := reloaded.Config; !reflect.DeepEqual(got, want) {
+		t.Errorf("Reloaded configuration = %+v, want %+v", got, want)
+	}
+
+	// Without the secret the printed file still loads.
+	if _, err := config.Load([]string{"--config", path}, env(nil)); err != nil {
+		t.Errorf("Load() of the printed configuration without the secret error = %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package apperror_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Korjick/go-http-quote/domain/apperror"
+)
+
+func TestError_WrappedKeepsKindAndCode(t *testing.T) {
+	base := apperror.New(apperror.KindNotFound, "thing_not_found", "thing not found")
+	wrapped := fmt.Errorf("loading thing 42: %w", base)
+
+	if !errors.Is(wrapped, base) {
+		t.Error("errors.Is() on wrapped error = false, want true")
+	}
+
+	appErr, ok := apperror.As(wrapped)
+	if !ok {
+		t.Fatal("As() on wrapped error = false, want true")
+	}
+	if appErr.Code() != "thing_not_found" {
+		t.Errorf("Code() = %v, want thing_not_found", appErr.Code())
+	}
+	if apperror.KindOf(wrapped) != apperror.KindNotFound {
+		t.Errorf("KindOf() = %v, want %v", apperror.KindOf(wrapped), apperror.KindNotFound)
+	}
+}
+
+func TestError_IdentityComparison(t *testing.T) {
+	first := apperror.New(apperror.KindInvalid, "same_code", "same message")
+	second := apperror.New(apperror.KindInvalid, "same_code", "same message")
+
+	if errors.Is(first, second) {
+		t.Error("errors.Is() on distinct errors = true, want false")
+	}
+}
+
+func TestKindOf_PlainError(t *testing.T) {
+	if kind := apperror.KindOf(errors.New("boom")); kind != apperror.KindInternal {
+		t.Errorf("KindOf() = %v, want %v", kind, apperror.KindInternal)
+	}
+
+	if _, ok := apperror.As(nil); ok {
+		t.Error("As(nil) = true, want false")
+	}
+}
@@ -0,0 +1,55 @@
+package auth_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Korjick/go-http-quote/domain/auth"
+)
+
+func TestRole_Can(t *testing.T) {
+	tests := []struct {
+		role       auth.Role
+		permission auth.Permission
+		want       bool
+	}{
+		{auth.RoleReader, auth.PermissionCreateQuote, false},
+		{auth.RoleContributor, auth.PermissionCreateQuote, true},
+		{auth.RoleContributor, auth.PermissionDeleteQuote, false},
+		{auth.RoleModerator, auth.PermissionDeleteQuote, true},
+		{auth.RoleModerator, auth.PermissionManageAPIKeys, false},
+		{auth.RoleAdmin, auth.PermissionManageAPIKeys, true},
+		{auth.RoleAdmin, auth.PermissionCreateQuote, true},
+		{"", auth.PermissionCreateQuote, false},
+	}
+	for _, tt := range tests {
+		if got := tt.role.Can(tt.permission); got != tt.want {
+			t.Errorf("Role(%q).Can(%s) = %v, want %v", tt.role, tt.permission, got, tt.want)
+		}
+	}
+}
+
+func TestParseRole(t *testing.T) {
+	role, err := auth.ParseRole("moderator")
+	if err != nil || role != auth.RoleModerator {
+		t.Errorf("ParseRole(moderator) = %q, %v, want moderator", role, err)
+	}
+	if _, err := auth.ParseRole("root"); !errors.Is(err, auth.ErrUnknownRole) {
+		t.Errorf("ParseRole(root) error = %v, want %v", err, auth.ErrUnknownRole)
+	}
+}
+
+func TestPrincipal_Authorize(t *testing.T) {
+	if err := auth.Anonymous.Authorize(auth.PermissionCreateQuote); !errors.Is(err, auth.ErrUnauthenticated) {
+		t.Errorf("Anonymous.Authorize() error = %v, want %v", err, auth.ErrUnauthenticated)
+	}
+
+	reader := auth.Principal{Subject: "api-key:1", Role: auth.RoleReader}
+	if err := reader.Authorize(auth.PermissionCreateQuote); !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("reader.Authorize() error = %v, want %v", err, auth.ErrForbidden)
+	}
+
+	if err := auth.System.Authorize(auth.PermissionManageAPIKeys); err != nil {
+		t.Errorf("System.Authorize() error = %v, want nil", err)
+	}
+}
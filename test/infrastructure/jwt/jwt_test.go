@@ -0,0 +1,137 @@
+package jwt_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/Korjick/go-http-quote/infrastructure/jwt"
+)
+
+// signer issues test tokens with one key and describes that key as a JWK.
+type signer struct {
+	kid  string
+	alg  string
+	key  crypto.Signer
+	sign func(digest, input []byte) ([]byte, error)
+}
+
+func newSigner(t *testing.T, kid, alg string) *signer {
+	t.Helper()
+	s := &signer{kid: kid, alg: alg}
+	switch alg {
+	case jwt.AlgRS256:
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.key = key
+		s.sign = func(digest, _ []byte) ([]byte, error) {
+			return rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest)
+		}
+	case jwt.AlgES256:
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.key = key
+		s.sign = func(digest, _ []byte) ([]byte, error) {
+			r, sig, err := ecdsa.Sign(rand.Reader, key, digest)
+			if err != nil {
+				return nil, err
+			}
+			return append(r.FillBytes(make([]byte, 32)), sig.FillBytes(make([]byte, 32))...), nil
+		}
+	case jwt.AlgEdDSA:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.key = key
+		s.sign = func(_, input []byte) ([]byte, error) {
+			return ed25519.Sign(key, input), nil
+		}
+	}
+	return s
+}
+
+func (s *signer) jwk() jwt.JWK {
+	encode := base64.RawURLEncoding.EncodeToString
+	switch public := s.key.Public().(type) {
+	case *rsa.PublicKey:
+		return jwt.JWK{Kty: "RSA", Kid: s.kid, Alg: s.alg, Use: "sig", N: encode(public.N.Bytes()), E: encode(big.NewInt(int64(public.E)).Bytes())}
+	case *ecdsa.PublicKey:
+		return jwt.JWK{Kty: "EC", Kid: s.kid, Crv: "P-256", X: encode(public.X.FillBytes(make([]byte, 32))), Y: encode(public.Y.FillBytes(make([]byte, 32)))}
+	default:
+		return jwt.JWK{Kty: "OKP", Kid: s.kid, Crv: "Ed25519", X: encode(public.(ed25519.PublicKey))}
+	}
+}
+
+func (s *signer) token(t *testing.T, claims map[string]any) string {
+	t.Helper()
+	return s.tokenWithHeader(t, map[string]any{"alg": s.alg, "kid": s.kid, "typ": "JWT"}, claims)
+}
+
+func (s *signer) tokenWithHeader(t *testing.T, header, claims map[string]any) string {
+	t.Helper()
+	input := segment(t, header) + "." + segment(t, claims)
+	digest := sha256.Sum256([]byte(input))
+	signature, err := s.sign(digest[:], []byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func segment(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func keySetJSON(t *testing.T, signers ...*signer) []byte {
+	t.Helper()
+	set := struct {
+		Keys []jwt.JWK `json:"keys"`
+	}{}
+	for _, s := range signers {
+		set.Keys = append(set.Keys, s.jwk())
+	}
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestParseKeySet_SkipsUnusableKeys(t *testing.T) {
+	encryption := newSigner(t, "encryption", jwt.AlgRS256).jwk()
+	encryption.Use = "enc"
+	set := struct {
+		Keys []jwt.JWK `json:"keys"`
+	}{Keys: []jwt.JWK{{Kty: "oct", Kid: "symmetric"}, encryption, newSigner(t, "ed", jwt.AlgEdDSA).jwk()}}
+	data, _ := json.Marshal(set)
+
+	keys, err := jwt.ParseKeySet(data)
+	if err != nil {
+		t.Fatalf("ParseKeySet() error = %v", err)
+	}
+	if len(keys) != 1 || keys[0].ID != "ed" || keys[0].Algorithm != jwt.AlgEdDSA {
+		t.Errorf("ParseKeySet() = %+v, want the Ed25519 key only", keys)
+	}
+
+	if _, err := jwt.ParseKeySet([]byte(`{"keys": [{"kty": "oct", "k": "c2VjcmV0"}]}`)); err == nil {
+		t.Error("ParseKeySet() without usable keys error = nil")
+	}
+}
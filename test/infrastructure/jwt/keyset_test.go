@@ -0,0 +1,178 @@
+package jwt_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Korjick/go-http-quote/domain/auth"
+	"github.com/Korjick/go-http-quote/infrastructure/jwt"
+)
+
+// jwksServer serves a key set that tests can replace, counting requests.
+type jwksServer struct {
+	*httptest.Server
+	mutex        sync.Mutex
+	body         []byte
+	status       int
+	cacheControl string
+	requests     int
+}
+
+func newJWKSServer(t *testing.T, body []byte) *jwksServer {
+	s := &jwksServer{body: body, status: http.StatusOK}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		s.requests++
+		if s.cacheControl != "" {
+			w.Header().Set("Cache-Control", s.cacheControl)
+		}
+		w.WriteHeader(s.status)
+		_, _ = w.Write(s.body)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *jwksServer) set(status int, body []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.status, s.body = status, body
+}
+
+func (s *jwksServer) count() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.requests
+}
+
+func TestKeySet_CachesKeys(t *testing.T) {
+	s := newSigner(t, "ed", jwt.AlgEdDSA)
+	server := newJWKSServer(t, keySetJSON(t, s))
+	keys := jwt.NewKeySet(jwt.URLSource(server.Client(), server.URL), time.Hour, time.Hour)
+
+	for range 3 {
+		if _, err := keys.Key(context.Background(), "ed", jwt.AlgEdDSA); err != nil {
+			t.Fatalf("Key() error = %v", err)
+		}
+	}
+	if _, err := keys.Key(context.Background(), "unknown", jwt.AlgEdDSA); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("Key(unknown) error = %v, want %v", err, auth.ErrInvalidToken)
+	}
+	if server.count() != 1 {
+		t.Errorf("Key set fetched %d times, want 1", server.count())
+	}
+}
+
+func TestKeySet_Rotation(t *testing.T) {
+	old := newSigner(t, "2024", jwt.AlgRS256)
+	rotated := newSigner(t, "2025", jwt.AlgRS256)
+	server := newJWKSServer(t, keySetJSON(t, old))
+	keys := jwt.NewKeySet(jwt.URLSource(server.Client(), server.URL), time.Hour, 0)
+	verifier := jwt.NewVerifier(keys, "", "")
+
+	if _, err := verifier.Verify(context.Background(), old.token(t, validClaims())); err != nil {
+		t.Fatalf("Verify(old) error = %v", err)
+	}
+	server.set(http.StatusOK, keySetJSON(t, old, rotated))
+	if _, err := verifier.Verify(context.Background(), rotated.token(t, validClaims())); err != nil {
+		t.Errorf("Verify(rotated) error = %v", err)
+	}
+	if server.count() != 2 {
+		t.Errorf("Key set fetched %d times, want 2", server.count())
+	}
+}
+
+func TestKeySet_KeepsKeysWhenReloadFails(t *testing.T) {
+	s := newSigner(t, "ec", jwt.AlgES256)
+	server := newJWKSServer(t, keySetJSON(t, s))
+	keys := jwt.NewKeySet(jwt.URLSource(server.Client(), server.URL), time.Hour, 0)
+
+	if err := keys.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	server.set(http.StatusServiceUnavailable, nil)
+	if err := keys.Refresh(context.Background()); err == nil {
+		t.Error("Refresh() with failing source error = nil")
+	}
+	if _, err := keys.Key(context.Background(), "ec", jwt.AlgES256); err != nil {
+		t.Errorf("Key() after failed reload error = %v", err)
+	}
+}
+
+func TestKeySet_Unavailable(t *testing.T) {
+	server := newJWKSServer(t, nil)
+	server.set(http.StatusInternalServerError, nil)
+	keys := jwt.NewKeySet(jwt.URLSource(server.Client(), server.URL), time.Hour, time.Hour)
+
+	if _, err := keys.Key(context.Background(), "any", jwt.AlgEdDSA); !errors.Is(err, jwt.ErrKeySetUnavailable) {
+		t.Errorf("Key() error = %v, want %v", err, jwt.ErrKeySetUnavailable)
+	}
+}
+
+func TestKeySet_ReloadsOutsideTheLock(t *testing.T) {
+	s := newSigner(t, "ed", jwt.AlgEdDSA)
+	body := keySetJSON(t, s)
+	var fetches int
+	started, release := make(chan struct{}), make(chan struct{})
+	source := func(ctx context.Context) ([]byte, time.Duration, error) {
+		fetches++
+		if fetches > 1 {
+			close(started)
+			<-release
+		}
+		return body, 0, nil
+	}
+	keys := jwt.NewKeySet(source, time.Millisecond, time.Hour)
+	if err := keys.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	// The expired keys reload in one request while the others use them.
+	reloaded := make(chan error, 1)
+	go func() {
+		_, err := keys.Key(context.Background(), "ed", jwt.AlgEdDSA)
+		reloaded <- err
+	}()
+	<-started
+	for range 3 {
+		if _, err := keys.Key(context.Background(), "ed", jwt.AlgEdDSA); err != nil {
+			t.Errorf("Key() during the reload error = %v", err)
+		}
+	}
+	close(release)
+	if err := <-reloaded; err != nil {
+		t.Errorf("Key() reloading error = %v", err)
+	}
+	if fetches != 2 {
+		t.Errorf("Key set fetched %d times, want 2", fetches)
+	}
+}
+
+func TestKeySet_ReloadCanceledWithRequest(t *testing.T) {
+	blocked := make(chan struct{})
+	t.Cleanup(func() { close(blocked) })
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-blocked:
+		}
+	}))
+	t.Cleanup(server.Close)
+	keys := jwt.NewKeySet(jwt.URLSource(server.Client(), server.URL), time.Hour, time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := keys.Key(ctx, "any", jwt.AlgEdDSA); !errors.Is(err, jwt.ErrKeySetUnavailable) {
+		t.Errorf("Key() error = %v, want %v", err, jwt.ErrKeySetUnavailable)
+	}
+	if ctx.Err() == nil {
+		t.Error("Key() returned before the request was canceled")
+	}
+}
@@ -0,0 +1,101 @@
+package jwt_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Korjick/go-http-quote/domain/auth"
+	"github.com/Korjick/go-http-quote/infrastructure/jwt"
+)
+
+func setupVerifier(t *testing.T, signers ...*signer) *jwt.Verifier {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, keySetJSON(t, signers...), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	keys := jwt.NewKeySet(jwt.FileSource(path), time.Hour, time.Hour)
+	return jwt.NewVerifier(keys, "https://sso.example.com", "quotes")
+}
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"iss":   "https://sso.example.com",
+		"aud":   []string{"quotes", "other"},
+		"sub":   "user-42",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": []string{"editors"},
+	}
+}
+
+func TestVerifier_Algorithms(t *testing.T) {
+	signers := []*signer{
+		newSigner(t, "rsa", jwt.AlgRS256),
+		newSigner(t, "ec", jwt.AlgES256),
+		newSigner(t, "ed", jwt.AlgEdDSA),
+	}
+	verifier := setupVerifier(t, signers...)
+
+	for _, s := range signers {
+		claims, err := verifier.Verify(context.Background(), s.token(t, validClaims()))
+		if err != nil {
+			t.Errorf("%s: Verify() error = %v", s.alg, err)
+			continue
+		}
+		if claims["sub"] != "user-42" {
+			t.Errorf("%s: sub = %v, want user-42", s.alg, claims["sub"])
+		}
+	}
+}
+
+func TestVerifier_RejectsInvalidTokens(t *testing.T) {
+	s := newSigner(t, "ec", jwt.AlgES256)
+	other := newSigner(t, "ec", jwt.AlgES256)
+	verifier := setupVerifier(t, s)
+
+	with := func(name string, value any) map[string]any {
+		claims := validClaims()
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+		return claims
+	}
+
+	tests := map[string]string{
+		"garbage":             "not-a-token",
+		"foreign signature":   other.token(t, validClaims()),
+		"expired":             s.token(t, with("exp", time.Now().Add(-2*time.Minute).Unix())),
+		"no expiration":       s.token(t, with("exp", nil)),
+		"not yet valid":       s.token(t, with("nbf", time.Now().Add(time.Hour).Unix())),
+		"other issuer":        s.token(t, with("iss", "https://evil.example.com")),
+		"other audience":      s.token(t, with("aud", "billing")),
+		"no subject":          s.token(t, with("sub", nil)),
+		"unknown key":         s.tokenWithHeader(t, map[string]any{"alg": jwt.AlgES256, "kid": "rotated"}, validClaims()),
+		"algorithm none":      s.tokenWithHeader(t, map[string]any{"alg": "none", "kid": "ec"}, validClaims()),
+		"algorithm mismatch":  s.tokenWithHeader(t, map[string]any{"alg": jwt.AlgRS256, "kid": "ec"}, validClaims()),
+		"critical extensions": s.tokenWithHeader(t, map[string]any{"alg": jwt.AlgES256, "kid": "ec", "crit": []string{"exp"}}, validClaims()),
+	}
+	for name, token := range tests {
+		if _, err := verifier.Verify(context.Background(), token); !errors.Is(err, auth.ErrInvalidToken) {
+			t.Errorf("%s: Verify() error = %v, want %v", name, err, auth.ErrInvalidToken)
+		}
+	}
+}
+
+func TestVerifier_Leeway(t *testing.T) {
+	s := newSigner(t, "ed", jwt.AlgEdDSA)
+	verifier := setupVerifier(t, s)
+
+	claims := validClaims()
+	claims["exp"] = time.Now().Add(-30 * time.Second).Unix()
+	claims["nbf"] = time.Now().Add(30 * time.Second).Unix()
+	if _, err := verifier.Verify(context.Background(), s.token(t, claims)); err != nil {
+		t.Errorf("Verify() within leeway error = %v", err)
+	}
+}
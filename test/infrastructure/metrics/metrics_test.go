@@ -0,0 +1,148 @@
+package metrics_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Korjick/go-http-quote/domain/quote/entity"
+	"github.com/Korjick/go-http-quote/infrastructure/metrics"
+	"github.com/Korjick/go-http-quote/infrastructure/repository/in_memory"
+)
+
+func exposition(t *testing.T, registry *metrics.Registry) string {
+	t.Helper()
+	var b strings.Builder
+	if _, err := registry.WriteTo(&b); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	return b.String()
+}
+
+func TestRegistry_TextFormat(t *testing.T) {
+	registry := metrics.NewRegistry()
+	requests := registry.Counter("requests_total", "Requests served.", "route", "status")
+	latency := registry.Histogram("latency_seconds", "Request latency.", []float64{0.1, 1}, "route")
+	registry.GaugeFunc("items", "Items stored.", func() float64 { return 3 })
+
+	requests.Inc("/b", "200")
+	requests.Inc("/a", "200")
+	requests.Add(2, "/a", "200")
+	latency.Observe(0.05, "/a")
+	latency.Observe(0.1, "/a")
+	latency.Observe(5, "/a")
+
+	want := `# HELP requests_total Requests served.
+# TYPE requests_total counter
+requests_total{route="/a",status="200"} 3
+requests_total{route="/b",status="200"} 1
+# HELP latency_seconds Request latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{route="/a",le="0.1"} 2
+latency_seconds_bucket{route="/a",le="1"} 2
+latency_seconds_bucket{route="/a",le="+Inf"} 3
+latency_seconds_sum{route="/a"} 5.15
+latency_seconds_count{route="/a"} 3
+# HELP items Items stored.
+# TYPE items gauge
+items 3
+`
+	if got := exposition(t, registry); got != want {
+		t.Errorf("Exposition:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRegistry_EscapesLabelValues(t *testing.T) {
+	registry := metrics.NewRegistry()
+	registry.Counter("c", "Line\nbreak.", "v").Inc("a\"b\\c\nd")
+
+	got := exposition(t, registry)
+	for _, want := range []string{`# HELP c Line\nbreak.`, `c{v="a\"b\\c\nd"} 1`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Exposition %q does not contain %q", got, want)
+		}
+	}
+}
+
+func TestRegistry_Misuse(t *testing.T) {
+	tests := []struct {
+		name string
+		use  func(*metrics.Registry)
+	}{
+		{"duplicate name", func(r *metrics.Registry) {
+			r.Counter("c", "")
+			r.Histogram("c", "", metrics.DefaultBuckets)
+		}},
+		{"wrong label count", func(r *metrics.Registry) {
+			r.Counter("c", "", "a", "b").Inc("x")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected a panic")
+				}
+			}()
+			tt.use(metrics.NewRegistry())
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	registry := metrics.NewRegistry()
+	registry.Counter("c", "Help.").Inc()
+
+	w := httptest.NewRecorder()
+	metrics.Handler(registry).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if got := w.Header().Get("Content-Type"); got != metrics.ContentType {
+		t.Errorf("Content-Type = %q, want %q", got, metrics.ContentType)
+	}
+	if !strings.Contains(w.Body.String(), "\nc 1\n") {
+		t.Errorf("Body = %q, want the counter", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	metrics.Handler(registry).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST status = %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestInstrumentQuoteRepository(t *testing.T) {
+	registry := metrics.NewRegistry()
+	lockWait := registry.Histogram("lock_wait_seconds", "Lock wait.", metrics.LockWaitBuckets, "mode")
+	repo := metrics.InstrumentQuoteRepository(in_memory.NewInMemoryQuoteRepository(
+		in_memory.WithLockWaitObserver(func(mode string, wait time.Duration) {
+			lockWait.Observe(wait.Seconds(), mode)
+		}),
+	), registry)
+	ctx := context.Background()
+
+	for range 2 {
+		if _, err := repo.Create(ctx, entity.QuoteDraft{Author: "Author", Text: "Text"}); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	if _, err := repo.GetByID(ctx, 99); err == nil {
+		t.Fatal("GetByID() of a missing quote succeeded")
+	}
+
+	got := exposition(t, registry)
+	for _, want := range []string{
+		"\nquotes_stored 2\n",
+		`quotes_repository_operation_duration_seconds_count{operation="create",outcome="ok"} 2`,
+		`quotes_repository_operation_duration_seconds_count{operation="get_by_id",outcome="error"} 1`,
+		`lock_wait_seconds_count{mode="write"} 2`,
+		// The snapshot behind quotes_stored is taken after the lock
+		// waits are written.
+		`lock_wait_seconds_count{mode="read"} 1`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Exposition does not contain %q:\n%s", want, got)
+		}
+	}
+}
@@ -1,10 +1,12 @@
 package in_memory_test
 
 import (
+	"context"
 	"errors"
 	"testing"
 
 	"github.com/Korjick/go-http-quote/domain/quote/entity"
+	"github.com/Korjick/go-http-quote/domain/quote/repository"
 	"github.com/Korjick/go-http-quote/infrastructure/repository/in_memory"
 )
 
@@ -14,7 +16,7 @@ func TestInMemoryQuoteRepository_Create(t *testing.T) {
 	author := "Test Author"
 	text := "Test Quote"
 
-	quote, err := repo.Create(author, text)
+	quote, err := repo.Create(context.Background(), entity.QuoteDraft{Author: author, Text: text})
 	if err != nil {
 		t.Errorf("Create() error = %v, want nil", err)
 	}
@@ -35,7 +37,7 @@ func TestInMemoryQuoteRepository_Create(t *testing.T) {
 		t.Errorf("Created quote Text = %v, want %v", quote.Text, text)
 	}
 
-	allQuotes, err := repo.GetAll()
+	allQuotes, err := repo.GetAll(context.Background())
 	if err != nil {
 		t.Errorf("GetAll() error = %v, want nil", err)
 	}
@@ -48,12 +50,12 @@ func TestInMemoryQuoteRepository_Create(t *testing.T) {
 func TestInMemoryQuoteRepository_CreateInvalidInput(t *testing.T) {
 	repo := in_memory.NewInMemoryQuoteRepository()
 
-	_, err := repo.Create("", "Some text")
+	_, err := repo.Create(context.Background(), entity.QuoteDraft{Author: "", Text: "Some text"})
 	if err == nil {
 		t.Error("Create() with empty author should return error")
 	}
 
-	_, err = repo.Create("Author", "")
+	_, err = repo.Create(context.Background(), entity.QuoteDraft{Author: "Author", Text: ""})
 	if err == nil {
 		t.Error("Create() with empty text should return error")
 	}
@@ -62,7 +64,7 @@ func TestInMemoryQuoteRepository_CreateInvalidInput(t *testing.T) {
 func TestInMemoryQuoteRepository_GetAll(t *testing.T) {
 	repo := in_memory.NewInMemoryQuoteRepository()
 
-	quotes, err := repo.GetAll()
+	quotes, err := repo.GetAll(context.Background())
 	if err != nil {
 		t.Errorf("GetAll() error = %v, want nil", err)
 	}
@@ -71,10 +73,10 @@ func TestInMemoryQuoteRepository_GetAll(t *testing.T) {
 		t.Errorf("GetAll() returned %d quotes, want 0", len(quotes))
 	}
 
-	_, _ = repo.Create("Author 1", "Quote 1")
-	_, _ = repo.Create("Author 2", "Quote 2")
+	_, _ = repo.Create(context.Background(), entity.QuoteDraft{Author: "Author 1", Text: "Quote 1"})
+	_, _ = repo.Create(context.Background(), entity.QuoteDraft{Author: "Author 2", Text: "Quote 2"})
 
-	quotes, err = repo.GetAll()
+	quotes, err = repo.GetAll(context.Background())
 	if err != nil {
 		t.Errorf("GetAll() error = %v, want nil", err)
 	}
@@ -95,11 +97,11 @@ func TestInMemoryQuoteRepository_GetAll(t *testing.T) {
 func TestInMemoryQuoteRepository_GetByAuthor(t *testing.T) {
 	repo := in_memory.NewInMemoryQuoteRepository()
 
-	_, _ = repo.Create("Albert Einstein", "Quote 1")
-	_, _ = repo.Create("Steve Jobs", "Quote 2")
-	_, _ = repo.Create("Albert Einstein", "Quote 3")
+	_, _ = repo.Create(context.Background(), entity.QuoteDraft{Author: "Albert Einstein", Text: "Quote 1"})
+	_, _ = repo.Create(context.Background(), entity.QuoteDraft{Author: "Steve Jobs", Text: "Quote 2"})
+	_, _ = repo.Create(context.Background(), entity.QuoteDraft{Author: "Albert Einstein", Text: "Quote 3"})
 
-	quotes, err := repo.GetByAuthor("Albert Einstein")
+	quotes, err := repo.GetByAuthor(context.Background(), "Albert Einstein")
 	if err != nil {
 		t.Errorf("GetByAuthor() error = %v, want nil", err)
 	}
@@ -108,7 +110,7 @@ func TestInMemoryQuoteRepository_GetByAuthor(t *testing.T) {
 		t.Errorf("GetByAuthor() returned %d quotes, want 2", len(quotes))
 	}
 
-	quotes, err = repo.GetByAuthor("albert einstein")
+	quotes, err = repo.GetByAuthor(context.Background(), "albert einstein")
 	if err != nil {
 		t.Errorf("GetByAuthor() error = %v, want nil", err)
 	}
@@ -117,7 +119,7 @@ func TestInMemoryQuoteRepository_GetByAuthor(t *testing.T) {
 		t.Errorf("GetByAuthor() case-insensitive returned %d quotes, want 2", len(quotes))
 	}
 
-	quotes, err = repo.GetByAuthor("Non-existent Author")
+	quotes, err = repo.GetByAuthor(context.Background(), "Non-existent Author")
 	if err != nil {
 		t.Errorf("GetByAuthor() error = %v, want nil", err)
 	}
@@ -127,19 +129,40 @@ func TestInMemoryQuoteRepository_GetByAuthor(t *testing.T) {
 	}
 }
 
+func TestInMemoryQuoteRepository_GetByID(t *testing.T) {
+	repo := in_memory.NewInMemoryQuoteRepository()
+	for i := 0; i < 3; i++ {
+		if _, err := repo.Create(context.Background(), entity.QuoteDraft{Author: "Author", Text: "Quote"}); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	if err := repo.Delete(context.Background(), 2); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	quote, err := repo.GetByID(context.Background(), 3)
+	if err != nil || quote.ID != 3 {
+		t.Errorf("GetByID(3) = %v, %v, want quote 3", quote, err)
+	}
+
+	if _, err := repo.GetByID(context.Background(), 2); !errors.Is(err, entity.ErrQuoteNotFound) {
+		t.Errorf("GetByID(2) error = %v, want %v", err, entity.ErrQuoteNotFound)
+	}
+}
+
 func TestInMemoryQuoteRepository_GetRandom(t *testing.T) {
 	repo := in_memory.NewInMemoryQuoteRepository()
 
-	_, err := repo.GetRandom()
+	_, err := repo.GetRandom(context.Background())
 	if !errors.Is(err, entity.ErrQuoteNotFound) {
 		t.Errorf("GetRandom() on empty repo error = %v, want %v", err, entity.ErrQuoteNotFound)
 	}
 
-	_, _ = repo.Create("Author 1", "Quote 1")
-	_, _ = repo.Create("Author 2", "Quote 2")
-	_, _ = repo.Create("Author 3", "Quote 3")
+	_, _ = repo.Create(context.Background(), entity.QuoteDraft{Author: "Author 1", Text: "Quote 1"})
+	_, _ = repo.Create(context.Background(), entity.QuoteDraft{Author: "Author 2", Text: "Quote 2"})
+	_, _ = repo.Create(context.Background(), entity.QuoteDraft{Author: "Author 3", Text: "Quote 3"})
 
-	quote, err := repo.GetRandom()
+	quote, err := repo.GetRandom(context.Background())
 	if err != nil {
 		t.Errorf("GetRandom() error = %v, want nil", err)
 	}
@@ -166,21 +189,21 @@ func TestInMemoryQuoteRepository_GetRandom(t *testing.T) {
 func TestInMemoryQuoteRepository_Delete(t *testing.T) {
 	repo := in_memory.NewInMemoryQuoteRepository()
 
-	err := repo.Delete(999)
+	err := repo.Delete(context.Background(), 999)
 	if !errors.Is(err, entity.ErrQuoteNotFound) {
 		t.Errorf("Delete() on empty repo error = %v, want %v", err, entity.ErrQuoteNotFound)
 	}
 
-	quote1, _ := repo.Create("Author 1", "Quote 1")
-	quote2, _ := repo.Create("Author 2", "Quote 2")
-	quote3, _ := repo.Create("Author 3", "Quote 3")
+	quote1, _ := repo.Create(context.Background(), entity.QuoteDraft{Author: "Author 1", Text: "Quote 1"})
+	quote2, _ := repo.Create(context.Background(), entity.QuoteDraft{Author: "Author 2", Text: "Quote 2"})
+	quote3, _ := repo.Create(context.Background(), entity.QuoteDraft{Author: "Author 3", Text: "Quote 3"})
 
-	err = repo.Delete(quote2.ID)
+	err = repo.Delete(context.Background(), quote2.ID)
 	if err != nil {
 		t.Errorf("Delete() error = %v, want nil", err)
 	}
 
-	allQuotes, _ := repo.GetAll()
+	allQuotes, _ := repo.GetAll(context.Background())
 	if len(allQuotes) != 2 {
 		t.Errorf("After deletion, found %d quotes, want 2", len(allQuotes))
 	}
@@ -201,7 +224,7 @@ func TestInMemoryQuoteRepository_Delete(t *testing.T) {
 		}
 	}
 
-	err = repo.Delete(quote2.ID)
+	err = repo.Delete(context.Background(), quote2.ID)
 	if !errors.Is(err, entity.ErrQuoteNotFound) {
 		t.Errorf("Delete() already deleted quote error = %v, want %v", err, entity.ErrQuoteNotFound)
 	}
@@ -214,14 +237,14 @@ func TestInMemoryQuoteRepository_ThreadSafety(t *testing.T) {
 
 	for i := 0; i < 5; i++ {
 		go func(id int) {
-			_, _ = repo.Create("Author", "Quote from goroutine")
+			_, _ = repo.Create(context.Background(), entity.QuoteDraft{Author: "Author", Text: "Quote from goroutine"})
 			done <- true
 		}(i)
 	}
 
 	for i := 0; i < 5; i++ {
 		go func() {
-			_, _ = repo.GetAll()
+			_, _ = repo.GetAll(context.Background())
 			done <- true
 		}()
 	}
@@ -230,7 +253,7 @@ func TestInMemoryQuoteRepository_ThreadSafety(t *testing.T) {
 		<-done
 	}
 
-	quotes, err := repo.GetAll()
+	quotes, err := repo.GetAll(context.Background())
 	if err != nil {
 		t.Errorf("GetAll() after concurrent operations error = %v", err)
 	}
@@ -239,3 +262,247 @@ func TestInMemoryQuoteRepository_ThreadSafety(t *testing.T) {
 		t.Errorf("After concurrent operations, found %d quotes, want 5", len(quotes))
 	}
 }
+
+func TestInMemoryQuoteRepository_CreateMany(t *testing.T) {
+	repo := in_memory.NewInMemoryQuoteRepository()
+	_, _ = repo.Create(context.Background(), entity.QuoteDraft{Author: "Author 1", Text: "Quote 1"})
+
+	quotes, err := repo.CreateMany(context.Background(), []entity.QuoteDraft{
+		{Author: "Author 2", Text: "Quote 2"},
+		{Author: "Author 3", Text: "Quote 3"},
+	})
+	if err != nil {
+		t.Fatalf("CreateMany() error = %v, want nil", err)
+	}
+
+	if len(quotes) != 2 {
+		t.Fatalf("CreateMany() returned %d quotes, want 2", len(quotes))
+	}
+
+	if quotes[0].ID != 2 || quotes[1].ID != 3 {
+		t.Errorf("CreateMany() IDs = %v, %v, want 2, 3", quotes[0].ID, quotes[1].ID)
+	}
+}
+
+func TestInMemoryQuoteRepository_CreateManyInvalidInput(t *testing.T) {
+	repo := in_memory.NewInMemoryQuoteRepository()
+
+	_, err := repo.CreateMany(context.Background(), []entity.QuoteDraft{
+		{Author: "Author 1", Text: "Quote 1"},
+		{Author: "", Text: "Quote 2"},
+	})
+	if !errors.Is(err, entity.ErrEmptyAuthor) {
+		t.Errorf("CreateMany() error = %v, want %v", err, entity.ErrEmptyAuthor)
+	}
+
+	quotes, _ := repo.GetAll(context.Background())
+	if len(quotes) != 0 {
+		t.Errorf("After failed CreateMany(), found %d quotes, want 0", len(quotes))
+	}
+}
+
+func TestInMemoryQuoteRepository_IDsAreNotReused(t *testing.T) {
+	repo := in_memory.NewInMemoryQuoteRepository()
+
+	_, _ = repo.Create(context.Background(), entity.QuoteDraft{Author: "Author 1", Text: "Quote 1"})
+	quote2, _ := repo.Create(context.Background(), entity.QuoteDraft{Author: "Author 2", Text: "Quote 2"})
+	_ = repo.Delete(context.Background(), quote2.ID)
+
+	quote3, err := repo.Create(context.Background(), entity.QuoteDraft{Author: "Author 3", Text: "Quote 3"})
+	if err != nil {
+		t.Fatalf("Create() error = %v, want nil", err)
+	}
+
+	if quote3.ID != 3 {
+		t.Errorf("Created quote ID after deletion = %v, want 3", quote3.ID)
+	}
+}
+
+func TestInMemoryQuoteRepository_GetPage(t *testing.T) {
+	repo := in_memory.NewInMemoryQuoteRepository()
+
+	for i := 0; i < 5; i++ {
+		_, _ = repo.Create(context.Background(), entity.QuoteDraft{Author: "Author", Text: "Quote"})
+	}
+	_ = repo.Delete(context.Background(), 3)
+
+	page, err := repo.GetPage(context.Background(), 0, 2)
+	if err != nil {
+		t.Fatalf("GetPage() error = %v, want nil", err)
+	}
+	if len(page) != 2 || page[0].ID != 1 || page[1].ID != 2 {
+		t.Errorf("GetPage(0, 2) returned %d quotes, want IDs 1, 2", len(page))
+	}
+
+	page, _ = repo.GetPage(context.Background(), 2, 2)
+	if len(page) != 2 || page[0].ID != 4 || page[1].ID != 5 {
+		t.Errorf("GetPage(2, 2) returned %d quotes, want IDs 4, 5", len(page))
+	}
+
+	page, _ = repo.GetPage(context.Background(), 5, 2)
+	if len(page) != 0 {
+		t.Errorf("GetPage(5, 2) returned %d quotes, want 0", len(page))
+	}
+}
+
+func TestInMemoryQuoteRepository_Snapshot(t *testing.T) {
+	repo := in_memory.NewInMemoryQuoteRepository()
+
+	empty, err := repo.Snapshot(context.Background())
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v, want nil", err)
+	}
+
+	quote, _ := repo.Create(context.Background(), entity.QuoteDraft{Author: "Author 1", Text: "Quote 1"})
+	_, _ = repo.Create(context.Background(), entity.QuoteDraft{Author: "Author 2", Text: "Quote 2"})
+	_ = repo.Delete(context.Background(), quote.ID)
+
+	snapshot, _ := repo.Snapshot(context.Background())
+	if snapshot.Revision <= empty.Revision {
+		t.Errorf("Snapshot() revision = %v, want greater than %v", snapshot.Revision, empty.Revision)
+	}
+	if snapshot.MaxID != 2 {
+		t.Errorf("Snapshot() MaxID = %v, want 2", snapshot.MaxID)
+	}
+	if snapshot.Count != 1 {
+		t.Errorf("Snapshot() Count = %v, want 1", snapshot.Count)
+	}
+}
+
+func TestInMemoryQuoteRepository_SnapshotModifiedAt(t *testing.T) {
+	repo := in_memory.NewInMemoryQuoteRepository()
+
+	snapshot, _ := repo.Snapshot(context.Background())
+	if !snapshot.ModifiedAt.IsZero() {
+		t.Errorf("Snapshot() ModifiedAt = %v, want zero for a new repository", snapshot.ModifiedAt)
+	}
+
+	quote, _ := repo.Create(context.Background(), entity.QuoteDraft{Author: "Author", Text: "Quote"})
+	created, _ := repo.Snapshot(context.Background())
+	if created.ModifiedAt.Before(quote.CreatedAt) {
+		t.Errorf("Snapshot() ModifiedAt = %v, want at least %v", created.ModifiedAt, quote.CreatedAt)
+	}
+
+	repo.Delete(context.Background(), quote.ID)
+	deleted, _ := repo.Snapshot(context.Background())
+	if deleted.ModifiedAt.Before(created.ModifiedAt) || deleted.Revision == created.Revision {
+		t.Errorf("Snapshot() after delete = %+v, want a later modification", deleted)
+	}
+}
+
+func TestInMemoryQuoteRepository_ApplyBatch(t *testing.T) {
+	repo := in_memory.NewInMemoryQuoteRepository()
+	quote1, _ := repo.Create(context.Background(), entity.QuoteDraft{Author: "Author 1", Text: "Quote 1"})
+
+	results, err := repo.ApplyBatch(context.Background(), []repository.Operation{
+		{Kind: repository.OperationCreate, Draft: entity.QuoteDraft{Author: "Author 2", Text: "Quote 2"}},
+		{Kind: repository.OperationDelete, ID: quote1.ID},
+		{Kind: repository.OperationCreate, Draft: entity.QuoteDraft{Author: "Author 3", Text: "Quote 3"}},
+	})
+	if err != nil {
+		t.Fatalf("ApplyBatch() error = %v, want nil", err)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("ApplyBatch() returned %d results, want 3", len(results))
+	}
+	if results[0].Quote.ID != 2 || results[2].Quote.ID != 3 {
+		t.Errorf("ApplyBatch() created IDs = %v, %v, want 2, 3", results[0].Quote.ID, results[2].Quote.ID)
+	}
+
+	quotes, _ := repo.GetAll(context.Background())
+	if len(quotes) != 2 {
+		t.Errorf("After ApplyBatch(), found %d quotes, want 2", len(quotes))
+	}
+}
+
+func TestInMemoryQuoteRepository_ApplyBatchIsAtomic(t *testing.T) {
+	repo := in_memory.NewInMemoryQuoteRepository()
+	quote1, _ := repo.Create(context.Background(), entity.QuoteDraft{Author: "Author 1", Text: "Quote 1"})
+
+	results, err := repo.ApplyBatch(context.Background(), []repository.Operation{
+		{Kind: repository.OperationDelete, ID: quote1.ID},
+		{Kind: repository.OperationCreate, Draft: entity.QuoteDraft{Author: "Author 2", Text: "Quote 2"}},
+		{Kind: repository.OperationDelete, ID: quote1.ID},
+	})
+	if !errors.Is(err, entity.ErrBatchAborted) {
+		t.Fatalf("ApplyBatch() error = %v, want %v", err, entity.ErrBatchAborted)
+	}
+
+	if results[0].Err != nil || results[1].Err != nil {
+		t.Errorf("ApplyBatch() unexpected errors for valid operations: %v, %v", results[0].Err, results[1].Err)
+	}
+	if !errors.Is(results[2].Err, entity.ErrQuoteNotFound) {
+		t.Errorf("ApplyBatch() third result error = %v, want %v", results[2].Err, entity.ErrQuoteNotFound)
+	}
+
+	quotes, _ := repo.GetAll(context.Background())
+	if len(quotes) != 1 || quotes[0].ID != quote1.ID {
+		t.Errorf("After aborted ApplyBatch(), repository changed: %d quotes", len(quotes))
+	}
+
+	quote2, _ := repo.Create(context.Background(), entity.QuoteDraft{Author: "Author 2", Text: "Quote 2"})
+	if quote2.ID != 2 {
+		t.Errorf("Created quote ID after aborted batch = %v, want 2", quote2.ID)
+	}
+}
+
+func TestInMemoryQuoteRepository_GetByOwner(t *testing.T) {
+	repo := in_memory.NewInMemoryQuoteRepository()
+
+	_, _ = repo.Create(context.Background(), entity.QuoteDraft{Author: "Author 1", Text: "Quote 1", Owner: "alice"})
+	_, _ = repo.Create(context.Background(), entity.QuoteDraft{Author: "Author 2", Text: "Quote 2", Owner: "bob"})
+	_, _ = repo.Create(context.Background(), entity.QuoteDraft{Author: "Author 3", Text: "Quote 3"})
+
+	quotes, err := repo.GetByOwner(context.Background(), "alice")
+	if err != nil {
+		t.Fatalf("GetByOwner() error = %v", err)
+	}
+	if len(quotes) != 1 || quotes[0].Text != "Quote 1" {
+		t.Errorf("GetByOwner(alice) = %v, want Quote 1 only", quotes)
+	}
+	if quotes, _ := repo.GetByOwner(context.Background(), ""); len(quotes) != 0 {
+		t.Errorf("GetByOwner(\"\") = %d quotes, want 0", len(quotes))
+	}
+}
+
+func TestInMemoryQuoteRepository_CanceledContext(t *testing.T) {
+	repo := in_memory.NewInMemoryQuoteRepository()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := repo.Create(ctx, entity.QuoteDraft{Author: "Author", Text: "Quote"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Create() error = %v, want %v", err, context.Canceled)
+	}
+	if _, err := repo.GetAll(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetAll() error = %v, want %v", err, context.Canceled)
+	}
+	if quotes, _ := repo.GetAll(context.Background()); len(quotes) != 0 {
+		t.Errorf("GetAll() = %d quotes after a canceled Create, want 0", len(quotes))
+	}
+}
+
+func TestInMemoryQuoteRepository_ApplyBatchPrecondition(t *testing.T) {
+	repo := in_memory.NewInMemoryQuoteRepository()
+	quote, _ := repo.Create(context.Background(), entity.QuoteDraft{Author: "Author", Text: "Quote", Owner: "alice"})
+
+	errNotOwner := errors.New("not the owner")
+	var checked *entity.Quote
+	results, err := repo.ApplyBatch(context.Background(), []repository.Operation{{
+		Kind: repository.OperationDelete,
+		ID:   quote.ID,
+		Precondition: func(q *entity.Quote) error {
+			checked = q
+			return errNotOwner
+		},
+	}})
+	if !errors.Is(err, entity.ErrBatchAborted) || !errors.Is(results[0].Err, errNotOwner) {
+		t.Errorf("ApplyBatch() = %v, %v, want the precondition error", results, err)
+	}
+	if checked == nil || checked.Owner != "alice" {
+		t.Errorf("Precondition checked %v, want the stored quote", checked)
+	}
+	if _, err := repo.GetByID(context.Background(), quote.ID); err != nil {
+		t.Errorf("GetByID() after a failed precondition error = %v", err)
+	}
+}
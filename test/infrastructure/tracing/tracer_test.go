@@ -0,0 +1,212 @@
+package tracing_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/Korjick/go-http-quote/domain/quote/entity"
+	"github.com/Korjick/go-http-quote/infrastructure/repository/in_memory"
+	"github.com/Korjick/go-http-quote/infrastructure/tracing"
+)
+
+const remoteParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+type recordingExporter struct {
+	spans    []tracing.SpanData
+	shutdown bool
+	mutex    sync.Mutex
+}
+
+func (e *recordingExporter) Export(_ context.Context, spans []tracing.SpanData) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func (e *recordingExporter) Shutdown(context.Context) error {
+	e.shutdown = true
+	return nil
+}
+
+// finish shuts the tracer down and returns the exported spans by name.
+func finish(t *testing.T, tracer *tracing.Tracer, exporter *recordingExporter) map[string]tracing.SpanData {
+	t.Helper()
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if !exporter.shutdown {
+		t.Error("Exporter was not shut down")
+	}
+	spans := make(map[string]tracing.SpanData)
+	for _, span := range exporter.spans {
+		spans[span.Name] = span
+	}
+	return spans
+}
+
+func TestTracer_ParentsAndPropagation(t *testing.T) {
+	exporter := &recordingExporter{}
+	tracer := tracing.NewTracer(exporter)
+
+	ctx, server := tracer.StartServer(context.Background(), remoteParent, "GET")
+	server.SetName("GET /quotes/{id}")
+	childCtx, child := tracer.Start(ctx, "QuoteService.GetQuote", slog.Int64("quote.id", 7))
+	_, grandchild := tracer.Start(childCtx, "QuoteRepository.GetByID")
+	grandchild.End(entity.ErrQuoteNotFound)
+	child.End(nil)
+	server.End(nil)
+	server.End(errors.New("ignored"))
+
+	spans := finish(t, tracer, exporter)
+	if len(exporter.spans) != 3 {
+		t.Fatalf("Exported %d spans, want 3", len(exporter.spans))
+	}
+	root, service, repo := spans["GET /quotes/{id}"], spans["QuoteService.GetQuote"], spans["QuoteRepository.GetByID"]
+
+	if got := root.SpanContext.TraceID.String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Server span trace ID = %s, want the remote one", got)
+	}
+	if root.Parent.String() != "00f067aa0ba902b7" || root.Kind != tracing.SpanKindServer {
+		t.Errorf("Server span parent = %s, kind = %d", root.Parent, root.Kind)
+	}
+	if service.Parent != root.SpanContext.SpanID || repo.Parent != service.SpanContext.SpanID {
+		t.Error("Spans are not nested server > service > repository")
+	}
+	if service.SpanContext.TraceID != root.SpanContext.TraceID || repo.SpanContext.TraceID != root.SpanContext.TraceID {
+		t.Error("Spans do not share the trace ID")
+	}
+	if root.StatusCode != tracing.StatusUnset {
+		t.Errorf("Server span status = %d after a second End, want unset", root.StatusCode)
+	}
+	if repo.StatusCode != tracing.StatusError || repo.StatusMessage != entity.ErrQuoteNotFound.Error() {
+		t.Errorf("Repository span status = %d %q, want the error", repo.StatusCode, repo.StatusMessage)
+	}
+	if len(service.Attributes) != 1 || service.Attributes[0].Key != "quote.id" {
+		t.Errorf("Service span attributes = %v", service.Attributes)
+	}
+}
+
+func TestTracer_Sampling(t *testing.T) {
+	exporter := &recordingExporter{}
+	tracer := tracing.NewTracer(exporter)
+
+	ctx, unsampled := tracer.StartServer(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", "unsampled")
+	_, child := tracer.Start(ctx, "unsampled child")
+	child.End(nil)
+	unsampled.End(nil)
+	if got := tracing.SpanContextFrom(ctx).Traceparent(); got[:36] != remoteParent[:36] || got[53:] != "00" {
+		t.Errorf("Unsampled context traceparent = %q, want the remote trace, not sampled", got)
+	}
+
+	_, invalid := tracer.StartServer(context.Background(), "garbage", "new trace")
+	invalid.End(nil)
+
+	spans := finish(t, tracer, exporter)
+	if len(spans) != 1 {
+		t.Fatalf("Exported %v, want only the new trace", spans)
+	}
+	if span := spans["new trace"]; span.Parent.IsValid() || !span.SpanContext.Sampled {
+		t.Errorf("Span with an invalid traceparent has parent %s, sampled %v", span.Parent, span.SpanContext.Sampled)
+	}
+}
+
+func TestTraceQuoteRepository(t *testing.T) {
+	exporter := &recordingExporter{}
+	tracer := tracing.NewTracer(exporter)
+	repo := tracing.TraceQuoteRepository(in_memory.NewInMemoryQuoteRepository(), tracer)
+
+	ctx, parent := tracer.Start(context.Background(), "parent")
+	if _, err := repo.Create(ctx, entity.QuoteDraft{Author: "Author", Text: "Text"}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := repo.Delete(ctx, 99); err == nil {
+		t.Fatal("Delete() of a missing quote succeeded")
+	}
+	parent.End(nil)
+
+	spans := finish(t, tracer, exporter)
+	for _, name := range []string{"QuoteRepository.Create", "QuoteRepository.Delete"} {
+		if span, ok := spans[name]; !ok || span.Parent != spans["parent"].SpanContext.SpanID {
+			t.Errorf("No span %s under the parent in %v", name, spans)
+		}
+	}
+	if spans["QuoteRepository.Delete"].StatusCode != tracing.StatusError {
+		t.Error("Failed Delete span has no error status")
+	}
+}
+
+func TestWriterExporter(t *testing.T) {
+	var buf bytes.Buffer
+	tracer := tracing.NewTracer(tracing.NewWriterExporter(&buf))
+	_, span := tracer.StartServer(context.Background(), remoteParent, "GET /quotes", slog.Int("http.response.status_code", 500))
+	span.End(errors.New("Internal Server Error"))
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("Output %q is not a JSON line: %v", buf.String(), err)
+	}
+	want := map[string]any{
+		"name":           "GET /quotes",
+		"trace_id":       "4bf92f3577b34da6a3ce929d0e0e4736",
+		"parent_span_id": "00f067aa0ba902b7",
+		"kind":           "server",
+		"status":         "error",
+		"status_message": "Internal Server Error",
+	}
+	for key, value := range want {
+		if line[key] != value {
+			t.Errorf("%s = %v, want %v", key, line[key], value)
+		}
+	}
+	if attrs, _ := line["attributes"].(map[string]any); attrs["http.response.status_code"] != float64(500) {
+		t.Errorf("attributes = %v", line["attributes"])
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var body map[string]any
+	var contentType string
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		json.NewDecoder(r.Body).Decode(&body)
+	}))
+	defer collector.Close()
+
+	tracer := tracing.NewTracer(tracing.NewOTLPExporter(collector.Client(), collector.URL+"/v1/traces", "quotes"))
+	_, span := tracer.StartServer(context.Background(), remoteParent, "GET /quotes", slog.Int("http.response.status_code", 200))
+	span.End(nil)
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	if contentType != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", contentType)
+	}
+	resourceSpans := body["resourceSpans"].([]any)[0].(map[string]any)
+	resource := resourceSpans["resource"].(map[string]any)["attributes"].([]any)[0].(map[string]any)
+	if resource["key"] != "service.name" || resource["value"].(map[string]any)["stringValue"] != "quotes" {
+		t.Errorf("Resource attribute = %v, want service.name quotes", resource)
+	}
+	otlpSpan := resourceSpans["scopeSpans"].([]any)[0].(map[string]any)["spans"].([]any)[0].(map[string]any)
+	if otlpSpan["traceId"] != "4bf92f3577b34da6a3ce929d0e0e4736" || otlpSpan["parentSpanId"] != "00f067aa0ba902b7" {
+		t.Errorf("Span IDs = %v / %v", otlpSpan["traceId"], otlpSpan["parentSpanId"])
+	}
+	if otlpSpan["kind"] != float64(tracing.SpanKindServer) {
+		t.Errorf("kind = %v, want %d", otlpSpan["kind"], tracing.SpanKindServer)
+	}
+	attr := otlpSpan["attributes"].([]any)[0].(map[string]any)
+	if attr["value"].(map[string]any)["intValue"] != "200" {
+		t.Errorf("Attribute = %v, want intValue \"200\"", attr)
+	}
+}
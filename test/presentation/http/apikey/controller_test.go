@@ -0,0 +1,106 @@
+package apikey_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Korjick/go-http-quote/application/service"
+	"github.com/Korjick/go-http-quote/domain/auth"
+	"github.com/Korjick/go-http-quote/infrastructure/repository/in_memory"
+	"github.com/Korjick/go-http-quote/presentation/http/apikey"
+	"github.com/Korjick/go-http-quote/presentation/http/middleware"
+)
+
+const adminKey = "qk_admin-secret-for-tests"
+
+func setupController(t *testing.T) http.Handler {
+	t.Helper()
+	svc := service.NewAPIKeyService(in_memory.NewInMemoryAPIKeyRepository())
+	if _, err := svc.RegisterAPIKey(auth.System, "admin", auth.RoleAdmin, adminKey); err != nil {
+		t.Fatalf("RegisterAPIKey() error = %v", err)
+	}
+	authentication := middleware.NewAuthentication(middleware.NewAPIKeyAuthenticator(svc))
+	return authentication.Wrap(apikey.NewController(svc, "/admin/api-keys"))
+}
+
+func send(handler http.Handler, method, target, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if key != "" {
+		req.Header.Set(middleware.APIKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestController_CreateListRevoke(t *testing.T) {
+	handler := setupController(t)
+
+	w := send(handler, http.MethodPost, "/admin/api-keys", adminKey, `{"name": "editor", "role": "moderator"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("Cache-Control = %q, want no-store", w.Header().Get("Cache-Control"))
+	}
+	var created apikey.CreatedAPIKeyResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if created.ID != 2 || created.Role != "moderator" || created.CreatedBy != "api-key:1" || !strings.HasPrefix(created.Key, created.Prefix) {
+		t.Errorf("Created key = %+v", created)
+	}
+
+	w = send(handler, http.MethodGet, "/admin/api-keys", created.Key, "")
+	if w.Code != http.StatusForbidden {
+		t.Errorf("GET as moderator status = %d, want %d", w.Code, http.StatusForbidden)
+	}
+
+	w = send(handler, http.MethodGet, "/admin/api-keys", adminKey, "")
+	var keys []apikey.APIKeyResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &keys); err != nil || len(keys) != 2 {
+		t.Fatalf("GET body = %s, error = %v, want 2 keys", w.Body.String(), err)
+	}
+	if strings.Contains(w.Body.String(), created.Key) {
+		t.Error("Listing exposes the secret of a key")
+	}
+
+	if w = send(handler, http.MethodDelete, "/admin/api-keys/2", adminKey, ""); w.Code != http.StatusNoContent {
+		t.Errorf("DELETE status = %d, want %d", w.Code, http.StatusNoContent)
+	}
+	if w = send(handler, http.MethodGet, "/admin/api-keys", created.Key, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("GET with revoked key status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestController_Errors(t *testing.T) {
+	handler := setupController(t)
+
+	tests := []struct {
+		name, method, target, key, body string
+		status                          int
+		code                            string
+	}{
+		{"anonymous", http.MethodGet, "/admin/api-keys", "", "", http.StatusUnauthorized, "unauthenticated"},
+		{"unknown role", http.MethodPost, "/admin/api-keys", adminKey, `{"name": "x", "role": "root"}`, http.StatusUnprocessableEntity, "unknown_role"},
+		{"duplicate name", http.MethodPost, "/admin/api-keys", adminKey, `{"name": "admin", "role": "reader"}`, http.StatusConflict, "api_key_name_exists"},
+		{"invalid id", http.MethodDelete, "/admin/api-keys/abc", adminKey, "", http.StatusBadRequest, "invalid_api_key_id"},
+		{"unknown id", http.MethodDelete, "/admin/api-keys/99", adminKey, "", http.StatusNotFound, "api_key_not_found"},
+	}
+	for _, tt := range tests {
+		w := send(handler, tt.method, tt.target, tt.key, tt.body)
+		var problem struct {
+			Code string `json:"code"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &problem)
+		if w.Code != tt.status || problem.Code != tt.code {
+			t.Errorf("%s: response = %d %q, want %d %q", tt.name, w.Code, problem.Code, tt.status, tt.code)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package http_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Korjick/go-http-quote/domain/apperror"
+	utils "github.com/Korjick/go-http-quote/presentation/http"
+)
+
+type decodeItem struct {
+	Name  string `json:"name"`
+	Count int8   `json:"count,omitempty"`
+}
+
+type decodeRequest struct {
+	Title string       `json:"title"`
+	Items []decodeItem `json:"items"`
+}
+
+func decode(contentType, body string) (decodeRequest, error) {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	var v decodeRequest
+	err := utils.DecodeJSON(httptest.NewRecorder(), req, &v)
+	return v, err
+}
+
+func TestDecodeJSON(t *testing.T) {
+	got, err := decode("application/json; charset=utf-8", `{"title": "t", "items": [{"name": "a", "count": 2}]} `+"\n")
+	want := decodeRequest{Title: "t", Items: []decodeItem{{Name: "a", Count: 2}}}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodeJSON() = %+v, %v, want %+v", got, err, want)
+	}
+
+	if _, err := decode("application/merge-patch+json", `{}`); err != nil {
+		t.Errorf("DecodeJSON() +json suffix error = %v, want nil", err)
+	}
+}
+
+func TestDecodeJSON_Rejects(t *testing.T) {
+	tests := []struct {
+		name, contentType, body string
+		want                    *apperror.Error
+	}{
+		{"no content type", "", `{}`, utils.ErrUnsupportedContentType},
+		{"form content type", "application/x-www-form-urlencoded", `{}`, utils.ErrUnsupportedContentType},
+		{"empty body", "application/json", ``, utils.ErrMalformedJSON},
+		{"syntax error", "application/json", `{"title": }`, utils.ErrMalformedJSON},
+		{"trailing data", "application/json", `{"title": "t"} {"title": "u"}`, utils.ErrTrailingData},
+		{"too large", "application/json", `{"title": "` + strings.Repeat("x", utils.MaxJSONBodySize) + `"}`, utils.ErrBodyTooLarge},
+		{"unknown field", "application/json", `{"title": "t", "extra": 1}`, utils.ErrInvalidBody},
+	}
+
+	for _, tt := range tests {
+		if _, err := decode(tt.contentType, tt.body); !errors.Is(err, tt.want) {
+			t.Errorf("DecodeJSON() %s error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestDecodeJSON_FieldErrors(t *testing.T) {
+	_, err := decode("application/json", `{"title": 5, "items": [{"name": "a"}, {"name": "b", "count": 300, "size": 1}, "c"], "a/b": null}`)
+
+	var bodyErr *utils.BodyError
+	if !errors.As(err, &bodyErr) {
+		t.Fatalf("DecodeJSON() error = %v, want BodyError", err)
+	}
+
+	want := []struct {
+		pointer string
+		err     *apperror.Error
+	}{
+		{"/a~1b", utils.ErrUnknownField},
+		{"/items/1/count", utils.ErrInvalidType},
+		{"/items/1/size", utils.ErrUnknownField},
+		{"/items/2", utils.ErrInvalidType},
+		{"/title", utils.ErrInvalidType},
+	}
+	if len(bodyErr.Fields) != len(want) {
+		t.Fatalf("DecodeJSON() fields = %+v, want %d", bodyErr.Fields, len(want))
+	}
+	for i, field := range bodyErr.Fields {
+		if field.Pointer != want[i].pointer || !errors.Is(field.Err, want[i].err) {
+			t.Errorf("DecodeJSON() field %d = %s %v, want %s %v", i, field.Pointer, field.Err, want[i].pointer, want[i].err)
+		}
+	}
+	if got := bodyErr.Fields[4].Err.Error(); got != "invalid type: expected string, got integer" {
+		t.Errorf("DecodeJSON() /title error = %q, want expected and actual types", got)
+	}
+}
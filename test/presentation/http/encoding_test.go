@@ -0,0 +1,188 @@
+package http_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	utils "github.com/Korjick/go-http-quote/presentation/http"
+	"github.com/Korjick/go-http-quote/presentation/http/quote/dto"
+)
+
+func sampleQuotes() []dto.QuoteResponse {
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	return []dto.QuoteResponse{
+		{ID: 1, Author: "Einstein", Quote: "Quote, 1", CreatedAt: createdAt},
+		{ID: 2, Author: "Jobs", Quote: "Quote 2", CreatedAt: createdAt},
+	}
+}
+
+func TestEncoderRegistry_Negotiate(t *testing.T) {
+	tests := map[string]string{
+		"":                                    "application/json",
+		"*/*":                                 "application/json",
+		"application/json":                    "application/json",
+		"application/vnd.quotes.v2+json":      "application/json",
+		"application/xml":                     "application/xml",
+		"text/csv":                            "text/csv; charset=utf-8",
+		"text/yaml":                           "text/yaml; charset=utf-8",
+		"text/plain":                          "text/plain; charset=utf-8",
+		"text/*":                              "text/plain; charset=utf-8",
+		"image/png, text/csv;q=0.5":           "text/csv; charset=utf-8",
+		"application/json;q=0.1, text/plain":  "text/plain; charset=utf-8",
+		"*/*;q=0.1, application/xml;q=0.1":    "application/xml",
+		"application/json;q=0, text/csv;q=.2": "text/csv; charset=utf-8",
+	}
+
+	for accept, want := range tests {
+		encoder, err := utils.DefaultEncoders.Negotiate(accept)
+		if err != nil {
+			t.Errorf("Negotiate(%q) error = %v", accept, err)
+			continue
+		}
+		if got := encoder.ContentType(); got != want {
+			t.Errorf("Negotiate(%q) = %q, want %q", accept, got, want)
+		}
+	}
+
+	for _, accept := range []string{"image/png", "application/json;q=0"} {
+		if _, err := utils.DefaultEncoders.Negotiate(accept); err == nil {
+			t.Errorf("Negotiate(%q) error = nil, want not acceptable", accept)
+		}
+	}
+}
+
+func encode(t *testing.T, accept string, v any) string {
+	t.Helper()
+	encoder, err := utils.DefaultEncoders.Negotiate(accept)
+	if err != nil {
+		t.Fatalf("Negotiate(%q) error = %v", accept, err)
+	}
+	var buf bytes.Buffer
+	if err := encoder.Encode(&buf, v); err != nil {
+		t.Fatalf("Encode() as %s error = %v", accept, err)
+	}
+	return buf.String()
+}
+
+func TestEncoders_QuoteList(t *testing.T) {
+	quotes := sampleQuotes()
+
+	var list struct {
+		Quotes []dto.QuoteResponse `xml:"quote"`
+	}
+	if err := xml.Unmarshal([]byte(encode(t, "application/xml", quotes)), &list); err != nil {
+		t.Fatalf("Failed to decode XML: %v", err)
+	}
+	if len(list.Quotes) != 2 || list.Quotes[0].Quote != "Quote, 1" {
+		t.Errorf("XML quotes = %+v, want both quotes", list.Quotes)
+	}
+
+	wantCSV := "id,author,quote,created_at\n" +
+		"1,Einstein,\"Quote, 1\",2024-01-02T03:04:05Z\n" +
+		"2,Jobs,Quote 2,2024-01-02T03:04:05Z\n"
+	if got := encode(t, "text/csv", quotes); got != wantCSV {
+		t.Errorf("CSV =\n%s\nwant\n%s", got, wantCSV)
+	}
+
+	wantYAML := "-\n" +
+		"  id: 1\n  author: \"Einstein\"\n  quote: \"Quote, 1\"\n  created_at: \"2024-01-02T03:04:05Z\"\n" +
+		"-\n" +
+		"  id: 2\n  author: \"Jobs\"\n  quote: \"Quote 2\"\n  created_at: \"2024-01-02T03:04:05Z\"\n"
+	if got := encode(t, "application/yaml", quotes); got != wantYAML {
+		t.Errorf("YAML =\n%s\nwant\n%s", got, wantYAML)
+	}
+
+	wantText := "Quote, 1 — Einstein\nQuote 2 — Jobs\n"
+	if got := encode(t, "text/plain", quotes); got != wantText {
+		t.Errorf("text =\n%s\nwant\n%s", got, wantText)
+	}
+}
+
+func TestEncoders_NestedValues(t *testing.T) {
+	report := dto.ImportResponse{
+		Mode:   "atomic",
+		Total:  1,
+		Failed: 1,
+		Errors: []dto.ImportErrorResponse{{Row: 1, Code: "empty_author", Error: "author cannot be empty"}},
+	}
+
+	wantYAML := "mode: \"atomic\"\ntotal: 1\nimported: 0\nfailed: 1\nerrors:\n" +
+		"  -\n    row: 1\n    code: \"empty_author\"\n    error: \"author cannot be empty\"\n"
+	if got := encode(t, "text/plain", report); got != wantYAML {
+		t.Errorf("text =\n%s\nwant\n%s", got, wantYAML)
+	}
+
+	got := encode(t, "text/csv", report)
+	if !strings.HasPrefix(got, "mode,total,imported,failed,errors\n") || !strings.Contains(got, `""code"":""empty_author""`) {
+		t.Errorf("CSV = %s, want one row with errors as JSON", got)
+	}
+}
+
+func TestWriteResponse_NotAcceptable(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/quotes", nil)
+	req.Header.Set("Accept", "image/png")
+	w := httptest.NewRecorder()
+
+	utils.WriteResponse(w, req, http.StatusOK, sampleQuotes())
+
+	if w.Code != http.StatusNotAcceptable {
+		t.Errorf("WriteResponse() status = %v, want %v", w.Code, http.StatusNotAcceptable)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != utils.ProblemContentType {
+		t.Errorf("WriteResponse() Content-Type = %q, want %q", ct, utils.ProblemContentType)
+	}
+}
+
+func TestWriteError_ProblemXML(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/quotes/999", nil)
+	req.Header.Set("Accept", "application/xml")
+	w := httptest.NewRecorder()
+
+	utils.WriteProblem(w, req, utils.NewProblem(req, http.StatusNotFound, "quote_not_found", "quote not found"))
+
+	if ct := w.Header().Get("Content-Type"); ct != "application/problem+xml" {
+		t.Errorf("WriteProblem() Content-Type = %q, want application/problem+xml", ct)
+	}
+	var problem utils.Problem
+	if err := xml.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("Failed to decode problem: %v", err)
+	}
+	if problem.Code != "quote_not_found" || problem.Status != http.StatusNotFound {
+		t.Errorf("WriteProblem() problem = %+v, want quote_not_found", problem)
+	}
+}
+
+func TestAddVary(t *testing.T) {
+	header := http.Header{}
+	utils.AddVary(header, "Accept")
+	utils.AddVary(header, "accept")
+	utils.AddVary(header, "Accept-Language")
+
+	if got := header.Values("Vary"); len(got) != 2 {
+		t.Errorf("AddVary() Vary = %v, want Accept and Accept-Language once", got)
+	}
+}
+
+func TestAcceptsHTML(t *testing.T) {
+	tests := map[string]bool{
+		"": false,
+		"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8": true,
+		"application/xhtml+xml":             true,
+		"application/json, text/html;q=0.5": false,
+		"*/*":                               false,
+		"text/html;q=0.9, application/json;q=0.8": true,
+	}
+
+	for accept, want := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/quotes", nil)
+		req.Header.Set("Accept", accept)
+		if got := utils.AcceptsHTML(req); got != want {
+			t.Errorf("AcceptsHTML(%q) = %v, want %v", accept, got, want)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package i18n_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Korjick/go-http-quote/application/service"
+	"github.com/Korjick/go-http-quote/domain/apperror"
+	"github.com/Korjick/go-http-quote/domain/auth"
+	"github.com/Korjick/go-http-quote/domain/quote/entity"
+	"github.com/Korjick/go-http-quote/infrastructure/jwt"
+	utils "github.com/Korjick/go-http-quote/presentation/http"
+	"github.com/Korjick/go-http-quote/presentation/http/apikey"
+	"github.com/Korjick/go-http-quote/presentation/http/i18n"
+	"github.com/Korjick/go-http-quote/presentation/http/middleware"
+	"github.com/Korjick/go-http-quote/presentation/http/quote"
+	"github.com/Korjick/go-http-quote/presentation/http/quote/bulk"
+	"github.com/Korjick/go-http-quote/presentation/http/quote/card"
+	"github.com/Korjick/go-http-quote/presentation/http/quote/dto"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := map[string]i18n.Language{
+		"":                         i18n.English,
+		"ru":                       i18n.Russian,
+		"ru-RU,ru;q=0.9":           i18n.Russian,
+		"en-US,en;q=0.9,ru;q=0.8":  i18n.English,
+		"de;q=1.0, ru;q=0.5":       i18n.Russian,
+		"en;q=0.1, RU;q=0.7":       i18n.Russian,
+		"ru;q=0, en;q=0.5":         i18n.English,
+		"fr, *;q=0.1":              i18n.English,
+		"ru;q=invalid, en;q=0.001": i18n.English,
+	}
+
+	for header, want := range tests {
+		if got := i18n.Negotiate(header); got != want {
+			t.Errorf("Negotiate(%q) = %v, want %v", header, got, want)
+		}
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	code, message := i18n.Russian.Localize(entity.ErrQuoteNotFound)
+	if code != "quote_not_found" || message != "цитата не найдена" {
+		t.Errorf("Localize() = %q, %q, want quote_not_found, цитата не найдена", code, message)
+	}
+
+	code, message = i18n.Russian.Localize(fmt.Errorf("%w: %q", service.ErrInvalidImportMode, "fast"))
+	if code != "invalid_import_mode" || message != `недопустимый режим импорта: "fast"` {
+		t.Errorf("Localize() wrapped = %q, %q, want message with details", code, message)
+	}
+
+	code, message = i18n.Russian.Localize(fmt.Errorf("import: %w", fmt.Errorf("%w: %q", service.ErrInvalidImportMode, "fast")))
+	if want, _ := i18n.Russian.Message(service.ErrInvalidImportMode.Code()); message != want {
+		t.Errorf("Localize() with context = %q, want %q without the context and details", message, want)
+	}
+
+	code, message = i18n.English.Localize(errors.New("boom"))
+	if code != i18n.CodeInternalError || message != "internal server error" {
+		t.Errorf("Localize() plain error = %q, %q, want internal error", code, message)
+	}
+}
+
+func TestCatalogCoversAllErrors(t *testing.T) {
+	errs := []*apperror.Error{
+		entity.ErrEmptyAuthor,
+		entity.ErrEmptyText,
+		entity.ErrQuoteNotFound,
+		entity.ErrBatchAborted,
+		service.ErrInvalidImportMode,
+		service.ErrEmptyBatch,
+		service.ErrBatchTooLarge,
+		service.ErrSnapshotChanged,
+		bulk.ErrUnsupportedFormat,
+		bulk.ErrMissingColumn,
+		bulk.ErrNotAnArray,
+		bulk.ErrMalformedRecord,
+		bulk.ErrUnsupportedExportFormat,
+		bulk.ErrFortuneTooLarge,
+		dto.ErrUnknownOperation,
+		quote.ErrMalformedJSON,
+		quote.ErrInvalidQuoteID,
+		middleware.ErrIdempotencyKeyTooLong,
+		middleware.ErrIdempotencyKeyReused,
+		middleware.ErrIdempotencyKeyAnonymous,
+		middleware.ErrUnreadableBody,
+		middleware.ErrRateLimited,
+		quote.ErrUnsupportedAPIVersion,
+		utils.ErrNotAcceptable,
+		utils.ErrTimeout,
+		utils.ErrCanceled,
+		card.ErrUnknownTheme,
+		card.ErrUnknownSize,
+		utils.ErrUnsupportedContentType,
+		utils.ErrBodyTooLarge,
+		utils.ErrTrailingData,
+		utils.ErrInvalidBody,
+		utils.ErrUnknownField,
+		utils.ErrInvalidType,
+		auth.ErrUnauthenticated,
+		auth.ErrInvalidAPIKey,
+		auth.ErrInvalidToken,
+		auth.ErrForbidden,
+		auth.ErrUnknownRole,
+		auth.ErrEmptyAPIKeyName,
+		auth.ErrAPIKeyNotFound,
+		auth.ErrAPIKeyNameExists,
+		auth.ErrAPIKeyInUse,
+		auth.ErrAPIKeyTooShort,
+		apikey.ErrInvalidAPIKeyID,
+		jwt.ErrKeySetUnavailable,
+	}
+
+	for _, err := range errs {
+		for _, lang := range []i18n.Language{i18n.English, i18n.Russian} {
+			if _, ok := lang.Message(err.Code()); !ok {
+				t.Errorf("Message(%q) missing for %v", err.Code(), lang)
+			}
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Korjick/go-http-quote/domain/quote/entity"
+	utils "github.com/Korjick/go-http-quote/presentation/http"
+	"github.com/Korjick/go-http-quote/presentation/http/middleware"
+)
+
+func newAccessLog(buf *bytes.Buffer) *middleware.AccessLog {
+	logger := slog.New(utils.NewLogHandler(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	return middleware.NewAccessLog(logger, nil)
+}
+
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Log line %q is not JSON: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestAccessLog_LogsRequest(t *testing.T) {
+	var buf bytes.Buffer
+	router := utils.NewRouter()
+	router.HandleFunc(http.MethodGet, "/quotes/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("hello"))
+	})
+	handler := newAccessLog(&buf).Wrap(router)
+
+	r := httptest.NewRequest(http.MethodGet, "/quotes/7", nil)
+	r.RemoteAddr = "192.0.2.1:1234"
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	records := logRecords(t, &buf)
+	if len(records) != 1 {
+		t.Fatalf("Got %d log records, want 1", len(records))
+	}
+	record := records[0]
+	want := map[string]any{
+		"msg":    "request",
+		"method": "GET",
+		"route":  "GET /quotes/{id}",
+		"path":   "/quotes/7",
+		"status": float64(http.StatusAccepted),
+		"bytes":  float64(5),
+		"client": "192.0.2.1",
+	}
+	for key, value := range want {
+		if record[key] != value {
+			t.Errorf("%s = %v, want %v", key, record[key], value)
+		}
+	}
+	if _, ok := record["latency"]; !ok {
+		t.Error("Record has no latency")
+	}
+	if id, _ := record["request_id"].(string); len(id) != 32 {
+		t.Errorf("request_id = %q, want a generated 32 digit ID", id)
+	}
+}
+
+func TestAccessLog_RequestID(t *testing.T) {
+	var inner string
+	handler := newAccessLog(&bytes.Buffer{}).Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inner = utils.RequestIDFrom(r.Context())
+	}))
+
+	tests := []struct {
+		name     string
+		incoming string
+		kept     bool
+	}{
+		{"propagated", "abc-123", true},
+		{"generated", "", false},
+		{"spaces replaced", "abc 123", false},
+		{"too long replaced", strings.Repeat("a", 129), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.incoming != "" {
+				r.Header.Set(utils.RequestIDHeader, tt.incoming)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			got := w.Header().Get(utils.RequestIDHeader)
+			if got == "" || got != inner {
+				t.Fatalf("Response ID = %q, context ID = %q, want the same non-empty ID", got, inner)
+			}
+			if (got == tt.incoming) != tt.kept {
+				t.Errorf("ID = %q for incoming %q, kept = %v", got, tt.incoming, tt.kept)
+			}
+		})
+	}
+}
+
+func TestAccessLog_RequestIDInProblemAndLogs(t *testing.T) {
+	var buf bytes.Buffer
+	handler := newAccessLog(&buf).Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		slog.New(utils.NewLogHandler(slog.NewJSONHandler(&buf, nil))).InfoContext(r.Context(), "inner")
+		utils.WriteError(w, r, entity.ErrQuoteNotFound)
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/quotes/9", nil)
+	r.Header.Set(utils.RequestIDHeader, "req-42")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	var problem utils.Problem
+	if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+		t.Fatalf("Decoding problem: %v", err)
+	}
+	if problem.RequestID != "req-42" {
+		t.Errorf("Problem request_id = %q, want %q", problem.RequestID, "req-42")
+	}
+	for _, record := range logRecords(t, &buf) {
+		if record["request_id"] != "req-42" {
+			t.Errorf("Record %v has request_id %v, want req-42", record["msg"], record["request_id"])
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Korjick/go-http-quote/application/service"
+	"github.com/Korjick/go-http-quote/domain/auth"
+	"github.com/Korjick/go-http-quote/infrastructure/repository/in_memory"
+	utils "github.com/Korjick/go-http-quote/presentation/http"
+	"github.com/Korjick/go-http-quote/presentation/http/middleware"
+)
+
+func setupAuthentication(t *testing.T) (*middleware.Authentication, string) {
+	t.Helper()
+	svc := service.NewAPIKeyService(in_memory.NewInMemoryAPIKeyRepository())
+	_, secret, err := svc.CreateAPIKey(auth.System, "editor", auth.RoleContributor)
+	if err != nil {
+		t.Fatalf("CreateAPIKey() error = %v", err)
+	}
+	return middleware.NewAuthentication(middleware.NewAPIKeyAuthenticator(svc)), secret
+}
+
+// principalHandler responds with the subject of the principal, or 401 for
+// anonymous requests.
+func principalHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal := utils.PrincipalFrom(r)
+		if !principal.Authenticated() {
+			utils.WriteError(w, r, auth.ErrUnauthenticated)
+			return
+		}
+		_, _ = w.Write([]byte(principal.Subject + " " + string(principal.Role)))
+	})
+}
+
+func TestAuthentication_APIKey(t *testing.T) {
+	authentication, secret := setupAuthentication(t)
+	handler := authentication.Wrap(principalHandler())
+
+	req := httptest.NewRequest(http.MethodPost, "/quotes", nil)
+	req.Header.Set(middleware.APIKeyHeader, secret)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "api-key:1 contributor" {
+		t.Errorf("Response = %d %q, want 200 %q", w.Code, w.Body.String(), "api-key:1 contributor")
+	}
+}
+
+func TestAuthentication_Challenges(t *testing.T) {
+	authentication, secret := setupAuthentication(t)
+	handler := authentication.Wrap(principalHandler())
+
+	for name, key := range map[string]string{"anonymous": "", "invalid key": secret + "x"} {
+		req := httptest.NewRequest(http.MethodPost, "/quotes", nil)
+		if key != "" {
+			req.Header.Set(middleware.APIKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s: status = %d, want %d", name, w.Code, http.StatusUnauthorized)
+		}
+		if got, want := w.Header().Get("WWW-Authenticate"), `APIKey header="X-API-Key"`; got != want {
+			t.Errorf("%s: WWW-Authenticate = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestIdempotency_ScopedToPrincipal(t *testing.T) {
+	var calls atomic.Int32
+	authentication, secret := setupAuthentication(t)
+	handler := authentication.Wrap(middleware.NewIdempotency(time.Hour).Wrap(countingHandler(&calls, http.StatusCreated)))
+
+	send := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/quotes", nil)
+		req.Header.Set(middleware.IdempotencyKeyHeader, "key-1")
+		if key != "" {
+			req.Header.Set(middleware.APIKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+	send(secret)
+	if w := send(""); w.Code != http.StatusUnauthorized || w.Header().Get(middleware.IdempotentReplayedHeader) != "" {
+		t.Errorf("Anonymous retry = %d, want %d without a replay", w.Code, http.StatusUnauthorized)
+	}
+	if w := send(secret); w.Header().Get(middleware.IdempotentReplayedHeader) != "true" {
+		t.Error("Retry with the same API key was not replayed")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("Handler called %d times, want 1", calls.Load())
+	}
+}
@@ -0,0 +1,109 @@
+package middleware_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Korjick/go-http-quote/domain/auth"
+	"github.com/Korjick/go-http-quote/infrastructure/jwt"
+	"github.com/Korjick/go-http-quote/presentation/http/middleware"
+)
+
+// staticVerifier accepts the tokens it knows.
+type staticVerifier map[string]map[string]any
+
+func (v staticVerifier) Verify(_ context.Context, token string) (map[string]any, error) {
+	claims, ok := v[token]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown token", auth.ErrInvalidToken)
+	}
+	return claims, nil
+}
+
+func TestRoleMapping_Role(t *testing.T) {
+	mapping := middleware.RoleMapping{
+		Claim: "realm_access.roles",
+		Roles: map[string]auth.Role{"editors": auth.RoleContributor, "moderators": auth.RoleModerator},
+	}
+	tests := []struct {
+		claims map[string]any
+		want   auth.Role
+	}{
+		{map[string]any{"realm_access": map[string]any{"roles": []any{"editors", "moderators"}}}, auth.RoleModerator},
+		{map[string]any{"realm_access": map[string]any{"roles": []any{"editors", "unknown"}}}, auth.RoleContributor},
+		{map[string]any{"realm_access": map[string]any{"roles": "moderators"}}, auth.RoleModerator},
+		{map[string]any{"realm_access": "editors"}, auth.RoleReader},
+		{map[string]any{}, auth.RoleReader},
+	}
+	for _, tt := range tests {
+		if got := mapping.Role(tt.claims); got != tt.want {
+			t.Errorf("Role(%v) = %q, want %q", tt.claims, got, tt.want)
+		}
+	}
+
+	scope := middleware.RoleMapping{Claim: "scope"}
+	if got := scope.Role(map[string]any{"scope": "openid contributor admin"}); got != auth.RoleAdmin {
+		t.Errorf("Role(scope) without mapping = %q, want %q", got, auth.RoleAdmin)
+	}
+}
+
+func TestAuthentication_Bearer(t *testing.T) {
+	verifier := staticVerifier{"good": {"sub": "user-42", "roles": []any{"moderator"}}}
+	authentication := middleware.NewAuthentication(
+		middleware.NewBearerAuthenticator(verifier, middleware.RoleMapping{Claim: "roles"}),
+	)
+	handler := authentication.Wrap(principalHandler())
+
+	tests := []struct {
+		authorization string
+		status        int
+		body          string
+	}{
+		{"Bearer good", http.StatusOK, "jwt:user-42 moderator"},
+		{"bearer good", http.StatusOK, "jwt:user-42 moderator"},
+		{"Bearer forged", http.StatusUnauthorized, ""},
+		{"Basic dXNlcjpwYXNz", http.StatusUnauthorized, ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/quotes", nil)
+		req.Header.Set("Authorization", tt.authorization)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != tt.status || (tt.body != "" && w.Body.String() != tt.body) {
+			t.Errorf("%s: response = %d %q, want %d %q", tt.authorization, w.Code, w.Body.String(), tt.status, tt.body)
+		}
+		if w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") != `Bearer realm="quotes"` {
+			t.Errorf("%s: WWW-Authenticate = %q", tt.authorization, w.Header().Get("WWW-Authenticate"))
+		}
+	}
+}
+
+// unavailableVerifier fails like a verifier whose key set never loaded.
+type unavailableVerifier struct{}
+
+func (unavailableVerifier) Verify(context.Context, string) (map[string]any, error) {
+	return nil, fmt.Errorf("%w: connection refused", jwt.ErrKeySetUnavailable)
+}
+
+func TestAuthentication_BearerKeySetUnavailable(t *testing.T) {
+	authentication := middleware.NewAuthentication(
+		middleware.NewBearerAuthenticator(unavailableVerifier{}, middleware.RoleMapping{Claim: "roles"}),
+	)
+	handler := authentication.Wrap(principalHandler())
+
+	req := httptest.NewRequest(http.MethodPost, "/quotes", nil)
+	req.Header.Set("Authorization", "Bearer any")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+	if got := w.Header().Get("Retry-After"); got != "30" {
+		t.Errorf("Retry-After = %q, want 30", got)
+	}
+}
@@ -0,0 +1,240 @@
+package middleware_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Korjick/go-http-quote/domain/auth"
+	utils "github.com/Korjick/go-http-quote/presentation/http"
+	"github.com/Korjick/go-http-quote/presentation/http/middleware"
+)
+
+func countingHandler(calls *atomic.Int32, status int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = fmt.Fprintf(w, `{"call": %d}`, n)
+	})
+}
+
+var idempotencyCaller = auth.Principal{Subject: "key-id", Role: auth.RoleContributor}
+
+func sendWithKey(handler http.Handler, method, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/quotes", strings.NewReader(body))
+	req = utils.WithPrincipal(req, idempotencyCaller)
+	if key != "" {
+		req.Header.Set(middleware.IdempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency_ReplaysFirstResponse(t *testing.T) {
+	var calls atomic.Int32
+	handler := middleware.NewIdempotency(time.Hour).Wrap(countingHandler(&calls, http.StatusCreated))
+
+	first := sendWithKey(handler, http.MethodPost, "key-1", `{"author": "A", "quote": "Q"}`)
+	second := sendWithKey(handler, http.MethodPost, "key-1", `{"author": "A", "quote": "Q"}`)
+
+	if calls.Load() != 1 {
+		t.Errorf("Handler called %d times, want 1", calls.Load())
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Errorf("Replayed response = %d %q, want %d %q", second.Code, second.Body.String(), first.Code, first.Body.String())
+	}
+	if second.Header().Get(middleware.IdempotentReplayedHeader) != "true" {
+		t.Error("Replayed response is missing Idempotent-Replayed header")
+	}
+	if second.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Replayed Content-Type = %q, want application/json", second.Header().Get("Content-Type"))
+	}
+}
+
+func TestIdempotency_ReplaysOnlyHandlerHeaders(t *testing.T) {
+	var calls atomic.Int32
+	idempotent := middleware.NewIdempotency(time.Hour).Wrap(countingHandler(&calls, http.StatusCreated))
+	var requests atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		w.Header().Set("X-Request-ID", fmt.Sprintf("request-%d", n))
+		w.Header().Set("RateLimit-Remaining", fmt.Sprint(30-n))
+		idempotent.ServeHTTP(w, r)
+	})
+
+	sendWithKey(handler, http.MethodPost, "key-1", `{}`)
+	second := sendWithKey(handler, http.MethodPost, "key-1", `{}`)
+
+	if second.Header().Get(middleware.IdempotentReplayedHeader) != "true" {
+		t.Fatal("Retry was not replayed")
+	}
+	if got := second.Header().Get("X-Request-ID"); got != "request-2" {
+		t.Errorf("Replayed X-Request-ID = %q, want request-2", got)
+	}
+	if got := second.Header().Get("RateLimit-Remaining"); got != "28" {
+		t.Errorf("Replayed RateLimit-Remaining = %q, want 28", got)
+	}
+	if got := second.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Replayed Content-Type = %q, want application/json", got)
+	}
+}
+
+func TestIdempotency_RefusesAnonymousKeys(t *testing.T) {
+	var calls atomic.Int32
+	handler := middleware.NewIdempotency(time.Hour).Wrap(countingHandler(&calls, http.StatusCreated))
+
+	req := httptest.NewRequest(http.MethodPost, "/quotes", strings.NewReader(`{}`))
+	req.Header.Set(middleware.IdempotencyKeyHeader, "key-1")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+	if calls.Load() != 0 {
+		t.Errorf("Handler called %d times for an anonymous key, want 0", calls.Load())
+	}
+}
+
+func TestIdempotency_ConflictingBody(t *testing.T) {
+	var calls atomic.Int32
+	handler := middleware.NewIdempotency(time.Hour).Wrap(countingHandler(&calls, http.StatusCreated))
+
+	sendWithKey(handler, http.MethodPost, "key-1", `{"author": "A", "quote": "Q"}`)
+	w := sendWithKey(handler, http.MethodPost, "key-1", `{"author": "B", "quote": "Q"}`)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Conflicting request status = %v, want %v", w.Code, http.StatusUnprocessableEntity)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("Handler called %d times, want 1", calls.Load())
+	}
+}
+
+func TestIdempotency_WithoutKeyOrForReads(t *testing.T) {
+	var calls atomic.Int32
+	handler := middleware.NewIdempotency(time.Hour).Wrap(countingHandler(&calls, http.StatusOK))
+
+	sendWithKey(handler, http.MethodPost, "", `{}`)
+	sendWithKey(handler, http.MethodPost, "", `{}`)
+	sendWithKey(handler, http.MethodGet, "key-1", "")
+	sendWithKey(handler, http.MethodGet, "key-1", "")
+
+	if calls.Load() != 4 {
+		t.Errorf("Handler called %d times, want 4", calls.Load())
+	}
+}
+
+func TestIdempotency_ServerErrorsAreNotStored(t *testing.T) {
+	var calls atomic.Int32
+	handler := middleware.NewIdempotency(time.Hour).Wrap(countingHandler(&calls, http.StatusInternalServerError))
+
+	sendWithKey(handler, http.MethodPost, "key-1", `{}`)
+	sendWithKey(handler, http.MethodPost, "key-1", `{}`)
+
+	if calls.Load() != 2 {
+		t.Errorf("Handler called %d times, want 2", calls.Load())
+	}
+}
+
+func TestIdempotency_CanceledRequestsAreNotStored(t *testing.T) {
+	var calls atomic.Int32
+	handler := middleware.NewIdempotency(time.Hour).Wrap(countingHandler(&calls, utils.StatusClientClosedRequest))
+	sendWithKey(handler, http.MethodPost, "key-1", `{}`)
+	sendWithKey(handler, http.MethodPost, "key-1", `{}`)
+	if calls.Load() != 2 {
+		t.Errorf("Handler called %d times after 499 responses, want 2", calls.Load())
+	}
+
+	calls.Store(0)
+	handler = middleware.NewIdempotency(time.Hour).Wrap(countingHandler(&calls, http.StatusCreated))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodPost, "/quotes", strings.NewReader(`{}`)).WithContext(ctx)
+	req = utils.WithPrincipal(req, idempotencyCaller)
+	req.Header.Set(middleware.IdempotencyKeyHeader, "key-2")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	sendWithKey(handler, http.MethodPost, "key-2", `{}`)
+	if calls.Load() != 2 {
+		t.Errorf("Handler called %d times after a canceled request, want 2", calls.Load())
+	}
+}
+
+func TestIdempotency_ExpiresAfterTTL(t *testing.T) {
+	var calls atomic.Int32
+	handler := middleware.NewIdempotency(10 * time.Millisecond).Wrap(countingHandler(&calls, http.StatusCreated))
+
+	sendWithKey(handler, http.MethodPost, "key-1", `{}`)
+	time.Sleep(20 * time.Millisecond)
+	sendWithKey(handler, http.MethodPost, "key-1", `{"different": true}`)
+
+	if calls.Load() != 2 {
+		t.Errorf("Handler called %d times, want 2", calls.Load())
+	}
+}
+
+func TestIdempotency_ConcurrentDuplicatesWait(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		countingHandler(&calls, http.StatusCreated).ServeHTTP(w, r)
+	})
+	handler := middleware.NewIdempotency(time.Hour).Wrap(slow)
+
+	var wg sync.WaitGroup
+	responses := make([]*httptest.ResponseRecorder, 5)
+	for i := range responses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i] = sendWithKey(handler, http.MethodPost, "key-1", `{}`)
+		}(i)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("Handler called %d times, want 1", calls.Load())
+	}
+	for i, w := range responses {
+		if w.Code != http.StatusCreated || w.Body.String() != `{"call": 1}` {
+			t.Errorf("Response %d = %d %q, want 201 from the first call", i, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestIdempotency_KeyTooLong(t *testing.T) {
+	var calls atomic.Int32
+	handler := middleware.NewIdempotency(time.Hour).Wrap(countingHandler(&calls, http.StatusCreated))
+
+	w := sendWithKey(handler, http.MethodPost, strings.Repeat("k", 256), `{}`)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Long key status = %v, want %v", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestIdempotency_BodyTooLarge(t *testing.T) {
+	var calls atomic.Int32
+	handler := middleware.NewIdempotency(time.Hour).Wrap(countingHandler(&calls, http.StatusCreated))
+
+	w := sendWithKey(handler, http.MethodPost, "key-1", strings.Repeat("x", middleware.MaxIdempotentBodySize+1))
+
+	if w.Code != http.StatusRequestEntityTooLarge || !strings.Contains(w.Body.String(), "body_too_large") {
+		t.Errorf("Response = %d %s, want 413 body_too_large", w.Code, w.Body.String())
+	}
+	if calls.Load() != 0 {
+		t.Errorf("Handler called %d times, want 0", calls.Load())
+	}
+}
@@ -0,0 +1,72 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	utils "github.com/Korjick/go-http-quote/presentation/http"
+	"github.com/Korjick/go-http-quote/presentation/http/middleware"
+)
+
+type observedRequest struct {
+	method string
+	route  string
+	status int
+}
+
+type recordingObserver struct {
+	requests []observedRequest
+}
+
+func (o *recordingObserver) ObserveRequest(method, route string, status int, duration time.Duration) {
+	o.requests = append(o.requests, observedRequest{method, route, status})
+}
+
+func TestMetrics_ObservesRoutePattern(t *testing.T) {
+	router := utils.NewRouter()
+	router.HandleFunc(http.MethodDelete, "/quotes/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux := http.NewServeMux()
+	mux.Handle("/quotes/", router)
+	mux.HandleFunc("/docs", func(w http.ResponseWriter, r *http.Request) {})
+
+	observer := &recordingObserver{}
+	handler := middleware.NewMetrics(observer).Wrap(mux)
+
+	for _, target := range []string{"/quotes/1", "/quotes/2", "/docs", "/elsewhere"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, target, nil))
+	}
+
+	want := []observedRequest{
+		{http.MethodDelete, "DELETE /quotes/{id}", http.StatusNoContent},
+		{http.MethodDelete, "DELETE /quotes/{id}", http.StatusNoContent},
+		{http.MethodDelete, "/docs", http.StatusOK},
+		{http.MethodDelete, "unmatched", http.StatusNotFound},
+	}
+	if len(observer.requests) != len(want) {
+		t.Fatalf("Observed %d requests, want %d", len(observer.requests), len(want))
+	}
+	for i := range want {
+		if observer.requests[i] != want[i] {
+			t.Errorf("Request %d observed as %+v, want %+v", i, observer.requests[i], want[i])
+		}
+	}
+}
+
+func TestMetrics_NonStandardMethods(t *testing.T) {
+	observer := &recordingObserver{}
+	handler := middleware.NewMetrics(observer).Wrap(http.NotFoundHandler())
+
+	for _, method := range []string{"PURGE", "X-RANDOM-1", http.MethodPatch} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, "/quotes", nil))
+	}
+
+	for i, want := range []string{"OTHER", "OTHER", http.MethodPatch} {
+		if got := observer.requests[i].method; got != want {
+			t.Errorf("Request %d observed with method %q, want %q", i, got, want)
+		}
+	}
+}
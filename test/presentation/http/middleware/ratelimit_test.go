@@ -0,0 +1,208 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Korjick/go-http-quote/domain/auth"
+	utils "github.com/Korjick/go-http-quote/presentation/http"
+	"github.com/Korjick/go-http-quote/presentation/http/middleware"
+	"github.com/Korjick/go-http-quote/presentation/http/openapi"
+)
+
+func okHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+type rateLimitRequest struct {
+	method       string
+	remoteAddr   string
+	forwardedFor string
+	principal    auth.Principal
+}
+
+func sendLimited(handler http.Handler, req rateLimitRequest) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(req.method, "/quotes", nil)
+	if req.remoteAddr != "" {
+		r.RemoteAddr = req.remoteAddr
+	}
+	if req.forwardedFor != "" {
+		r.Header.Set("X-Forwarded-For", req.forwardedFor)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, utils.WithPrincipal(r, req.principal))
+	return w
+}
+
+func TestRateLimiter_BucketAndHeaders(t *testing.T) {
+	limit := middleware.RateLimit{Requests: 3, Per: time.Minute}
+	handler := middleware.NewRateLimiter(limit, limit, nil).Wrap(okHandler())
+	get := rateLimitRequest{method: http.MethodGet}
+
+	for i := range 3 {
+		w := sendLimited(handler, get)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Request %d status = %d, want %d", i+1, w.Code, http.StatusOK)
+		}
+		if got, want := w.Header().Get(middleware.RateLimitRemainingHeader), strconv.Itoa(2-i); got != want {
+			t.Errorf("Request %d RateLimit-Remaining = %q, want %q", i+1, got, want)
+		}
+	}
+
+	w := sendLimited(handler, get)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Request over the limit status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+	// One token comes back every 20 seconds.
+	if retryAfter, _ := strconv.Atoi(w.Header().Get("Retry-After")); retryAfter < 1 || retryAfter > 20 {
+		t.Errorf("Retry-After = %q, want 1..20 seconds", w.Header().Get("Retry-After"))
+	}
+	if reset, _ := strconv.Atoi(w.Header().Get(middleware.RateLimitResetHeader)); reset < 40 || reset > 60 {
+		t.Errorf("RateLimit-Reset = %q, want 40..60 seconds", w.Header().Get(middleware.RateLimitResetHeader))
+	}
+	if w.Header().Get(middleware.RateLimitLimitHeader) != "3" || w.Header().Get(middleware.RateLimitPolicyHeader) != "3;w=60" {
+		t.Errorf("RateLimit-Limit = %q, RateLimit-Policy = %q", w.Header().Get(middleware.RateLimitLimitHeader), w.Header().Get(middleware.RateLimitPolicyHeader))
+	}
+}
+
+func TestRateLimiter_SeparateBudgets(t *testing.T) {
+	handler := middleware.NewRateLimiter(
+		middleware.RateLimit{Requests: 1, Per: time.Minute},
+		middleware.RateLimit{Requests: 1, Per: time.Minute},
+		nil,
+	).Wrap(okHandler())
+	alice := auth.Principal{Subject: "alice", Role: auth.RoleContributor}
+	bob := auth.Principal{Subject: "bob", Role: auth.RoleContributor}
+
+	tests := []struct {
+		name string
+		req  rateLimitRequest
+		want int
+	}{
+		{"first read", rateLimitRequest{method: http.MethodGet, principal: alice}, http.StatusOK},
+		{"second read", rateLimitRequest{method: http.MethodGet, principal: alice}, http.StatusTooManyRequests},
+		{"write", rateLimitRequest{method: http.MethodPost, principal: alice}, http.StatusOK},
+		{"read of another principal", rateLimitRequest{method: http.MethodGet, principal: bob}, http.StatusOK},
+		{"anonymous read from the same address", rateLimitRequest{method: http.MethodGet}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		if w := sendLimited(handler, tt.req); w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+}
+
+func TestRateLimiter_TrustedProxies(t *testing.T) {
+	limit := middleware.RateLimit{Requests: 1, Per: time.Minute}
+	proxies := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+	handler := middleware.NewRateLimiter(limit, limit, proxies).Wrap(okHandler())
+
+	tests := []struct {
+		name string
+		req  rateLimitRequest
+		want int
+	}{
+		{"client behind proxy", rateLimitRequest{method: http.MethodGet, remoteAddr: "10.0.0.1:1234", forwardedFor: "203.0.113.7"}, http.StatusOK},
+		{"same client through another proxy hop", rateLimitRequest{method: http.MethodGet, remoteAddr: "10.0.0.2:1234", forwardedFor: "203.0.113.7, 10.0.0.1"}, http.StatusTooManyRequests},
+		{"spoofed header behind proxy", rateLimitRequest{method: http.MethodGet, remoteAddr: "10.0.0.1:1234", forwardedFor: "198.51.100.1, 203.0.113.9"}, http.StatusOK},
+		{"untrusted client sending the header", rateLimitRequest{method: http.MethodGet, remoteAddr: "192.0.2.1:1234", forwardedFor: "203.0.113.50"}, http.StatusOK},
+		{"header ignored for untrusted client", rateLimitRequest{method: http.MethodGet, remoteAddr: "192.0.2.1:1234", forwardedFor: "203.0.113.51"}, http.StatusTooManyRequests},
+	}
+	for _, tt := range tests {
+		if w := sendLimited(handler, tt.req); w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+}
+
+func TestRateLimiter_GroupBudgets(t *testing.T) {
+	one := middleware.RateLimit{Requests: 1, Per: time.Minute}
+	limiter := middleware.NewRateLimiter(one, one, nil,
+		middleware.WithGroupBudget("admin", middleware.Budget{Read: middleware.RateLimit{Requests: 2, Per: time.Minute}, Write: one}))
+	quotes := limiter.WrapGroup("quotes", okHandler())
+	me := limiter.WrapGroup("me", okHandler())
+	admin := limiter.WrapGroup("admin", okHandler())
+	get := rateLimitRequest{method: http.MethodGet}
+
+	if w := sendLimited(quotes, get); w.Code != http.StatusOK {
+		t.Fatalf("First read of quotes status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if w := sendLimited(quotes, get); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Second read of quotes status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+	if w := sendLimited(me, get); w.Code != http.StatusOK {
+		t.Errorf("Read of another group status = %d, want %d", w.Code, http.StatusOK)
+	}
+	for i := range 2 {
+		if w := sendLimited(admin, get); w.Code != http.StatusOK || w.Header().Get(middleware.RateLimitLimitHeader) != "2" {
+			t.Errorf("Read %d of admin status = %d, RateLimit-Limit = %q, want 200 and 2", i+1, w.Code, w.Header().Get(middleware.RateLimitLimitHeader))
+		}
+	}
+}
+
+// rejectingAuthenticator accepts the API key "good" and rejects any other.
+type rejectingAuthenticator struct{}
+
+func (rejectingAuthenticator) Authenticate(r *http.Request) (auth.Principal, bool, error) {
+	switch key := r.Header.Get(middleware.APIKeyHeader); key {
+	case "":
+		return auth.Anonymous, false, nil
+	case "good":
+		return auth.Principal{Subject: "api-key:1", Role: auth.RoleAdmin}, true, nil
+	default:
+		return auth.Anonymous, false, auth.ErrInvalidAPIKey
+	}
+}
+
+func (rejectingAuthenticator) Challenge() string { return "APIKey" }
+
+func (rejectingAuthenticator) SecurityScheme() (string, openapi.SecurityScheme) {
+	return "apiKey", openapi.SecurityScheme{}
+}
+
+func TestRateLimiter_CountsRejectedCredentials(t *testing.T) {
+	plenty := middleware.RateLimit{Requests: 100, Per: time.Minute}
+	limiter := middleware.NewRateLimiter(plenty, plenty, nil,
+		middleware.WithFailureLimit(middleware.RateLimit{Requests: 3, Per: time.Minute}))
+	authentication := middleware.NewAuthentication(rejectingAuthenticator{})
+	handler := limiter.WrapCredentials(authentication.Wrap(limiter.Wrap(okHandler())))
+	send := func(key, addr string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/quotes", nil)
+		r.RemoteAddr = addr
+		r.Header.Set(middleware.APIKeyHeader, key)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	for i := range 3 {
+		w := send("guess", "192.0.2.1:1234")
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("Guess %d status = %d, want %d", i+1, w.Code, http.StatusUnauthorized)
+		}
+		if got, want := w.Header().Get(middleware.RateLimitRemainingHeader), strconv.Itoa(2-i); got != want {
+			t.Errorf("Guess %d RateLimit-Remaining = %q, want %q", i+1, got, want)
+		}
+	}
+	w := send("guess", "192.0.2.1:1234")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Errorf("Guess over the limit status = %d, Retry-After = %q, want 429 with Retry-After", w.Code, w.Header().Get("Retry-After"))
+	}
+	if w := send("good", "192.0.2.1:1234"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Valid key from the blocked address status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+	if w := send("good", "192.0.2.2:1234"); w.Code != http.StatusOK {
+		t.Errorf("Valid key from another address status = %d, want %d", w.Code, http.StatusOK)
+	}
+	for range 5 {
+		if w := send("good", "192.0.2.2:1234"); w.Code != http.StatusOK {
+			t.Fatalf("Accepted key status = %d, want %d: accepted keys are not failures", w.Code, http.StatusOK)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package middleware_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Korjick/go-http-quote/application/service"
+	utils "github.com/Korjick/go-http-quote/presentation/http"
+	"github.com/Korjick/go-http-quote/presentation/http/middleware"
+)
+
+type spanKey struct{}
+
+type fakeSpan struct {
+	traceparent string
+	name        string
+	attrs       map[string]string
+	ended       bool
+	err         error
+}
+
+func (s *fakeSpan) SetName(name string) { s.name = name }
+
+func (s *fakeSpan) SetAttributes(attrs ...slog.Attr) {
+	for _, attr := range attrs {
+		s.attrs[attr.Key] = attr.Value.String()
+	}
+}
+
+func (s *fakeSpan) End(err error) { s.ended, s.err = true, err }
+
+type fakeTracer struct {
+	spans []*fakeSpan
+}
+
+func (t *fakeTracer) StartServer(ctx context.Context, traceparent, name string, attrs ...slog.Attr) (context.Context, service.Span) {
+	span := &fakeSpan{traceparent: traceparent, name: name, attrs: make(map[string]string)}
+	span.SetAttributes(attrs...)
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func TestTracing_ServerSpan(t *testing.T) {
+	var inner any
+	router := utils.NewRouter()
+	router.HandleFunc(http.MethodGet, "/quotes/{id}", func(w http.ResponseWriter, r *http.Request) {
+		inner = r.Context().Value(spanKey{})
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	mux := http.NewServeMux()
+	mux.Handle("/quotes/", router)
+	mux.HandleFunc("/docs", func(w http.ResponseWriter, r *http.Request) {})
+
+	tracer := &fakeTracer{}
+	handler := middleware.NewTracing(tracer).Wrap(mux)
+
+	r := httptest.NewRequest(http.MethodGet, "/quotes/7", nil)
+	r.Header.Set(middleware.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/docs", nil))
+
+	if len(tracer.spans) != 2 {
+		t.Fatalf("Started %d spans, want 2", len(tracer.spans))
+	}
+	span := tracer.spans[0]
+	if inner != span {
+		t.Error("Handler context does not carry the server span")
+	}
+	if span.traceparent != r.Header.Get(middleware.TraceparentHeader) {
+		t.Errorf("traceparent = %q, want the request header", span.traceparent)
+	}
+	if span.name != "GET /quotes/{id}" || span.attrs["http.route"] != "/quotes/{id}" {
+		t.Errorf("Span name = %q, route = %q", span.name, span.attrs["http.route"])
+	}
+	if span.attrs["http.response.status_code"] != "500" || !span.ended || span.err == nil {
+		t.Errorf("Span status = %s, ended = %v, err = %v, want a failed 500", span.attrs["http.response.status_code"], span.ended, span.err)
+	}
+
+	if docs := tracer.spans[1]; docs.name != "GET /docs" || docs.err != nil {
+		t.Errorf("Docs span name = %q, err = %v", docs.name, docs.err)
+	}
+}
@@ -0,0 +1,85 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Korjick/go-http-quote/presentation/http/openapi"
+)
+
+type item struct {
+	ID      int64     `json:"id"`
+	Name    string    `json:"name,omitempty"`
+	Tags    []string  `json:"tags"`
+	Parent  *item     `json:"parent,omitempty"`
+	Created time.Time `json:"created_at"`
+	Hidden  string    `json:"-"`
+	secret  string
+}
+
+func TestSchemaOf(t *testing.T) {
+	doc := openapi.NewDocument(openapi.Info{Title: "Test", Version: "1"})
+
+	ref := doc.SchemaOf([]item{})
+	if ref.Type != "array" || ref.Items.Ref != "#/components/schemas/item" {
+		t.Fatalf("SchemaOf([]item) = %+v, want array of item reference", ref)
+	}
+
+	schema := doc.Components.Schemas["item"]
+	var names []string
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	if want := []string{"created_at", "id", "name", "parent", "tags"}; !slices.Equal(names, want) {
+		t.Errorf("item properties = %v, want %v", names, want)
+	}
+	if want := []string{"id", "tags", "created_at"}; !slices.Equal(schema.Required, want) {
+		t.Errorf("item required = %v, want %v", schema.Required, want)
+	}
+	if got := schema.Properties["created_at"]; got.Type != "string" || got.Format != "date-time" {
+		t.Errorf("created_at schema = %+v, want date-time string", got)
+	}
+	if got := schema.Properties["parent"].Ref; got != "#/components/schemas/item" {
+		t.Errorf("parent schema ref = %q, want self reference", got)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	doc := openapi.NewDocument(openapi.Info{Title: "Test", Version: "1"})
+	doc.Add(http.MethodGet, "/items", &openapi.Operation{OperationID: "listItems", Summary: "List items"})
+	handler := openapi.Handler(doc)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	var got map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || got["openapi"] != openapi.Version {
+		t.Fatalf("Handler() body = %s, want OpenAPI %s document", w.Body.String(), openapi.Version)
+	}
+	if w.Header().Get("Content-Type") != openapi.ContentType {
+		t.Errorf("Handler() Content-Type = %q, want %q", w.Header().Get("Content-Type"), openapi.ContentType)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	req.Header.Set("If-None-Match", w.Header().Get("ETag"))
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("Handler() with If-None-Match status = %d, want %d", w.Code, http.StatusNotModified)
+	}
+}
+
+func TestDocsHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+	openapi.DocsHandler("Test API", "/openapi.json").ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+
+	body := w.Body.String()
+	if w.Code != http.StatusOK || !strings.Contains(body, "<title>Test API</title>") || !strings.Contains(body, `fetch("/openapi.json")`) {
+		t.Errorf("DocsHandler() = %d %s, want page loading the spec", w.Code, body)
+	}
+}
@@ -0,0 +1,111 @@
+package http_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/Korjick/go-http-quote/domain/apperror"
+	utils "github.com/Korjick/go-http-quote/presentation/http"
+)
+
+func TestStatusForKind(t *testing.T) {
+	tests := map[apperror.Kind]int{
+		apperror.KindMalformed:       http.StatusBadRequest,
+		apperror.KindInvalid:         http.StatusUnprocessableEntity,
+		apperror.KindNotFound:        http.StatusNotFound,
+		apperror.KindConflict:        http.StatusConflict,
+		apperror.KindUnsupported:     http.StatusUnsupportedMediaType,
+		apperror.KindNotAcceptable:   http.StatusNotAcceptable,
+		apperror.KindTooLarge:        http.StatusRequestEntityTooLarge,
+		apperror.KindUnauthenticated: http.StatusUnauthorized,
+		apperror.KindForbidden:       http.StatusForbidden,
+		apperror.KindTooManyRequests: http.StatusTooManyRequests,
+		apperror.KindTimeout:         http.StatusServiceUnavailable,
+		apperror.KindCanceled:        utils.StatusClientClosedRequest,
+		apperror.KindUnavailable:     http.StatusServiceUnavailable,
+		apperror.KindInternal:        http.StatusInternalServerError,
+	}
+
+	for kind, want := range tests {
+		if got := utils.StatusForKind(kind); got != want {
+			t.Errorf("StatusForKind(%v) = %v, want %v", kind, got, want)
+		}
+	}
+}
+
+func TestWriteError_TypedError(t *testing.T) {
+	errConflict := apperror.New(apperror.KindConflict, "already_exists", "already exists")
+	req := httptest.NewRequest(http.MethodPost, "/quotes?x=1", nil)
+	w := httptest.NewRecorder()
+
+	utils.WriteError(w, req, fmt.Errorf("%w: quote 7", errConflict))
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("WriteError() status = %v, want %v", w.Code, http.StatusConflict)
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != utils.ProblemContentType {
+		t.Errorf("WriteError() Content-Type = %v, want %v", contentType, utils.ProblemContentType)
+	}
+
+	var problem utils.Problem
+	if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+		t.Fatalf("Failed to decode problem: %v", err)
+	}
+
+	want := utils.Problem{
+		Type:     "/problems/already_exists",
+		Title:    "Conflict",
+		Status:   http.StatusConflict,
+		Detail:   "already exists: quote 7",
+		Instance: "/quotes?x=1",
+		Code:     "already_exists",
+	}
+	if !reflect.DeepEqual(problem, want) {
+		t.Errorf("WriteError() problem = %+v, want %+v", problem, want)
+	}
+}
+
+func TestWriteError_UnknownErrorIsHidden(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/quotes", nil)
+	w := httptest.NewRecorder()
+
+	utils.WriteError(w, req, errors.New("database password is hunter2"))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("WriteError() status = %v, want %v", w.Code, http.StatusInternalServerError)
+	}
+
+	var problem utils.Problem
+	if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+		t.Fatalf("Failed to decode problem: %v", err)
+	}
+	if problem.Code != "internal_error" || problem.Detail != "internal server error" {
+		t.Errorf("WriteError() problem = %+v, want generic internal error", problem)
+	}
+}
+
+func TestWriteError_Localized(t *testing.T) {
+	errInvalid := apperror.New(apperror.KindInvalid, "empty_author", "author cannot be empty")
+	req := httptest.NewRequest(http.MethodPost, "/quotes", nil)
+	req.Header.Set("Accept-Language", "ru-RU,ru;q=0.9,en;q=0.8")
+	w := httptest.NewRecorder()
+
+	utils.WriteError(w, req, errInvalid)
+
+	if lang := w.Header().Get("Content-Language"); lang != "ru" {
+		t.Errorf("WriteError() Content-Language = %q, want ru", lang)
+	}
+
+	var problem utils.Problem
+	if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+		t.Fatalf("Failed to decode problem: %v", err)
+	}
+	if problem.Code != "empty_author" || problem.Detail != "автор не может быть пустым" || problem.Title != "Ошибка валидации" {
+		t.Errorf("WriteError() problem = %+v, want Russian validation error", problem)
+	}
+}
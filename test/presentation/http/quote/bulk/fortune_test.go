@@ -0,0 +1,226 @@
+package bulk_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Korjick/go-http-quote/domain/quote/entity"
+	"github.com/Korjick/go-http-quote/domain/quote/repository"
+	"github.com/Korjick/go-http-quote/presentation/http/quote/bulk"
+)
+
+func TestFortuneSource(t *testing.T) {
+	body := "Imagination is more important\nthan knowledge.\n\t\t-- Albert Einstein\n%\nStay hungry, stay foolish.\n    -- Steve Jobs\n%\n%\nA fortune without an author.\n%\n"
+	src, err := bulk.NewSource("application/x-fortune", strings.NewReader(body), v1)
+	if err != nil {
+		t.Fatalf("NewSource() error = %v", err)
+	}
+
+	drafts, errs := readAll(t, src)
+	if len(errs) != 0 {
+		t.Fatalf("Fortune source returned errors %v, want none", errs)
+	}
+	if len(drafts) != 3 {
+		t.Fatalf("Fortune source returned %d drafts, want 3", len(drafts))
+	}
+
+	if drafts[0].Author != "Albert Einstein" || drafts[0].Text != "Imagination is more important\nthan knowledge." {
+		t.Errorf("First draft = %+v, want multi-line Einstein quote", drafts[0])
+	}
+	if drafts[1].Author != "Steve Jobs" {
+		t.Errorf("Second draft author = %q, want Steve Jobs", drafts[1].Author)
+	}
+	if drafts[2].Author != "" || drafts[2].Text != "A fortune without an author." {
+		t.Errorf("Third draft = %+v, want text without author", drafts[2])
+	}
+}
+
+func TestBuildFortuneIndex(t *testing.T) {
+	text := "one\n%\nthree\n%\n%\nfive5\n"
+
+	index, err := bulk.BuildFortuneIndex(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("BuildFortuneIndex() error = %v", err)
+	}
+
+	wantOffsets := []uint32{0, 6, 14, 22}
+	if len(index.Offsets) != len(wantOffsets) {
+		t.Fatalf("BuildFortuneIndex() offsets = %v, want %v", index.Offsets, wantOffsets)
+	}
+	for i := range wantOffsets {
+		if index.Offsets[i] != wantOffsets[i] {
+			t.Errorf("BuildFortuneIndex() offsets = %v, want %v", index.Offsets, wantOffsets)
+			break
+		}
+	}
+
+	if index.NumStr() != 3 || index.LongLen != 6 || index.ShortLen != 4 {
+		t.Errorf("BuildFortuneIndex() numstr = %d, longlen = %d, shortlen = %d, want 3, 6, 4",
+			index.NumStr(), index.LongLen, index.ShortLen)
+	}
+}
+
+func TestFortuneIndex_WriteTo(t *testing.T) {
+	index, _ := bulk.BuildFortuneIndex(strings.NewReader("one\n%\ntwo\n%\n"))
+
+	var buf bytes.Buffer
+	n, err := index.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	if n != int64(buf.Len()) || n != 24+3*4 {
+		t.Errorf("WriteTo() wrote %d bytes (reported %d), want %d", buf.Len(), n, 24+3*4)
+	}
+
+	data := buf.Bytes()
+	if version := binary.BigEndian.Uint32(data[0:4]); version != 2 {
+		t.Errorf("Index version = %d, want 2", version)
+	}
+	if numstr := binary.BigEndian.Uint32(data[4:8]); numstr != 2 {
+		t.Errorf("Index numstr = %d, want 2", numstr)
+	}
+	if data[20] != '%' {
+		t.Errorf("Index delimiter = %q, want '%%'", data[20])
+	}
+	if last := binary.BigEndian.Uint32(data[32:36]); last != 12 {
+		t.Errorf("Index last offset = %d, want 12", last)
+	}
+}
+
+func TestFortuneWriter_RoundTrip(t *testing.T) {
+	quotes := makeQuotes(t, 3)
+	data := writeQuotes(t, bulk.FormatFortune, quotes)
+
+	src, err := bulk.NewSource("text/x-fortune", bytes.NewReader(data), v1)
+	if err != nil {
+		t.Fatalf("NewSource() error = %v", err)
+	}
+
+	drafts, errs := readAll(t, src)
+	if len(drafts) != 3 || len(errs) != 0 {
+		t.Fatalf("Re-importing fortune export returned %d drafts and %d errors, want 3 and 0", len(drafts), len(errs))
+	}
+	if drafts[0].Author != quotes[0].Author || drafts[0].Text != quotes[0].Text {
+		t.Errorf("Round-tripped draft = %+v, want %s/%s", drafts[0], quotes[0].Author, quotes[0].Text)
+	}
+}
+
+func TestFortuneWriter_RoundTripDelimiterLines(t *testing.T) {
+	texts := []string{
+		"Progress:\n%\nof the work",
+		"Escaped:\n\\%\nstays escaped",
+		"100%",
+	}
+	quotes := make([]*entity.Quote, len(texts))
+	for i, text := range texts {
+		quote, err := entity.NewQuote(entity.QuoteID(i+1), "Author", text)
+		if err != nil {
+			t.Fatalf("NewQuote() error = %v", err)
+		}
+		quotes[i] = quote
+	}
+	data := writeQuotes(t, bulk.FormatFortune, quotes)
+
+	src, err := bulk.NewSource("text/x-fortune", bytes.NewReader(data), v1)
+	if err != nil {
+		t.Fatalf("NewSource() error = %v", err)
+	}
+	drafts, errs := readAll(t, src)
+	if len(drafts) != len(texts) || len(errs) != 0 {
+		t.Fatalf("Re-importing fortune export returned %d drafts and %d errors, want %d and 0:\n%s", len(drafts), len(errs), len(texts), data)
+	}
+	for i, draft := range drafts {
+		if draft.Author != "Author" || draft.Text != texts[i] {
+			t.Errorf("Round-tripped draft = %+v, want Author/%q", draft, texts[i])
+		}
+	}
+
+	index, err := bulk.BuildFortuneIndex(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("BuildFortuneIndex() error = %v", err)
+	}
+	if index.NumStr() != len(texts) {
+		t.Errorf("Index entries = %d, want %d", index.NumStr(), len(texts))
+	}
+}
+
+func TestFortuneArchiveWriter(t *testing.T) {
+	data := writeQuotes(t, bulk.FormatFortuneArchive, makeQuotes(t, 5))
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to open gzip stream: %v", err)
+	}
+	archive := tar.NewReader(gz)
+
+	files := make(map[string][]byte)
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read tar entry: %v", err)
+		}
+		files[header.Name], _ = io.ReadAll(archive)
+	}
+
+	text, ok := files["quotes"]
+	if !ok {
+		t.Fatal("Archive does not contain quotes")
+	}
+	dat, ok := files["quotes.dat"]
+	if !ok {
+		t.Fatal("Archive does not contain quotes.dat")
+	}
+
+	if numstr := binary.BigEndian.Uint32(dat[4:8]); numstr != 5 {
+		t.Errorf("Index numstr = %d, want 5", numstr)
+	}
+	if last := binary.BigEndian.Uint32(dat[len(dat)-4:]); int(last) != len(text) {
+		t.Errorf("Index last offset = %d, want %d", last, len(text))
+	}
+
+	index, err := bulk.BuildFortuneIndex(bytes.NewReader(text))
+	if err != nil {
+		t.Fatalf("BuildFortuneIndex() error = %v", err)
+	}
+	var want bytes.Buffer
+	if _, err := index.WriteTo(&want); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	if !bytes.Equal(dat, want.Bytes()) {
+		t.Error("Index built while writing differs from the index of the text")
+	}
+}
+
+func TestFortuneArchiveWriter_RemovesTemporaryFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", dir)
+	assertEmpty := func(when string) {
+		t.Helper()
+		if entries, err := os.ReadDir(dir); err != nil || len(entries) != 0 {
+			t.Errorf("Temporary directory %s holds %d files, %v, want none", when, len(entries), err)
+		}
+	}
+
+	writeQuotes(t, bulk.FormatFortuneArchive, makeQuotes(t, 3))
+	assertEmpty("after Close")
+
+	writer, err := bulk.NewWriter(bulk.FormatFortuneArchive, io.Discard, repository.Snapshot{}, v1)
+	if err != nil {
+		t.Fatalf("NewWriter() error = %v", err)
+	}
+	if err := writer.Write(makeQuotes(t, 1)[0]); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	bulk.Abort(writer)
+	assertEmpty("after Abort")
+}
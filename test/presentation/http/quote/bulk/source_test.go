@@ -0,0 +1,134 @@
+package bulk_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Korjick/go-http-quote/application/service"
+	"github.com/Korjick/go-http-quote/domain/quote/entity"
+	"github.com/Korjick/go-http-quote/presentation/http/quote/bulk"
+)
+
+func readAll(t *testing.T, src service.QuoteSource) ([]entity.QuoteDraft, []error) {
+	t.Helper()
+
+	var drafts []entity.QuoteDraft
+	var errs []error
+	for i := 0; i < 100; i++ {
+		draft, err := src.Next()
+		if errors.Is(err, io.EOF) {
+			return drafts, errs
+		}
+		if err != nil {
+			errs = append(errs, err)
+			var rowErr *service.RowError
+			if !errors.As(err, &rowErr) {
+				return drafts, errs
+			}
+			continue
+		}
+		drafts = append(drafts, draft)
+	}
+	t.Fatal("source did not terminate")
+	return nil, nil
+}
+
+func TestNewSource_UnsupportedFormat(t *testing.T) {
+	_, err := bulk.NewSource("application/xml", strings.NewReader(""), v1)
+	if !errors.Is(err, bulk.ErrUnsupportedFormat) {
+		t.Errorf("NewSource() error = %v, want %v", err, bulk.ErrUnsupportedFormat)
+	}
+}
+
+func TestJSONLinesSource(t *testing.T) {
+	body := `{"author": "Einstein", "quote": "Quote 1"}
+
+{"author": "Jobs", "quote": "Quote 2"}
+not json
+{"author": "Plato", "quote": "Quote 3"}
+`
+	src, err := bulk.NewSource("application/x-ndjson", strings.NewReader(body), v1)
+	if err != nil {
+		t.Fatalf("NewSource() error = %v", err)
+	}
+
+	drafts, errs := readAll(t, src)
+	if len(drafts) != 3 {
+		t.Errorf("JSONL source returned %d drafts, want 3", len(drafts))
+	}
+	if len(errs) != 1 {
+		t.Fatalf("JSONL source returned %d errors, want 1", len(errs))
+	}
+
+	var rowErr *service.RowError
+	if !errors.As(errs[0], &rowErr) || rowErr.Row != 3 {
+		t.Errorf("JSONL source error = %v, want row error for row 3", errs[0])
+	}
+}
+
+func TestJSONArraySource(t *testing.T) {
+	body := `[{"author": "Einstein", "quote": "Quote 1"}, {"author": 42}, {"author": "Jobs", "quote": "Quote 2"}]`
+	src, err := bulk.NewSource("application/json; charset=utf-8", strings.NewReader(body), v1)
+	if err != nil {
+		t.Fatalf("NewSource() error = %v", err)
+	}
+
+	drafts, errs := readAll(t, src)
+	if len(drafts) != 2 {
+		t.Errorf("JSON source returned %d drafts, want 2", len(drafts))
+	}
+	if len(errs) != 1 {
+		t.Fatalf("JSON source returned %d errors, want 1", len(errs))
+	}
+
+	if drafts[1].Author != "Jobs" || drafts[1].Text != "Quote 2" {
+		t.Errorf("JSON source second draft = %+v, want Jobs/Quote 2", drafts[1])
+	}
+}
+
+func TestJSONArraySource_NotAnArray(t *testing.T) {
+	src, err := bulk.NewSource("application/json", strings.NewReader(`{"author": "Einstein"}`), v1)
+	if err != nil {
+		t.Fatalf("NewSource() error = %v", err)
+	}
+
+	_, err = src.Next()
+	var rowErr *service.RowError
+	if err == nil || errors.As(err, &rowErr) {
+		t.Errorf("Next() error = %v, want fatal error", err)
+	}
+}
+
+func TestCSVSource(t *testing.T) {
+	body := "quote,author\n\"Quote, with comma\",Einstein\nonly one field\nQuote 2,Jobs\n"
+	src, err := bulk.NewSource("text/csv", strings.NewReader(body), v1)
+	if err != nil {
+		t.Fatalf("NewSource() error = %v", err)
+	}
+
+	drafts, errs := readAll(t, src)
+	if len(drafts) != 2 {
+		t.Errorf("CSV source returned %d drafts, want 2", len(drafts))
+	}
+	if len(errs) != 1 {
+		t.Fatalf("CSV source returned %d errors, want 1", len(errs))
+	}
+
+	if drafts[0].Author != "Einstein" || drafts[0].Text != "Quote, with comma" {
+		t.Errorf("CSV source first draft = %+v, want Einstein/Quote, with comma", drafts[0])
+	}
+}
+
+func TestCSVSource_MissingColumn(t *testing.T) {
+	src, err := bulk.NewSource("text/csv", strings.NewReader("author\nEinstein\n"), v1)
+	if err != nil {
+		t.Fatalf("NewSource() error = %v", err)
+	}
+
+	_, err = src.Next()
+	if !errors.Is(err, bulk.ErrMissingColumn) {
+		t.Errorf("Next() error = %v, want %v", err, bulk.ErrMissingColumn)
+	}
+}
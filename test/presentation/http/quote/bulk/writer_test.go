@@ -0,0 +1,206 @@
+package bulk_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Korjick/go-http-quote/domain/quote/entity"
+	"github.com/Korjick/go-http-quote/domain/quote/repository"
+	"github.com/Korjick/go-http-quote/presentation/http/quote/bulk"
+	"github.com/Korjick/go-http-quote/presentation/http/quote/dto"
+)
+
+// v1 is the mapper of the default API version, which most tests use.
+var v1 = dto.MapperFor(dto.V1)
+
+func writeQuotes(t *testing.T, format bulk.Format, quotes []*entity.Quote) []byte {
+	t.Helper()
+	return writeVersion(t, format, quotes, v1)
+}
+
+func writeVersion(t *testing.T, format bulk.Format, quotes []*entity.Quote, mapper dto.Mapper) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	snapshot := repository.Snapshot{Revision: 7, MaxID: entity.QuoteID(len(quotes)), TakenAt: time.Now()}
+	writer, err := bulk.NewWriter(format, &buf, snapshot, mapper)
+	if err != nil {
+		t.Fatalf("NewWriter() error = %v", err)
+	}
+
+	for _, quote := range quotes {
+		if err := writer.Write(quote); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func makeQuotes(t *testing.T, n int) []*entity.Quote {
+	t.Helper()
+
+	quotes := make([]*entity.Quote, n)
+	for i := range quotes {
+		quote, err := entity.NewQuote(entity.QuoteID(i+1), "Author", "Quote, \"quoted\"")
+		if err != nil {
+			t.Fatalf("NewQuote() error = %v", err)
+		}
+		quotes[i] = quote
+	}
+	return quotes
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := bulk.ParseFormat("")
+	if err != nil || format != bulk.FormatJSONLines {
+		t.Errorf("ParseFormat(\"\") = %v, %v, want %v", format, err, bulk.FormatJSONLines)
+	}
+
+	_, err = bulk.ParseFormat("xml")
+	if !errors.Is(err, bulk.ErrUnsupportedExportFormat) {
+		t.Errorf("ParseFormat(\"xml\") error = %v, want %v", err, bulk.ErrUnsupportedExportFormat)
+	}
+}
+
+func TestJSONLinesWriter(t *testing.T) {
+	data := writeQuotes(t, bulk.FormatJSONLines, makeQuotes(t, 3))
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("JSONL writer produced %d lines, want 3", len(lines))
+	}
+
+	var response dto.QuoteResponse
+	if err := json.Unmarshal([]byte(lines[2]), &response); err != nil {
+		t.Fatalf("Failed to decode line: %v", err)
+	}
+	if response.ID != 3 {
+		t.Errorf("Third line ID = %v, want 3", response.ID)
+	}
+}
+
+func TestCSVWriter(t *testing.T) {
+	data := writeQuotes(t, bulk.FormatCSV, makeQuotes(t, 2))
+
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("CSV writer produced %d records, want 3", len(records))
+	}
+	if records[0][0] != "id" || records[1][2] != "Quote, \"quoted\"" {
+		t.Errorf("CSV writer records = %v", records)
+	}
+
+	src, err := bulk.NewSource("text/csv", bytes.NewReader(data), v1)
+	if err != nil {
+		t.Fatalf("NewSource() error = %v", err)
+	}
+	drafts, errs := readAll(t, src)
+	if len(drafts) != 2 || len(errs) != 0 {
+		t.Errorf("Re-importing CSV export returned %d drafts and %d errors, want 2 and 0", len(drafts), len(errs))
+	}
+}
+
+func TestCSVWriter_Empty(t *testing.T) {
+	data := writeQuotes(t, bulk.FormatCSV, nil)
+
+	if strings.TrimSpace(string(data)) != "id,author,quote,created_at" {
+		t.Errorf("Empty CSV export = %q, want header only", data)
+	}
+}
+
+func TestArchiveWriter(t *testing.T) {
+	data := writeQuotes(t, bulk.FormatTarGz, makeQuotes(t, 2500))
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to open gzip stream: %v", err)
+	}
+	archive := tar.NewReader(gz)
+
+	var names []string
+	lines := 0
+	var manifest bulk.ArchiveManifest
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read tar entry: %v", err)
+		}
+		names = append(names, header.Name)
+
+		content, _ := io.ReadAll(archive)
+		if header.Name == "manifest.json" {
+			if err := json.Unmarshal(content, &manifest); err != nil {
+				t.Fatalf("Failed to decode manifest: %v", err)
+			}
+			continue
+		}
+		lines += strings.Count(string(content), "\n")
+	}
+
+	if len(names) != 4 || names[3] != "manifest.json" {
+		t.Errorf("Archive entries = %v, want 3 chunks followed by manifest.json", names)
+	}
+	if lines != 2500 {
+		t.Errorf("Archive chunks contain %d quotes, want 2500", lines)
+	}
+	if manifest.QuoteCount != 2500 || len(manifest.Files) != 3 || manifest.Snapshot.Revision != 7 {
+		t.Errorf("Manifest = %+v, want 2500 quotes in 3 files at revision 7", manifest)
+	}
+}
+
+func TestWriters_V2RoundTrip(t *testing.T) {
+	v2 := dto.MapperFor(dto.V2)
+	quotes := makeQuotes(t, 2)
+	tests := []struct {
+		format      bulk.Format
+		contentType string
+		field       string
+	}{
+		{bulk.FormatJSONLines, "application/x-ndjson", `"text":`},
+		{bulk.FormatCSV, "text/csv", "id,author,text,created_at"},
+	}
+	for _, tt := range tests {
+		data := writeVersion(t, tt.format, quotes, v2)
+		if !strings.Contains(string(data), tt.field) || strings.Contains(string(data), `"quote":`) {
+			t.Errorf("%s export of v2 = %s, want %s", tt.format, data, tt.field)
+		}
+
+		src, err := bulk.NewSource(tt.contentType, bytes.NewReader(data), v2)
+		if err != nil {
+			t.Fatalf("NewSource() error = %v", err)
+		}
+		drafts, errs := readAll(t, src)
+		if len(drafts) != len(quotes) || len(errs) != 0 {
+			t.Fatalf("%s: re-importing v2 export returned %d drafts and %v, want %d", tt.format, len(drafts), errs, len(quotes))
+		}
+		if drafts[0].Author != quotes[0].Author || drafts[0].Text != quotes[0].Text {
+			t.Errorf("%s: round-tripped draft = %+v, want %s/%s", tt.format, drafts[0], quotes[0].Author, quotes[0].Text)
+		}
+
+		// The v1 import does not take the v2 field.
+		src, err = bulk.NewSource(tt.contentType, bytes.NewReader(data), v1)
+		if err != nil {
+			t.Fatalf("NewSource() error = %v", err)
+		}
+		if drafts, errs := readAll(t, src); len(errs) == 0 && len(drafts) > 0 && drafts[0].Text != "" {
+			t.Errorf("%s: v1 import of a v2 export = %+v, want no quote text", tt.format, drafts[0])
+		}
+	}
+}
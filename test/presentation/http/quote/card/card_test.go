@@ -0,0 +1,223 @@
+package card_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Korjick/go-http-quote/application/service"
+	"github.com/Korjick/go-http-quote/domain/auth"
+	"github.com/Korjick/go-http-quote/infrastructure/repository/in_memory"
+	"github.com/Korjick/go-http-quote/presentation/http/quote"
+	"github.com/Korjick/go-http-quote/presentation/http/quote/card"
+)
+
+func setup(t *testing.T) *quote.Controller {
+	t.Helper()
+	svc := service.NewQuoteService(in_memory.NewInMemoryQuoteRepository())
+	if _, err := svc.CreateQuote(context.Background(), auth.System, "Лев Толстой", "Все счастливые семьи похожи друг на друга, каждая несчастливая семья несчастлива по-своему."); err != nil {
+		t.Fatalf("CreateQuote() error = %v", err)
+	}
+	if _, err := svc.CreateQuote(context.Background(), auth.System, "Tom & Jerry", "<cheese> is \"great\""); err != nil {
+		t.Fatalf("CreateQuote() error = %v", err)
+	}
+	return quote.NewQuoteController(svc, "/quotes")
+}
+
+func fetch(controller http.Handler, target string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	w := httptest.NewRecorder()
+	controller.ServeHTTP(w, req)
+	return w
+}
+
+func TestCards_PNG(t *testing.T) {
+	controller := setup(t)
+
+	for _, size := range card.Sizes {
+		w := fetch(controller, "/quotes/1/card.png?theme=dark&size="+size.Name, nil)
+		if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/png" {
+			t.Fatalf("card.png?size=%s status = %d, content type %q, want 200 image/png", size.Name, w.Code, w.Header().Get("Content-Type"))
+		}
+
+		img, err := png.Decode(w.Body)
+		if err != nil {
+			t.Fatalf("png.Decode() error = %v", err)
+		}
+		if got := img.Bounds().Size(); got != image.Pt(size.Width, size.Height) {
+			t.Errorf("card.png?size=%s size = %v, want %dx%d", size.Name, got, size.Width, size.Height)
+		}
+		if inked := countInked(img, card.Themes[1]); inked < 1000 {
+			t.Errorf("card.png?size=%s has %d text pixels, want Cyrillic glyphs drawn", size.Name, inked)
+		}
+	}
+}
+
+func countInked(img image.Image, theme card.Theme) int {
+	bg := theme.Background
+	inked := 0
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			if uint8(r>>8) != bg.R || uint8(g>>8) != bg.G || uint8(b>>8) != bg.B {
+				inked++
+			}
+		}
+	}
+	return inked
+}
+
+func TestCards_SVG(t *testing.T) {
+	controller := setup(t)
+
+	w := fetch(controller, "/quotes/2/card.svg?theme=sepia", nil)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/svg+xml" {
+		t.Fatalf("card.svg status = %d, content type %q, want 200 image/svg+xml", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	body := w.Body.String()
+	for _, want := range []string{`<tspan`, `&lt;cheese&gt; is &#34;great&#34;`, `— Tom &amp; Jerry`, `fill="#f4ecd8"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("card.svg body missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestCards_WrapsLongQuotes(t *testing.T) {
+	controller := setup(t)
+
+	body := fetch(controller, "/quotes/1/card.svg?size=story", nil).Body.String()
+	if lines := strings.Count(body, "<tspan"); lines < 2 {
+		t.Errorf("card.svg has %d lines, want the quote wrapped", lines)
+	}
+}
+
+func TestCards_SVGEmbedsFontSubset(t *testing.T) {
+	controller := setup(t)
+
+	body := fetch(controller, "/quotes/1/card.svg", nil).Body.String()
+	match := regexp.MustCompile(`@font-face \{ font-family: 'DejaVu Serif'; src: url\(data:font/ttf;base64,([A-Za-z0-9+/=]+)\)`).FindStringSubmatch(body)
+	if match == nil {
+		t.Fatalf("card.svg has no embedded font:\n%.500s", body)
+	}
+	font, err := base64.StdEncoding.DecodeString(match[1])
+	if err != nil {
+		t.Fatalf("Embedded font is not base64: %v", err)
+	}
+	if !bytes.HasPrefix(font, []byte{0, 1, 0, 0}) {
+		t.Errorf("Embedded font starts with % x, want a TrueType header", font[:4])
+	}
+	if len(font) > 100<<10 {
+		t.Errorf("Embedded font is %d bytes, want a subset of the used glyphs", len(font))
+	}
+}
+
+func TestCards_CapsLongQuotes(t *testing.T) {
+	svc := service.NewQuoteService(in_memory.NewInMemoryQuoteRepository())
+	text := strings.Repeat("слово ", 100000)
+	if _, err := svc.CreateQuote(context.Background(), auth.System, strings.Repeat("Автор ", 1000), text); err != nil {
+		t.Fatalf("CreateQuote() error = %v", err)
+	}
+	controller := quote.NewQuoteController(svc, "/quotes")
+
+	for _, size := range card.Sizes {
+		w := fetch(controller, "/quotes/1/card.svg?size="+size.Name, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("card.svg?size=%s status = %d, want 200", size.Name, w.Code)
+		}
+		if body := w.Body.String(); strings.Count(body, "слово") > 1000 || strings.Count(body, "…") != 2 {
+			t.Errorf("card.svg?size=%s does not cut the quote and author short", size.Name)
+		}
+	}
+}
+
+func TestCards_InvalidOptions(t *testing.T) {
+	controller := setup(t)
+
+	tests := []struct {
+		target string
+		status int
+		code   string
+	}{
+		{"/quotes/1/card.png?theme=neon", http.StatusBadRequest, "unknown_card_theme"},
+		{"/quotes/1/card.svg?size=huge", http.StatusBadRequest, "unknown_card_size"},
+		{"/quotes/abc/card.png", http.StatusBadRequest, "invalid_quote_id"},
+		{"/quotes/99/card.png", http.StatusNotFound, "quote_not_found"},
+	}
+	for _, tt := range tests {
+		w := fetch(controller, tt.target, nil)
+		if w.Code != tt.status || !strings.Contains(w.Body.String(), tt.code) {
+			t.Errorf("GET %s = %d %s, want %d %s", tt.target, w.Code, w.Body.String(), tt.status, tt.code)
+		}
+	}
+}
+
+func TestCards_ConditionalRequest(t *testing.T) {
+	controller := setup(t)
+
+	first := fetch(controller, "/quotes/1/card.png", nil)
+	etag := first.Header().Get("ETag")
+	if etag == "" || first.Header().Get("Cache-Control") == "" {
+		t.Fatalf("card.png headers = %v, want ETag and Cache-Control", first.Header())
+	}
+
+	second := fetch(controller, "/quotes/1/card.png", nil)
+	if !bytes.Equal(first.Body.Bytes(), second.Body.Bytes()) || second.Header().Get("ETag") != etag {
+		t.Errorf("repeated card.png differs, want the cached card")
+	}
+
+	w := fetch(controller, "/quotes/1/card.png", http.Header{"If-None-Match": {etag}})
+	if w.Code != http.StatusNotModified {
+		t.Errorf("card.png with If-None-Match status = %d, want %d", w.Code, http.StatusNotModified)
+	}
+
+	if other := fetch(controller, "/quotes/1/card.png?theme=dark", nil).Header().Get("ETag"); other == etag {
+		t.Errorf("card.png?theme=dark ETag = %q, want it to differ from the default theme", other)
+	}
+}
+
+func TestCards_ConcurrentRendersAcrossVersions(t *testing.T) {
+	svc := service.NewQuoteService(in_memory.NewInMemoryQuoteRepository())
+	if _, err := svc.CreateQuote(context.Background(), auth.System, "Author", "Shared card"); err != nil {
+		t.Fatalf("CreateQuote() error = %v", err)
+	}
+	controller := quote.NewVersionedController(svc, "/quotes")
+
+	targets := []string{"/quotes/1/card.png", "/v1/quotes/1/card.png", "/v2/quotes/1/card.png"}
+	results := make(chan *httptest.ResponseRecorder, 4*runtime.GOMAXPROCS(0)*len(targets))
+	var wg sync.WaitGroup
+	for i := range cap(results) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results <- fetch(controller, targets[i%len(targets)], nil)
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	var body []byte
+	for w := range results {
+		if w.Code != http.StatusOK {
+			t.Fatalf("card.png status = %d, want 200", w.Code)
+		}
+		if body == nil {
+			body = w.Body.Bytes()
+		} else if !bytes.Equal(w.Body.Bytes(), body) {
+			t.Error("Versions serve different cards of the same quote")
+		}
+	}
+}
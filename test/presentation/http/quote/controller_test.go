@@ -2,23 +2,36 @@ package quote_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/Korjick/go-http-quote/application/service"
+	"github.com/Korjick/go-http-quote/domain/auth"
 	"github.com/Korjick/go-http-quote/infrastructure/repository/in_memory"
+	utils "github.com/Korjick/go-http-quote/presentation/http"
 	"github.com/Korjick/go-http-quote/presentation/http/quote"
 	"github.com/Korjick/go-http-quote/presentation/http/quote/dto"
 )
 
-func setupTestController() *quote.Controller {
+func setupTestController() http.Handler {
 	repo := in_memory.NewInMemoryQuoteRepository()
 	svc := service.NewQuoteService(repo)
-	return quote.NewQuoteController(svc, "/quotes")
+	return asSystem(quote.NewQuoteController(svc, "/quotes"))
+}
+
+// asSystem serves requests on behalf of auth.System, as if they had passed
+// authentication with an admin key.
+func asSystem(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(w, utils.WithPrincipal(r, auth.System))
+	})
 }
 
 func TestController_CreateQuoteValidInput(t *testing.T) {
@@ -91,21 +104,21 @@ func TestController_CreateQuoteEmptyAuthor(t *testing.T) {
 	w := httptest.NewRecorder()
 	controller.ServeHTTP(w, req)
 
-	if w.Code != http.StatusBadRequest {
-		t.Errorf("CreateQuote() status = %v, want %v", w.Code, http.StatusBadRequest)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("CreateQuote() status = %v, want %v", w.Code, http.StatusUnprocessableEntity)
 	}
 
 	contentType := w.Header().Get("Content-Type")
-	if !strings.Contains(contentType, "application/json") {
-		t.Errorf("CreateQuote() Content-Type = %v, want application/json", contentType)
+	if !strings.Contains(contentType, utils.ProblemContentType) {
+		t.Errorf("CreateQuote() Content-Type = %v, want application/problem+json", contentType)
 	}
 
-	var errorResp dto.ErrorResponse
+	var errorResp utils.Problem
 	err = json.NewDecoder(w.Body).Decode(&errorResp)
 	if err != nil {
 		t.Errorf("Failed to decode error response: %v", err)
 	}
-	if errorResp.Error == "" {
+	if errorResp.Detail == "" {
 		t.Error("Expected error message but got empty string")
 	}
 }
@@ -129,21 +142,21 @@ func TestController_CreateQuoteEmptyText(t *testing.T) {
 	w := httptest.NewRecorder()
 	controller.ServeHTTP(w, req)
 
-	if w.Code != http.StatusBadRequest {
-		t.Errorf("CreateQuote() status = %v, want %v", w.Code, http.StatusBadRequest)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("CreateQuote() status = %v, want %v", w.Code, http.StatusUnprocessableEntity)
 	}
 
 	contentType := w.Header().Get("Content-Type")
-	if !strings.Contains(contentType, "application/json") {
-		t.Errorf("CreateQuote() Content-Type = %v, want application/json", contentType)
+	if !strings.Contains(contentType, utils.ProblemContentType) {
+		t.Errorf("CreateQuote() Content-Type = %v, want application/problem+json", contentType)
 	}
 
-	var errorResp dto.ErrorResponse
+	var errorResp utils.Problem
 	err = json.NewDecoder(w.Body).Decode(&errorResp)
 	if err != nil {
 		t.Errorf("Failed to decode error response: %v", err)
 	}
-	if errorResp.Error == "" {
+	if errorResp.Detail == "" {
 		t.Error("Expected error message but got empty string")
 	}
 }
@@ -160,6 +173,7 @@ func TestController_GetAllQuotes(t *testing.T) {
 	for _, q := range quotes {
 		jsonBody, _ := json.Marshal(q)
 		req := httptest.NewRequest(http.MethodPost, "/quotes", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
 		w := httptest.NewRecorder()
 		controller.ServeHTTP(w, req)
 	}
@@ -195,6 +209,7 @@ func TestController_GetQuotesByAuthorEinstein(t *testing.T) {
 	for _, q := range quotes {
 		jsonBody, _ := json.Marshal(q)
 		req := httptest.NewRequest(http.MethodPost, "/quotes", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
 		w := httptest.NewRecorder()
 		controller.ServeHTTP(w, req)
 	}
@@ -230,6 +245,7 @@ func TestController_GetQuotesByAuthorJobs(t *testing.T) {
 	for _, q := range quotes {
 		jsonBody, _ := json.Marshal(q)
 		req := httptest.NewRequest(http.MethodPost, "/quotes", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
 		w := httptest.NewRecorder()
 		controller.ServeHTTP(w, req)
 	}
@@ -265,6 +281,7 @@ func TestController_GetQuotesByNonExistentAuthor(t *testing.T) {
 	for _, q := range quotes {
 		jsonBody, _ := json.Marshal(q)
 		req := httptest.NewRequest(http.MethodPost, "/quotes", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
 		w := httptest.NewRecorder()
 		controller.ServeHTTP(w, req)
 	}
@@ -295,8 +312,8 @@ func TestController_GetRandomQuote(t *testing.T) {
 	w := httptest.NewRecorder()
 	controller.ServeHTTP(w, req)
 
-	if w.Code != http.StatusBadRequest {
-		t.Errorf("GetRandomQuote() on empty repo status = %v, want %v", w.Code, http.StatusBadRequest)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("GetRandomQuote() on empty repo status = %v, want %v", w.Code, http.StatusNotFound)
 	}
 
 	createReq := dto.CreateQuoteRequest{
@@ -305,6 +322,7 @@ func TestController_GetRandomQuote(t *testing.T) {
 	}
 	jsonBody, _ := json.Marshal(createReq)
 	req = httptest.NewRequest(http.MethodPost, "/quotes", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
 	w = httptest.NewRecorder()
 	controller.ServeHTTP(w, req)
 
@@ -338,8 +356,8 @@ func TestController_DeleteQuote(t *testing.T) {
 	w := httptest.NewRecorder()
 	controller.ServeHTTP(w, req)
 
-	if w.Code != http.StatusBadRequest {
-		t.Errorf("DeleteQuote() non-existent status = %v, want %v", w.Code, http.StatusBadRequest)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("DeleteQuote() non-existent status = %v, want %v", w.Code, http.StatusNotFound)
 	}
 
 	createReq := dto.CreateQuoteRequest{
@@ -348,6 +366,7 @@ func TestController_DeleteQuote(t *testing.T) {
 	}
 	jsonBody, _ := json.Marshal(createReq)
 	req = httptest.NewRequest(http.MethodPost, "/quotes", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
 	w = httptest.NewRecorder()
 	controller.ServeHTTP(w, req)
 
@@ -375,6 +394,39 @@ func TestController_DeleteQuote(t *testing.T) {
 	}
 }
 
+func TestController_ChangesRequireRole(t *testing.T) {
+	controller := quote.NewQuoteController(service.NewQuoteService(in_memory.NewInMemoryQuoteRepository()), "/quotes")
+	contributor := auth.Principal{Subject: "api-key:1", Role: auth.RoleContributor}
+	other := auth.Principal{Subject: "api-key:2", Role: auth.RoleContributor}
+
+	send := func(principal auth.Principal, method, target, body string) int {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		controller.ServeHTTP(w, utils.WithPrincipal(req, principal))
+		return w.Code
+	}
+
+	if code := send(auth.Anonymous, http.MethodPost, "/quotes", `{"author": "A", "quote": "Q"}`); code != http.StatusUnauthorized {
+		t.Errorf("Anonymous POST status = %d, want %d", code, http.StatusUnauthorized)
+	}
+	if code := send(contributor, http.MethodPost, "/quotes", `{"author": "A", "quote": "Q"}`); code != http.StatusCreated {
+		t.Errorf("Contributor POST status = %d, want %d", code, http.StatusCreated)
+	}
+	if code := send(auth.Anonymous, http.MethodDelete, "/quotes/1", ""); code != http.StatusUnauthorized {
+		t.Errorf("Anonymous DELETE status = %d, want %d", code, http.StatusUnauthorized)
+	}
+	if code := send(other, http.MethodDelete, "/quotes/1", ""); code != http.StatusForbidden {
+		t.Errorf("DELETE of another contributor's quote status = %d, want %d", code, http.StatusForbidden)
+	}
+	if code := send(auth.Anonymous, http.MethodGet, "/quotes/1", ""); code != http.StatusOK {
+		t.Errorf("Anonymous GET status = %d, want %d", code, http.StatusOK)
+	}
+	if code := send(contributor, http.MethodDelete, "/quotes/1", ""); code != http.StatusNoContent {
+		t.Errorf("DELETE of own quote status = %d, want %d", code, http.StatusNoContent)
+	}
+}
+
 func TestController_PutMethodNotAllowed(t *testing.T) {
 	controller := setupTestController()
 
@@ -382,8 +434,12 @@ func TestController_PutMethodNotAllowed(t *testing.T) {
 	w := httptest.NewRecorder()
 	controller.ServeHTTP(w, req)
 
-	if w.Code != http.StatusInternalServerError {
-		t.Errorf("PUT method status = %v, want %v", w.Code, http.StatusInternalServerError)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("PUT method status = %v, want %v", w.Code, http.StatusMethodNotAllowed)
+	}
+
+	if allow := w.Header().Get("Allow"); allow != "GET, HEAD, POST" {
+		t.Errorf("PUT method Allow = %q, want %q", allow, "GET, HEAD, POST")
 	}
 }
 
@@ -394,8 +450,8 @@ func TestController_PatchMethodNotAllowed(t *testing.T) {
 	w := httptest.NewRecorder()
 	controller.ServeHTTP(w, req)
 
-	if w.Code != http.StatusInternalServerError {
-		t.Errorf("PATCH method status = %v, want %v", w.Code, http.StatusInternalServerError)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("PATCH method status = %v, want %v", w.Code, http.StatusMethodNotAllowed)
 	}
 }
 
@@ -412,17 +468,54 @@ func TestController_InvalidJSON(t *testing.T) {
 		t.Errorf("Invalid JSON status = %v, want %v", w.Code, http.StatusBadRequest)
 	}
 
-	var errorResp dto.ErrorResponse
+	var errorResp utils.Problem
 	err := json.NewDecoder(w.Body).Decode(&errorResp)
 	if err != nil {
 		t.Errorf("Failed to decode error response: %v", err)
 	}
 
-	if errorResp.Error == "" {
+	if errorResp.Detail == "" {
 		t.Error("Expected error message but got empty string")
 	}
 }
 
+func TestController_StrictBody(t *testing.T) {
+	controller := setupTestController()
+
+	req := httptest.NewRequest(http.MethodPost, "/quotes", strings.NewReader(`{"author": "A", "quote": 42, "tags": ["x"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", "ru")
+	w := httptest.NewRecorder()
+	controller.ServeHTTP(w, req)
+
+	var problem utils.Problem
+	if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+		t.Fatalf("Failed to decode problem: %v", err)
+	}
+	want := []utils.ProblemField{
+		{Pointer: "/quote", Code: "invalid_type", Detail: "неверный тип: expected string, got integer"},
+		{Pointer: "/tags", Code: "unknown_field", Detail: "неизвестное поле"},
+	}
+	if w.Code != http.StatusBadRequest || problem.Code != "invalid_body" || !reflect.DeepEqual(problem.Errors, want) {
+		t.Errorf("CreateQuote() = %d %+v, want 400 invalid_body with %+v", w.Code, problem, want)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/quotes", strings.NewReader(`{"author": "A", "quote": "Q"}`))
+	w = httptest.NewRecorder()
+	controller.ServeHTTP(w, req)
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("CreateQuote() without Content-Type status = %d, want %d", w.Code, http.StatusUnsupportedMediaType)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/quotes/batch", strings.NewReader(`{"operations": []}`+strings.Repeat(" ", utils.MaxJSONBodySize)))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	controller.ServeHTTP(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("BatchQuotes() with oversized body status = %d, want %d", w.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
 func TestController_InvalidQuoteID(t *testing.T) {
 	controller := setupTestController()
 
@@ -434,13 +527,507 @@ func TestController_InvalidQuoteID(t *testing.T) {
 		t.Errorf("Invalid quote ID status = %v, want %v", w.Code, http.StatusBadRequest)
 	}
 
-	var errorResp dto.ErrorResponse
+	var errorResp utils.Problem
 	err := json.NewDecoder(w.Body).Decode(&errorResp)
 	if err != nil {
 		t.Errorf("Failed to decode error response: %v", err)
 	}
 
-	if !strings.Contains(errorResp.Error, "Invalid quote ID") {
-		t.Errorf("Expected 'Invalid quote ID' error, got: %s", errorResp.Error)
+	if errorResp.Code != "invalid_quote_id" {
+		t.Errorf("Expected 'invalid_quote_id' error code, got: %s", errorResp.Code)
+	}
+}
+
+func TestController_ImportQuotesJSONLines(t *testing.T) {
+	controller := setupTestController()
+
+	body := "{\"author\": \"Einstein\", \"quote\": \"Quote 1\"}\n{\"author\": \"Jobs\", \"quote\": \"Quote 2\"}\n"
+	req := httptest.NewRequest(http.MethodPost, "/quotes/import", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	w := httptest.NewRecorder()
+	controller.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("ImportQuotes() status = %v, want %v", w.Code, http.StatusOK)
+	}
+
+	var response dto.ImportResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Errorf("Failed to decode response: %v", err)
+	}
+
+	if response.Imported != 2 || response.Failed != 0 {
+		t.Errorf("ImportQuotes() imported = %d, failed = %d, want 2, 0", response.Imported, response.Failed)
+	}
+}
+
+func TestController_ImportQuotesAtomicFailure(t *testing.T) {
+	controller := setupTestController()
+
+	body := "author,quote\nEinstein,Quote 1\n,Quote 2\n"
+	req := httptest.NewRequest(http.MethodPost, "/quotes/import", strings.NewReader(body))
+	req.Header.Set("Content-Type", "text/csv")
+
+	w := httptest.NewRecorder()
+	controller.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("ImportQuotes() status = %v, want %v", w.Code, http.StatusUnprocessableEntity)
+	}
+
+	var response dto.ImportResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Errorf("Failed to decode response: %v", err)
+	}
+
+	if len(response.Errors) != 1 || response.Errors[0].Row != 2 {
+		t.Errorf("ImportQuotes() errors = %+v, want one error for row 2", response.Errors)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/quotes", nil)
+	w = httptest.NewRecorder()
+	controller.ServeHTTP(w, req)
+
+	var quotes []dto.QuoteResponse
+	_ = json.NewDecoder(w.Body).Decode(&quotes)
+	if len(quotes) != 0 {
+		t.Errorf("After failed atomic import, found %d quotes, want 0", len(quotes))
+	}
+}
+
+func TestController_ImportQuotesBestEffort(t *testing.T) {
+	controller := setupTestController()
+
+	body := `[{"author": "Einstein", "quote": "Quote 1"}, {"author": "", "quote": "Quote 2"}]`
+	req := httptest.NewRequest(http.MethodPost, "/quotes/import?mode=best-effort", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	controller.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("ImportQuotes() status = %v, want %v", w.Code, http.StatusOK)
+	}
+
+	var response dto.ImportResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Errorf("Failed to decode response: %v", err)
+	}
+
+	if response.Imported != 1 || response.Failed != 1 {
+		t.Errorf("ImportQuotes() imported = %d, failed = %d, want 1, 1", response.Imported, response.Failed)
+	}
+}
+
+func TestController_ImportQuotesUnsupportedMediaType(t *testing.T) {
+	controller := setupTestController()
+
+	req := httptest.NewRequest(http.MethodPost, "/quotes/import", strings.NewReader("<quotes/>"))
+	req.Header.Set("Content-Type", "application/xml")
+
+	w := httptest.NewRecorder()
+	controller.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("ImportQuotes() status = %v, want %v", w.Code, http.StatusUnsupportedMediaType)
+	}
+}
+
+func TestController_ExportQuotes(t *testing.T) {
+	controller := setupTestController()
+
+	for _, q := range []dto.CreateQuoteRequest{{Author: "Einstein", Quote: "Quote 1"}, {Author: "Jobs", Quote: "Quote 2"}} {
+		jsonBody, _ := json.Marshal(q)
+		req := httptest.NewRequest(http.MethodPost, "/quotes", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		controller.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/quotes/export?format=csv", nil)
+	w := httptest.NewRecorder()
+	controller.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("ExportQuotes() status = %v, want %v", w.Code, http.StatusOK)
+	}
+
+	if contentType := w.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/csv") {
+		t.Errorf("ExportQuotes() Content-Type = %v, want text/csv", contentType)
+	}
+
+	if w.Header().Get("X-Snapshot-Max-ID") != "2" {
+		t.Errorf("ExportQuotes() X-Snapshot-Max-ID = %v, want 2", w.Header().Get("X-Snapshot-Max-ID"))
+	}
+
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 3 {
+		t.Errorf("ExportQuotes() returned %d lines, want 3", len(lines))
+	}
+	if status := w.Result().Trailer.Get(quote.ExportStatusTrailer); status != "complete" {
+		t.Errorf("ExportQuotes() %s = %q, want complete", quote.ExportStatusTrailer, status)
+	}
+}
+
+func TestController_ExportQuotesUnsupportedFormat(t *testing.T) {
+	controller := setupTestController()
+
+	req := httptest.NewRequest(http.MethodGet, "/quotes/export?format=xml", nil)
+	w := httptest.NewRecorder()
+	controller.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("ExportQuotes() status = %v, want %v", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestController_ImportAndExportFortune(t *testing.T) {
+	controller := setupTestController()
+
+	body := "Imagination is more important than knowledge.\n\t\t-- Albert Einstein\n%\nStay hungry, stay foolish.\n\t\t-- Steve Jobs\n%\n"
+	req := httptest.NewRequest(http.MethodPost, "/quotes/import", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-fortune")
+
+	w := httptest.NewRecorder()
+	controller.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("ImportQuotes() status = %v, want %v", w.Code, http.StatusOK)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/quotes/export?format=fortune", nil)
+	w = httptest.NewRecorder()
+	controller.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("ExportQuotes() status = %v, want %v", w.Code, http.StatusOK)
+	}
+
+	if w.Body.String() != body {
+		t.Errorf("ExportQuotes() body = %q, want %q", w.Body.String(), body)
+	}
+}
+
+func TestController_BatchQuotes(t *testing.T) {
+	controller := setupTestController()
+
+	jsonBody, _ := json.Marshal(dto.CreateQuoteRequest{Author: "Einstein", Quote: "Quote 1"})
+	req := httptest.NewRequest(http.MethodPost, "/quotes", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	controller.ServeHTTP(httptest.NewRecorder(), req)
+
+	body := `{"operations": [
+		{"op": "create", "author": "Jobs", "quote": "Quote 2"},
+		{"op": "delete", "id": 1}
+	]}`
+	req = httptest.NewRequest(http.MethodPost, "/quotes/batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	controller.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("BatchQuotes() status = %v, want %v", w.Code, http.StatusOK)
+	}
+
+	var response dto.BatchResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if !response.Applied || len(response.Results) != 2 {
+		t.Fatalf("BatchQuotes() response = %+v, want 2 applied results", response)
+	}
+	if response.Results[0].Status != "created" || response.Results[0].Quote == nil {
+		t.Errorf("BatchQuotes() first result = %+v, want created quote", response.Results[0])
+	}
+	if response.Results[1].Status != "deleted" {
+		t.Errorf("BatchQuotes() second result status = %v, want deleted", response.Results[1].Status)
+	}
+}
+
+func TestController_BatchQuotesAborted(t *testing.T) {
+	controller := setupTestController()
+
+	body := `{"operations": [
+		{"op": "create", "author": "Jobs", "quote": "Quote 1"},
+		{"op": "delete", "id": 42}
+	]}`
+	req := httptest.NewRequest(http.MethodPost, "/quotes/batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	controller.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("BatchQuotes() status = %v, want %v", w.Code, http.StatusUnprocessableEntity)
+	}
+
+	var response dto.BatchResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if response.Applied || response.Results[0].Status != "skipped" || response.Results[1].Status != "failed" {
+		t.Errorf("BatchQuotes() response = %+v, want skipped and failed results", response)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/quotes", nil)
+	w = httptest.NewRecorder()
+	controller.ServeHTTP(w, req)
+
+	var quotes []dto.QuoteResponse
+	_ = json.NewDecoder(w.Body).Decode(&quotes)
+	if len(quotes) != 0 {
+		t.Errorf("After aborted batch, found %d quotes, want 0", len(quotes))
+	}
+}
+
+func TestController_BatchQuotesUnknownOperation(t *testing.T) {
+	controller := setupTestController()
+
+	req := httptest.NewRequest(http.MethodPost, "/quotes/batch", strings.NewReader(`{"operations": [{"op": "update", "id": 1}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	controller.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("BatchQuotes() status = %v, want %v", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestController_UnknownPathNotFound(t *testing.T) {
+	controller := setupTestController()
+
+	for _, path := range []string{"/quotes/random/extra", "/quotes/1/2", "/other"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		controller.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("GET %s status = %v, want %v", path, w.Code, http.StatusNotFound)
+		}
+
+		var errorResp utils.Problem
+		if err := json.NewDecoder(w.Body).Decode(&errorResp); err != nil || errorResp.Detail == "" {
+			t.Errorf("GET %s returned no JSON error body: %v", path, err)
+		}
+	}
+}
+
+func TestController_RandomPrefixIsNotRandom(t *testing.T) {
+	controller := setupTestController()
+
+	jsonBody, _ := json.Marshal(dto.CreateQuoteRequest{Author: "Einstein", Quote: "Quote 1"})
+	req := httptest.NewRequest(http.MethodPost, "/quotes", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	controller.ServeHTTP(httptest.NewRecorder(), req)
+
+	req = httptest.NewRequest(http.MethodGet, "/quotes/randomXYZ", nil)
+	w := httptest.NewRecorder()
+	controller.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("GET /quotes/randomXYZ status = %v, want %v", w.Code, http.StatusBadRequest)
+	}
+
+	var problem utils.Problem
+	if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+		t.Fatalf("Failed to decode problem: %v", err)
+	}
+	if problem.Code != "invalid_quote_id" {
+		t.Errorf("GET /quotes/randomXYZ code = %q, want invalid_quote_id", problem.Code)
+	}
+}
+
+func TestController_DeleteCollectionNotAllowed(t *testing.T) {
+	controller := setupTestController()
+
+	req := httptest.NewRequest(http.MethodDelete, "/quotes", nil)
+	w := httptest.NewRecorder()
+	controller.ServeHTTP(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("DELETE /quotes status = %v, want %v", w.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestController_ProblemDetails(t *testing.T) {
+	controller := setupTestController()
+
+	req := httptest.NewRequest(http.MethodDelete, "/quotes/999", nil)
+	w := httptest.NewRecorder()
+	controller.ServeHTTP(w, req)
+
+	if contentType := w.Header().Get("Content-Type"); contentType != utils.ProblemContentType {
+		t.Errorf("DeleteQuote() Content-Type = %v, want %v", contentType, utils.ProblemContentType)
+	}
+
+	var problem utils.Problem
+	if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+		t.Fatalf("Failed to decode problem: %v", err)
+	}
+
+	want := utils.Problem{
+		Type:     "/problems/quote_not_found",
+		Title:    "Not Found",
+		Status:   http.StatusNotFound,
+		Detail:   "quote not found",
+		Instance: "/quotes/999",
+		Code:     "quote_not_found",
+	}
+	if !reflect.DeepEqual(problem, want) {
+		t.Errorf("DeleteQuote() problem = %+v, want %+v", problem, want)
+	}
+}
+
+func TestController_LocalizedErrors(t *testing.T) {
+	controller := setupTestController()
+
+	req := httptest.NewRequest(http.MethodGet, "/quotes/random", nil)
+	req.Header.Set("Accept-Language", "ru")
+	w := httptest.NewRecorder()
+	controller.ServeHTTP(w, req)
+
+	var problem utils.Problem
+	if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+		t.Fatalf("Failed to decode problem: %v", err)
+	}
+	if problem.Code != "quote_not_found" || problem.Detail != "цитата не найдена" {
+		t.Errorf("GetRandomQuote() problem = %+v, want Russian quote_not_found", problem)
+	}
+
+	body := "author,quote\n,Quote 1\n"
+	req = httptest.NewRequest(http.MethodPost, "/quotes/import", strings.NewReader(body))
+	req.Header.Set("Content-Type", "text/csv")
+	req.Header.Set("Accept-Language", "ru")
+	w = httptest.NewRecorder()
+	controller.ServeHTTP(w, req)
+
+	var report dto.ImportResponse
+	if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(report.Errors) != 1 || report.Errors[0].Code != "empty_author" || report.Errors[0].Error != "автор не может быть пустым" {
+		t.Errorf("ImportQuotes() errors = %+v, want Russian empty_author", report.Errors)
+	}
+}
+
+func TestController_ContentNegotiation(t *testing.T) {
+	controller := setupTestController()
+
+	jsonBody, _ := json.Marshal(dto.CreateQuoteRequest{Author: "Einstein", Quote: "Quote 1"})
+	req := httptest.NewRequest(http.MethodPost, "/quotes", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "image/png")
+	w := httptest.NewRecorder()
+	controller.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotAcceptable {
+		t.Errorf("CreateQuote() status = %v, want %v", w.Code, http.StatusNotAcceptable)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/quotes", nil)
+	req.Header.Set("Accept", "text/plain")
+	w = httptest.NewRecorder()
+	controller.ServeHTTP(w, req)
+
+	if body := w.Body.String(); body != "" {
+		t.Errorf("GetQuotes() after rejected create = %q, want no quotes", body)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/quotes", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/plain")
+	w = httptest.NewRecorder()
+	controller.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated || w.Body.String() != "Quote 1 — Einstein\n" {
+		t.Errorf("CreateQuote() = %v %q, want 201 with plain text", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/quotes/export", nil)
+	req.Header.Set("Accept", "image/png")
+	w = httptest.NewRecorder()
+	controller.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("ExportQuotes() status = %v, want %v regardless of Accept", w.Code, http.StatusOK)
+	}
+}
+
+func TestController_GetQuote(t *testing.T) {
+	controller := setupTestController()
+
+	jsonBody, _ := json.Marshal(dto.CreateQuoteRequest{Author: "Einstein", Quote: "Quote 1"})
+	req := httptest.NewRequest(http.MethodPost, "/quotes", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	controller.ServeHTTP(httptest.NewRecorder(), req)
+
+	w := httptest.NewRecorder()
+	controller.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/quotes/1", nil))
+
+	var response dto.QuoteResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if w.Code != http.StatusOK || response.ID != 1 || response.Quote != "Quote 1" {
+		t.Errorf("GetQuote() = %v %+v, want quote 1", w.Code, response)
+	}
+
+	w = httptest.NewRecorder()
+	controller.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/quotes/2", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("GetQuote() missing status = %v, want %v", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestController_ContextErrors(t *testing.T) {
+	controller := setupTestController()
+
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "deadline exceeded", ctx: expired, wantStatus: http.StatusServiceUnavailable, wantCode: "timeout"},
+		{name: "canceled by the client", ctx: canceled, wantStatus: utils.StatusClientClosedRequest, wantCode: "request_canceled"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/quotes", nil).WithContext(tt.ctx)
+			w := httptest.NewRecorder()
+			controller.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("GetAllQuotes() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+			var problem utils.Problem
+			if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+				t.Fatalf("Failed to decode problem: %v", err)
+			}
+			if problem.Code != tt.wantCode {
+				t.Errorf("GetAllQuotes() code = %q, want %q", problem.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestController_RequestTimeout(t *testing.T) {
+	repo := in_memory.NewInMemoryQuoteRepository()
+	svc := service.NewQuoteService(repo)
+	controller := asSystem(quote.NewQuoteController(svc, "/quotes", quote.WithTimeouts(quote.Timeouts{Request: time.Nanosecond})))
+
+	time.Sleep(time.Millisecond)
+	req := httptest.NewRequest(http.MethodGet, "/quotes", nil)
+	w := httptest.NewRecorder()
+	controller.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("GetAllQuotes() status = %v, want %v", w.Code, http.StatusServiceUnavailable)
 	}
 }
@@ -0,0 +1,74 @@
+package dto_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Korjick/go-http-quote/domain/quote/entity"
+	"github.com/Korjick/go-http-quote/domain/quote/repository"
+	"github.com/Korjick/go-http-quote/presentation/http/quote/dto"
+)
+
+func TestEntityToDTOV2(t *testing.T) {
+	quote, err := entity.NewQuote(42, "Albert Einstein", "Imagination is more important than knowledge.")
+	if err != nil {
+		t.Fatalf("Failed to create quote: %v", err)
+	}
+
+	data, err := json.Marshal(dto.EntityToDTOV2(quote))
+	if err != nil {
+		t.Fatalf("Failed to marshal response: %v", err)
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if fields["text"] != quote.Text {
+		t.Errorf("EntityToDTOV2() text = %v, want %v", fields["text"], quote.Text)
+	}
+	if _, ok := fields["quote"]; ok {
+		t.Errorf("EntityToDTOV2() has field quote, want only text")
+	}
+}
+
+func TestMapperFor_CreateRequest(t *testing.T) {
+	tests := map[dto.Version]string{
+		dto.V1: `{"author":"Einstein","quote":"Quote 1"}`,
+		dto.V2: `{"author":"Einstein","text":"Quote 1"}`,
+	}
+
+	for version, body := range tests {
+		mapper := dto.MapperFor(version)
+		if mapper.Version() != version {
+			t.Errorf("MapperFor(%v).Version() = %v", version, mapper.Version())
+		}
+
+		req := mapper.CreateRequest()
+		if err := json.Unmarshal([]byte(body), req); err != nil {
+			t.Fatalf("Failed to decode %v request: %v", version, err)
+		}
+		want := entity.QuoteDraft{Author: "Einstein", Text: "Quote 1"}
+		if draft := req.Draft(); draft != want {
+			t.Errorf("%v Draft() = %+v, want %+v", version, draft, want)
+		}
+	}
+}
+
+func TestBatchRequestV2_ToOperations(t *testing.T) {
+	req := dto.BatchRequestV2{Operations: []dto.BatchOperationRequestV2{
+		{Op: "create", Author: "Einstein", Text: "Quote 1"},
+		{Op: "delete", ID: 7},
+	}}
+
+	ops, err := req.ToOperations()
+	if err != nil {
+		t.Fatalf("ToOperations() error = %v", err)
+	}
+	if ops[0].Kind != repository.OperationCreate || ops[0].Draft.Text != "Quote 1" {
+		t.Errorf("ToOperations()[0] = %+v, want create of Quote 1", ops[0])
+	}
+	if ops[1].Kind != repository.OperationDelete || ops[1].ID != 7 {
+		t.Errorf("ToOperations()[1] = %+v, want delete of 7", ops[1])
+	}
+}
@@ -0,0 +1,178 @@
+package feed_test
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Korjick/go-http-quote/application/service"
+	"github.com/Korjick/go-http-quote/domain/auth"
+	"github.com/Korjick/go-http-quote/infrastructure/repository/in_memory"
+	"github.com/Korjick/go-http-quote/presentation/http/quote"
+	"github.com/Korjick/go-http-quote/presentation/http/quote/feed"
+)
+
+type rss struct {
+	Channel struct {
+		Title       string `xml:"title"`
+		Description string `xml:"description"`
+		Items       []struct {
+			Title string `xml:"title"`
+			Link  string `xml:"link"`
+			GUID  string `xml:"guid"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+type atom struct {
+	ID       string `xml:"id"`
+	Title    string `xml:"title"`
+	Subtitle string `xml:"subtitle"`
+	Entries  []struct {
+		ID     string `xml:"id"`
+		Author string `xml:"author>name"`
+	} `xml:"entry"`
+}
+
+func setup(t *testing.T) (*quote.Controller, *service.QuoteService) {
+	t.Helper()
+	svc := service.NewQuoteService(in_memory.NewInMemoryQuoteRepository())
+	for _, q := range [][2]string{{"Einstein", "Quote 1"}, {"Jobs", "Quote 2"}, {"Einstein", "Quote 3"}} {
+		if _, err := svc.CreateQuote(context.Background(), auth.System, q[0], q[1]); err != nil {
+			t.Fatalf("CreateQuote() error = %v", err)
+		}
+	}
+	return quote.NewQuoteController(svc, "/quotes", quote.WithPublicURL("https://quotes.example.org/")), svc
+}
+
+func fetch(controller http.Handler, target string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	w := httptest.NewRecorder()
+	controller.ServeHTTP(w, req)
+	return w
+}
+
+func TestFeeds_RSS(t *testing.T) {
+	controller, _ := setup(t)
+
+	w := fetch(controller, "/quotes/feed.rss", nil)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != feed.RSSContentType {
+		t.Fatalf("RSS() = %v %q, want 200 %q", w.Code, w.Header().Get("Content-Type"), feed.RSSContentType)
+	}
+
+	var doc rss
+	if err := xml.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("Failed to decode RSS: %v", err)
+	}
+	items := doc.Channel.Items
+	if len(items) != 3 || items[0].Title != "Quote 3" {
+		t.Fatalf("RSS() items = %+v, want 3 items newest first", items)
+	}
+	// Links follow the public URL, not the Host of the request.
+	if items[0].Link != "https://quotes.example.org/quotes/3" || items[0].GUID != feed.QuoteGUID(3) {
+		t.Errorf("RSS() first item = %+v, want link and GUID of quote 3", items[0])
+	}
+}
+
+func TestFeeds_AtomFilteredByAuthor(t *testing.T) {
+	controller, _ := setup(t)
+
+	w := fetch(controller, "/quotes/feed.atom?author=einstein", nil)
+	if w.Header().Get("Content-Type") != feed.AtomContentType {
+		t.Errorf("Atom() Content-Type = %q, want %q", w.Header().Get("Content-Type"), feed.AtomContentType)
+	}
+
+	var doc atom
+	if err := xml.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("Failed to decode Atom: %v", err)
+	}
+	if len(doc.Entries) != 2 {
+		t.Fatalf("Atom() entries = %d, want 2", len(doc.Entries))
+	}
+	for _, entry := range doc.Entries {
+		if entry.Author != "Einstein" {
+			t.Errorf("Atom() entry author = %q, want Einstein", entry.Author)
+		}
+	}
+	if !strings.HasPrefix(doc.ID, "urn:uuid:") {
+		t.Errorf("Atom() id = %q, want a UUID URN", doc.ID)
+	}
+}
+
+func TestFeeds_Localized(t *testing.T) {
+	controller, _ := setup(t)
+	russian := http.Header{"Accept-Language": {"ru-RU,ru;q=0.9"}}
+
+	w := fetch(controller, "/quotes/feed.rss", russian)
+	var doc rss
+	if err := xml.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("Failed to decode RSS: %v", err)
+	}
+	if doc.Channel.Title != "Цитаты" || doc.Channel.Description != "Новые цитаты" {
+		t.Errorf("RSS() title = %q, description = %q, want Russian", doc.Channel.Title, doc.Channel.Description)
+	}
+	if !strings.Contains(w.Header().Get("Vary"), "Accept-Language") {
+		t.Errorf("RSS() Vary = %q, want Accept-Language", w.Header().Get("Vary"))
+	}
+
+	w = fetch(controller, "/quotes/feed.atom?author=Einstein", russian)
+	var atomDoc atom
+	if err := xml.Unmarshal(w.Body.Bytes(), &atomDoc); err != nil {
+		t.Fatalf("Failed to decode Atom: %v", err)
+	}
+	if atomDoc.Title != "Цитаты автора Einstein" || atomDoc.Subtitle != "Новые цитаты" {
+		t.Errorf("Atom() title = %q, subtitle = %q, want Russian", atomDoc.Title, atomDoc.Subtitle)
+	}
+
+	english := fetch(controller, "/quotes/feed.atom?author=Einstein", nil)
+	if english.Header().Get("ETag") == w.Header().Get("ETag") {
+		t.Errorf("ETag = %q in both languages", w.Header().Get("ETag"))
+	}
+}
+
+func TestQuoteGUID(t *testing.T) {
+	if feed.QuoteGUID(1) != feed.QuoteGUID(1) {
+		t.Errorf("QuoteGUID() is not stable")
+	}
+	if feed.QuoteGUID(1) == feed.QuoteGUID(2) {
+		t.Errorf("QuoteGUID() is the same for different quotes")
+	}
+	if guid := feed.QuoteGUID(1); len(guid) != len("urn:uuid:")+36 || guid[len("urn:uuid:")+14] != '5' {
+		t.Errorf("QuoteGUID() = %q, want a version 5 UUID URN", guid)
+	}
+}
+
+func TestFeeds_ConditionalGet(t *testing.T) {
+	controller, svc := setup(t)
+
+	w := fetch(controller, "/quotes/feed.rss", nil)
+	lastModified := w.Header().Get("Last-Modified")
+	etag := w.Header().Get("ETag")
+	if lastModified == "" || etag == "" {
+		t.Fatalf("RSS() Last-Modified = %q, ETag = %q, want both set", lastModified, etag)
+	}
+
+	w = fetch(controller, "/quotes/feed.rss", http.Header{"If-Modified-Since": {lastModified}})
+	if w.Code != http.StatusNotModified {
+		t.Errorf("RSS() with If-Modified-Since status = %v, want %v", w.Code, http.StatusNotModified)
+	}
+
+	w = fetch(controller, "/quotes/feed.rss", http.Header{"If-None-Match": {etag}})
+	if w.Code != http.StatusNotModified {
+		t.Errorf("RSS() with If-None-Match status = %v, want %v", w.Code, http.StatusNotModified)
+	}
+
+	if err := svc.DeleteQuote(context.Background(), auth.System, 1); err != nil {
+		t.Fatalf("DeleteQuote() error = %v", err)
+	}
+	w = fetch(controller, "/quotes/feed.rss", http.Header{"If-None-Match": {etag}})
+	if w.Code != http.StatusOK {
+		t.Errorf("RSS() after delete status = %v, want %v", w.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,110 @@
+package quote_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Korjick/go-http-quote/application/service"
+	"github.com/Korjick/go-http-quote/infrastructure/repository/in_memory"
+	"github.com/Korjick/go-http-quote/presentation/http/openapi"
+	"github.com/Korjick/go-http-quote/presentation/http/quote"
+)
+
+func describe(controller *quote.VersionedController) *openapi.Document {
+	doc := openapi.NewDocument(openapi.Info{Title: "Quotes API", Version: "v2"})
+	controller.Describe(doc)
+	return doc
+}
+
+func TestOpenAPI_MatchesRoutes(t *testing.T) {
+	controller := setupVersionedController()
+	own := quote.NewOwnQuotesController(service.NewQuoteService(in_memory.NewInMemoryQuoteRepository()), "/me")
+	doc := describe(controller)
+	own.Describe(doc)
+
+	routes := append(controller.Routes(), own.Routes()...)
+	for _, route := range routes {
+		method, path, _ := strings.Cut(route, " ")
+		op, ok := doc.Operation(method, path)
+		if !ok {
+			t.Errorf("route %s is not documented", route)
+			continue
+		}
+		for _, segment := range strings.Split(path, "/") {
+			name, ok := strings.CutPrefix(segment, "{")
+			if !ok {
+				continue
+			}
+			name = strings.TrimSuffix(name, "}")
+			if !slices.ContainsFunc(op.Parameters, func(p openapi.Parameter) bool { return p.In == "path" && p.Name == name }) {
+				t.Errorf("%s does not document path parameter %q", route, name)
+			}
+		}
+	}
+
+	ids := make(map[string]string)
+	for path, item := range doc.Paths {
+		for method, op := range item {
+			route := strings.ToUpper(method) + " " + path
+			if !slices.Contains(routes, route) {
+				t.Errorf("documented operation %s has no route", route)
+			}
+			if other, ok := ids[op.OperationID]; ok {
+				t.Errorf("operationId %q used by %s and %s", op.OperationID, other, route)
+			}
+			ids[op.OperationID] = route
+		}
+	}
+}
+
+func TestOpenAPI_ReferencesResolve(t *testing.T) {
+	doc := describe(setupVersionedController())
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	for _, ref := range strings.Split(string(data), `"$ref":"`)[1:] {
+		name := strings.TrimPrefix(ref[:strings.IndexByte(ref, '"')], "#/components/schemas/")
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			t.Errorf("$ref to undefined schema %q", name)
+		}
+	}
+}
+
+func TestOpenAPI_DescribesResponses(t *testing.T) {
+	controller := setupVersionedController()
+	doc := describe(controller)
+	handler := asSystem(controller)
+
+	for version, body := range map[string]string{"v1": `{"author":"Author","quote":"Text"}`, "v2": `{"author":"Author","text":"Text"}`} {
+		create := httptest.NewRequest(http.MethodPost, "/"+version+"/quotes", strings.NewReader(body))
+		create.Header.Set("Content-Type", "application/json")
+		handler.ServeHTTP(httptest.NewRecorder(), create)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/"+version+"/quotes/1", nil))
+		var response map[string]any
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("GET /%s/quotes/1 body = %s, error = %v", version, w.Body.String(), err)
+		}
+
+		op, _ := doc.Operation(http.MethodGet, "/"+version+"/quotes/{id}")
+		ref := op.Responses["200"].Content["application/json"].Schema.Ref
+		schema := doc.Components.Schemas[strings.TrimPrefix(ref, "#/components/schemas/")]
+		for field := range response {
+			if _, ok := schema.Properties[field]; !ok {
+				t.Errorf("GET /%s/quotes/{id} returns %q, not in schema %s", version, field, ref)
+			}
+		}
+		for _, field := range schema.Required {
+			if _, ok := response[field]; !ok {
+				t.Errorf("GET /%s/quotes/{id} lacks required %q of schema %s", version, field, ref)
+			}
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package quote_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Korjick/go-http-quote/application/service"
+	"github.com/Korjick/go-http-quote/domain/auth"
+	"github.com/Korjick/go-http-quote/infrastructure/repository/in_memory"
+	utils "github.com/Korjick/go-http-quote/presentation/http"
+	"github.com/Korjick/go-http-quote/presentation/http/quote"
+	"github.com/Korjick/go-http-quote/presentation/http/quote/dto"
+)
+
+func TestOwnQuotesController(t *testing.T) {
+	svc := service.NewQuoteService(in_memory.NewInMemoryQuoteRepository())
+	alice := auth.Principal{Subject: "alice", Role: auth.RoleContributor}
+	_, _ = svc.CreateQuote(context.Background(), alice, "Author", "Mine")
+	_, _ = svc.CreateQuote(context.Background(), auth.System, "Author", "Not mine")
+	controller := quote.NewOwnQuotesController(svc, "/me")
+
+	send := func(principal auth.Principal, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/me/quotes", nil)
+		req.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		controller.ServeHTTP(w, utils.WithPrincipal(req, principal))
+		return w
+	}
+
+	w := send(alice, quote.VendorMediaType(dto.V2))
+	var quotes []dto.QuoteResponseV2
+	if err := json.Unmarshal(w.Body.Bytes(), &quotes); err != nil {
+		t.Fatalf("GET /me/quotes body = %s, error = %v", w.Body.String(), err)
+	}
+	if len(quotes) != 1 || quotes[0].Text != "Mine" || quotes[0].CreatedBy != "alice" {
+		t.Errorf("GET /me/quotes = %+v, want the quote of alice", quotes)
+	}
+	if w.Header().Get(quote.APIVersionHeader) != "v2" {
+		t.Errorf("API-Version = %q, want v2", w.Header().Get(quote.APIVersionHeader))
+	}
+
+	if w := send(auth.Anonymous, "application/json"); w.Code != http.StatusUnauthorized {
+		t.Errorf("Anonymous GET /me/quotes status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+	if w := send(alice, "application/vnd.quotes.v9+json"); w.Code != http.StatusNotAcceptable {
+		t.Errorf("GET /me/quotes of unsupported version status = %d, want %d", w.Code, http.StatusNotAcceptable)
+	}
+}
@@ -0,0 +1,149 @@
+package page_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Korjick/go-http-quote/application/service"
+	"github.com/Korjick/go-http-quote/domain/auth"
+	"github.com/Korjick/go-http-quote/infrastructure/repository/in_memory"
+	"github.com/Korjick/go-http-quote/presentation/http/quote"
+	"github.com/Korjick/go-http-quote/presentation/http/quote/page"
+)
+
+const browserAccept = "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"
+
+func setupController(t *testing.T, quotes int) (*quote.Controller, *service.QuoteService) {
+	t.Helper()
+	svc := service.NewQuoteService(in_memory.NewInMemoryQuoteRepository())
+	for i := 1; i <= quotes; i++ {
+		author := "Einstein"
+		if i%2 == 0 {
+			author = "Jobs"
+		}
+		if _, err := svc.CreateQuote(context.Background(), auth.System, author, fmt.Sprintf("Quote number %d", i)); err != nil {
+			t.Fatalf("CreateQuote() error = %v", err)
+		}
+	}
+	return quote.NewQuoteController(svc, "/quotes"), svc
+}
+
+func get(controller http.Handler, target, accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.Header.Set("Accept", accept)
+	w := httptest.NewRecorder()
+	controller.ServeHTTP(w, req)
+	return w
+}
+
+func TestPages_ServedToBrowsersOnly(t *testing.T) {
+	controller, _ := setupController(t, 1)
+
+	w := get(controller, "/quotes", browserAccept)
+	if ct := w.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("GET /quotes as browser Content-Type = %q, want text/html", ct)
+	}
+	if !strings.Contains(w.Body.String(), "Quote number 1") {
+		t.Errorf("GET /quotes as browser body does not contain the quote")
+	}
+
+	w = get(controller, "/quotes", "application/json")
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("GET /quotes as API client Content-Type = %q, want application/json", ct)
+	}
+}
+
+func TestPages_ListPagination(t *testing.T) {
+	controller, _ := setupController(t, page.DefaultPageSize+5)
+
+	w := get(controller, "/quotes", browserAccept)
+	body := w.Body.String()
+	if strings.Count(body, `class="quote"`) != page.DefaultPageSize {
+		t.Errorf("first page shows %d quotes, want %d", strings.Count(body, `class="quote"`), page.DefaultPageSize)
+	}
+	if !strings.Contains(body, `href="/quotes?page=2" rel="next"`) {
+		t.Errorf("first page has no link to the second one")
+	}
+
+	w = get(controller, "/quotes?page=2", browserAccept)
+	body = w.Body.String()
+	if strings.Count(body, `class="quote"`) != 5 {
+		t.Errorf("second page shows %d quotes, want 5", strings.Count(body, `class="quote"`))
+	}
+	if strings.Contains(body, `rel="next"`) || !strings.Contains(body, `rel="prev"`) {
+		t.Errorf("last page should link back but not forward")
+	}
+
+	w = get(controller, "/quotes?page=99", browserAccept)
+	if w.Code != http.StatusOK || strings.Count(w.Body.String(), `class="quote"`) != 5 {
+		t.Errorf("page past the end should show the last page")
+	}
+}
+
+func TestPages_SearchAndAuthor(t *testing.T) {
+	controller, _ := setupController(t, 4)
+
+	w := get(controller, "/quotes?q=number+3", browserAccept)
+	if n := strings.Count(w.Body.String(), `class="quote"`); n != 1 {
+		t.Errorf("search shows %d quotes, want 1", n)
+	}
+
+	w = get(controller, "/quotes/authors/Jobs", browserAccept)
+	body := w.Body.String()
+	if n := strings.Count(body, `class="quote"`); n != 2 || !strings.Contains(body, "Quotes by Jobs") {
+		t.Errorf("author page shows %d quotes, want 2 by Jobs", n)
+	}
+
+	w = get(controller, "/quotes/authors/Jobs", "application/json")
+	if !strings.Contains(w.Body.String(), `"author":"Jobs"`) || strings.Contains(w.Body.String(), "Einstein") {
+		t.Errorf("author endpoint as JSON = %s, want Jobs quotes only", w.Body.String())
+	}
+}
+
+func TestPages_DetailAndRandom(t *testing.T) {
+	controller, _ := setupController(t, 2)
+
+	w := get(controller, "/quotes/2", browserAccept)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Quote number 2") {
+		t.Errorf("detail page = %v, want quote 2", w.Code)
+	}
+
+	w = get(controller, "/quotes/99", browserAccept)
+	if w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), "quote not found") {
+		t.Errorf("missing quote page = %v, want 404 page", w.Code)
+	}
+
+	w = get(controller, "/quotes/random", browserAccept)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `href="/quotes/random"`) {
+		t.Errorf("random page = %v, want a link to another random quote", w.Code)
+	}
+
+	w = get(controller, "/nowhere", browserAccept)
+	if w.Code != http.StatusNotFound || w.Header().Get("Content-Type") != "text/html; charset=utf-8" {
+		t.Errorf("unknown path as browser = %v %q, want HTML 404", w.Code, w.Header().Get("Content-Type"))
+	}
+}
+
+func TestPages_EscapeAndLocalize(t *testing.T) {
+	svc := service.NewQuoteService(in_memory.NewInMemoryQuoteRepository())
+	svc.CreateQuote(context.Background(), auth.System, "Mallory", "<script>alert(1)</script>")
+	controller := quote.NewQuoteController(svc, "/quotes")
+
+	req := httptest.NewRequest(http.MethodGet, "/quotes", nil)
+	req.Header.Set("Accept", browserAccept)
+	req.Header.Set("Accept-Language", "ru")
+	w := httptest.NewRecorder()
+	controller.ServeHTTP(w, req)
+
+	body := w.Body.String()
+	if strings.Contains(body, "<script>alert") {
+		t.Errorf("quote text is not escaped")
+	}
+	if !strings.Contains(body, "Все цитаты") || w.Header().Get("Content-Language") != "ru" {
+		t.Errorf("page is not in Russian")
+	}
+}
@@ -0,0 +1,123 @@
+package quote_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Korjick/go-http-quote/application/service"
+	"github.com/Korjick/go-http-quote/infrastructure/repository/in_memory"
+	"github.com/Korjick/go-http-quote/presentation/http/quote"
+	"github.com/Korjick/go-http-quote/presentation/http/quote/dto"
+)
+
+func setupVersionedController() *quote.VersionedController {
+	repo := in_memory.NewInMemoryQuoteRepository()
+	svc := service.NewQuoteService(repo)
+	return quote.NewVersionedController(svc, "/quotes")
+}
+
+func TestVersionFromAccept(t *testing.T) {
+	tests := map[string]dto.Version{
+		"":                               dto.V1,
+		"application/json":               dto.V1,
+		"application/vnd.quotes.v1+json": dto.V1,
+		"application/vnd.quotes.v2+json": dto.V2,
+		"application/json, application/vnd.quotes.v2+json; q=0.9":        dto.V2,
+		"application/vnd.quotes.v9+json, application/vnd.quotes.v2+json": dto.V2,
+	}
+
+	for accept, want := range tests {
+		got, err := quote.VersionFromAccept(accept)
+		if err != nil || got != want {
+			t.Errorf("VersionFromAccept(%q) = %v, %v, want %v", accept, got, err, want)
+		}
+	}
+
+	if _, err := quote.VersionFromAccept("application/vnd.quotes.v9+json"); err == nil {
+		t.Errorf("VersionFromAccept() error = nil, want unsupported version")
+	}
+}
+
+func TestVersionedController_Versions(t *testing.T) {
+	controller := asSystem(setupVersionedController())
+
+	tests := []struct {
+		name        string
+		path        string
+		accept      string
+		body        string
+		wantVersion string
+		wantField   string
+	}{
+		{"bare defaults to v1", "/quotes", "", `{"author":"A","quote":"Q"}`, "v1", `"quote":"Q"`},
+		{"path v1", "/v1/quotes", "", `{"author":"A","quote":"Q"}`, "v1", `"quote":"Q"`},
+		{"path v2", "/v2/quotes", "", `{"author":"A","text":"Q"}`, "v2", `"text":"Q"`},
+		{"accept v2", "/quotes", "application/vnd.quotes.v2+json", `{"author":"A","text":"Q"}`, "v2", `"text":"Q"`},
+		{"path wins over accept", "/v1/quotes", "application/vnd.quotes.v2+json", `{"author":"A","quote":"Q"}`, "v1", `"quote":"Q"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			controller.ServeHTTP(w, req)
+
+			if w.Code != http.StatusCreated {
+				t.Fatalf("CreateQuote() status = %v, want %v: %s", w.Code, http.StatusCreated, w.Body.String())
+			}
+			if got := w.Header().Get(quote.APIVersionHeader); got != tt.wantVersion {
+				t.Errorf("CreateQuote() %s = %q, want %q", quote.APIVersionHeader, got, tt.wantVersion)
+			}
+			if !strings.Contains(w.Body.String(), tt.wantField) {
+				t.Errorf("CreateQuote() body = %s, want %s", w.Body.String(), tt.wantField)
+			}
+		})
+	}
+}
+
+func TestVersionedController_BulkV2RoundTrip(t *testing.T) {
+	controller := asSystem(setupVersionedController())
+
+	req := httptest.NewRequest(http.MethodPost, "/v2/quotes/import", strings.NewReader(`{"author":"A","text":"T"}`+"\n"))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	w := httptest.NewRecorder()
+	controller.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"imported":1`) {
+		t.Fatalf("POST /v2/quotes/import = %d %s, want one imported quote", w.Code, w.Body.String())
+	}
+
+	for path, want := range map[string]string{"/v2/quotes/export": `"text":"T"`, "/v1/quotes/export": `"quote":"T"`} {
+		w := httptest.NewRecorder()
+		controller.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), want) {
+			t.Errorf("GET %s = %d %s, want %s", path, w.Code, w.Body.String(), want)
+		}
+	}
+}
+
+func TestVersionedController_UnsupportedVersion(t *testing.T) {
+	controller := asSystem(setupVersionedController())
+
+	req := httptest.NewRequest(http.MethodGet, "/quotes", nil)
+	req.Header.Set("Accept", "application/vnd.quotes.v9+json")
+	w := httptest.NewRecorder()
+	controller.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotAcceptable {
+		t.Errorf("GetQuotes() status = %v, want %v", w.Code, http.StatusNotAcceptable)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/v3/quotes", nil)
+	w = httptest.NewRecorder()
+	controller.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("GetQuotes() unknown version path status = %v, want %v", w.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,139 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	utils "github.com/Korjick/go-http-quote/presentation/http"
+)
+
+func newTestRouter() *utils.Router {
+	router := utils.NewRouter()
+	for _, route := range []struct{ method, pattern string }{
+		{http.MethodGet, "/quotes"},
+		{http.MethodPost, "/quotes"},
+		{http.MethodGet, "/quotes/random"},
+		{http.MethodGet, "/quotes/{id}"},
+		{http.MethodDelete, "/quotes/{id}"},
+	} {
+		router.HandleFunc(route.method, route.pattern, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Pattern", r.Pattern)
+			w.Header().Set("X-ID", r.PathValue("id"))
+		})
+	}
+	return router
+}
+
+func TestRouter_Dispatch(t *testing.T) {
+	router := newTestRouter()
+
+	tests := []struct {
+		method, path, pattern, id string
+	}{
+		{http.MethodGet, "/quotes", "GET /quotes", ""},
+		{http.MethodGet, "/quotes/", "GET /quotes", ""},
+		{http.MethodPost, "/quotes", "POST /quotes", ""},
+		{http.MethodGet, "/quotes/random", "GET /quotes/random", ""},
+		{http.MethodHead, "/quotes/random", "GET /quotes/random", ""},
+		{http.MethodGet, "/quotes/42", "GET /quotes/{id}", "42"},
+		{http.MethodDelete, "/quotes/random", "DELETE /quotes/{id}", "random"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("%s %s status = %v, want %v", tt.method, tt.path, w.Code, http.StatusOK)
+		}
+		if got := w.Header().Get("X-Pattern"); got != tt.pattern {
+			t.Errorf("%s %s pattern = %q, want %q", tt.method, tt.path, got, tt.pattern)
+		}
+		if got := w.Header().Get("X-ID"); got != tt.id {
+			t.Errorf("%s %s id = %q, want %q", tt.method, tt.path, got, tt.id)
+		}
+	}
+}
+
+func TestRouter_EscapedPathValues(t *testing.T) {
+	router := utils.NewRouter()
+	router.HandleFunc(http.MethodGet, "/quotes/authors/{author}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Author", r.PathValue("author"))
+	})
+
+	tests := []struct {
+		path, author string
+	}{
+		{"/quotes/authors/AC%2FDC", "AC/DC"},
+		{"/quotes/authors/Mark%20Twain", "Mark Twain"},
+		{"/quotes/authors/%D0%9F%D1%83%D1%88%D0%BA%D0%B8%D0%BD", "Пушкин"},
+		{"/quot%65s/authors/Seneca", "Seneca"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("GET %s status = %v, want %v", tt.path, w.Code, http.StatusOK)
+		}
+		if got := w.Header().Get("X-Author"); got != tt.author {
+			t.Errorf("GET %s author = %q, want %q", tt.path, got, tt.author)
+		}
+	}
+}
+
+func TestRouter_NotFound(t *testing.T) {
+	router := newTestRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/quotes/1/extra", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Unknown path status = %v, want %v", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestRouter_MethodNotAllowed(t *testing.T) {
+	router := newTestRouter()
+
+	req := httptest.NewRequest(http.MethodPut, "/quotes/random", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("PUT /quotes/random status = %v, want %v", w.Code, http.StatusMethodNotAllowed)
+	}
+
+	if allow := w.Header().Get("Allow"); allow != "DELETE, GET, HEAD" {
+		t.Errorf("PUT /quotes/random Allow = %q, want %q", allow, "DELETE, GET, HEAD")
+	}
+}
+
+func TestRouter_CustomErrorHandlers(t *testing.T) {
+	router := newTestRouter()
+	router.NotFound = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/unknown", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusTeapot {
+		t.Errorf("Custom NotFound status = %v, want %v", w.Code, http.StatusTeapot)
+	}
+}
+
+func TestRouter_Routes(t *testing.T) {
+	got := newTestRouter().Routes()
+	want := []string{"GET /quotes", "POST /quotes", "GET /quotes/random", "GET /quotes/{id}", "DELETE /quotes/{id}"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Routes() = %v, want %v", got, want)
+	}
+}
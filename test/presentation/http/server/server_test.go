@@ -0,0 +1,162 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	utils "github.com/Korjick/go-http-quote/presentation/http"
+	"github.com/Korjick/go-http-quote/presentation/http/server"
+)
+
+// start serves handler on a local port until the returned cancel is called.
+// The channel receives the result of Serve.
+func start(t *testing.T, config server.Config, handler http.Handler, hooks ...string) (string, context.CancelFunc, <-chan error, *[]string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	srv := server.New(config, handler)
+	var ran []string
+	for _, name := range hooks {
+		srv.OnShutdown(name, func(context.Context) error {
+			ran = append(ran, name)
+			return nil
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx, listener) }()
+	return "http://" + listener.Addr().String(), cancel, done, &ran
+}
+
+func wait(t *testing.T, done <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve() did not return")
+		return nil
+	}
+}
+
+func TestServer_DrainsRequestsInFlight(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "done")
+	})
+	url, shutdown, done, ran := start(t, server.DefaultConfig, handler, "flush", "export")
+
+	type result struct {
+		body string
+		err  error
+	}
+	response := make(chan result, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			response <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		response <- result{body: string(body), err: err}
+	}()
+
+	<-started
+	shutdown()
+	time.Sleep(50 * time.Millisecond)
+	if len(*ran) != 0 {
+		t.Fatal("Hooks ran before the request drained")
+	}
+	close(release)
+
+	if got := <-response; got.err != nil || got.body != "done" {
+		t.Errorf("Request in flight = %q, %v, want done", got.body, got.err)
+	}
+	if err := wait(t, done); err != nil {
+		t.Errorf("Serve() error = %v", err)
+	}
+	if len(*ran) != 2 || (*ran)[0] != "flush" || (*ran)[1] != "export" {
+		t.Errorf("Hooks ran = %v, want flush, export", *ran)
+	}
+	if _, err := http.Get(url); err == nil {
+		t.Error("Server accepts requests after the shutdown")
+	}
+}
+
+func TestServer_CancelsRequestsPastDrainDeadline(t *testing.T) {
+	config := server.DefaultConfig
+	config.ShutdownTimeout = 50 * time.Millisecond
+	started, canceled := make(chan struct{}), make(chan error, 1)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+		canceled <- r.Context().Err()
+	})
+	url, shutdown, done, ran := start(t, config, handler, "flush")
+
+	go http.Get(url)
+	<-started
+	shutdown()
+
+	wait(t, done)
+	select {
+	case err := <-canceled:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Request context error = %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Request was not canceled past the drain deadline")
+	}
+	if len(*ran) != 1 {
+		t.Errorf("Hooks ran = %v, want flush", *ran)
+	}
+}
+
+func TestServer_RouteTimeoutOutlastsWriteTimeout(t *testing.T) {
+	config := server.DefaultConfig
+	config.WriteTimeout = 50 * time.Millisecond
+	mux := http.NewServeMux()
+	slow := func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(150 * time.Millisecond)
+		io.WriteString(w, "exported")
+	}
+	mux.HandleFunc("/slow", slow)
+	mux.HandleFunc("/bulk", utils.WithTimeout(time.Second, slow))
+	mux.HandleFunc("/unlimited", utils.WithTimeout(0, slow))
+	url, shutdown, done, _ := start(t, config, mux)
+	defer func() {
+		shutdown()
+		wait(t, done)
+	}()
+
+	if resp, err := http.Get(url + "/slow"); err == nil {
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err == nil && string(body) == "exported" {
+			t.Error("Slow response outlived the write timeout")
+		}
+	}
+
+	for _, path := range []string{"/bulk", "/unlimited"} {
+		resp, err := http.Get(url + path)
+		if err != nil {
+			t.Fatalf("GET %s error = %v", path, err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil || string(body) != "exported" {
+			t.Errorf("GET %s = %q, %v, want exported", path, body, err)
+		}
+	}
+}